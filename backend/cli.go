@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/zvxte/kera/config"
+	"github.com/zvxte/kera/database"
+	"github.com/zvxte/kera/model/user"
+	"github.com/zvxte/kera/store/userstore"
+)
+
+// openDatabase connects to the database read from database.dsn of the config.
+// The returned error is prefixed with the command.
+func openDatabase(
+	ctx context.Context, cfg *config.Config, command string,
+) (*database.SqlDatabase, error) {
+	dataSourceName := cfg.Database.DSN.Value()
+	if dataSourceName == "" {
+		return nil, fmt.Errorf("%s: database.dsn is not set", command)
+	}
+
+	sqlDatabase, err := database.NewSqlDatabase(
+		ctx, database.PostgresDriverName, dataSourceName,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", command, err)
+	}
+	return sqlDatabase, nil
+}
+
+// getUser returns the user with the username, or an error if there is none.
+func getUser(
+	ctx context.Context, userStore userstore.Store, username string,
+) (*user.User, error) {
+	u, err := userStore.Get(ctx, userstore.UsernameColumn, username)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, fmt.Errorf("user %q not found", username)
+	}
+	return u, nil
+}
+
+// readPassword returns the first line of r, so a password can be piped
+// in without showing up in the process list or the shell history.
+func readPassword(r io.Reader) (string, error) {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+
+	plainPassword := strings.TrimRight(line, "\r\n")
+	if plainPassword == "" {
+		return "", errors.New("password is required on standard input")
+	}
+	return plainPassword, nil
+}
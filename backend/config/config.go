@@ -0,0 +1,229 @@
+// Package config loads the configuration of kera.
+//
+// Every option has a default, which is overridden by the config file,
+// then by environment variables, then by command line flags.
+// The config file is TOML, without inline tables and arrays of tables,
+// its path is set by the -config flag or the KERA_CONFIG environment variable.
+//
+// An option is named by its key in the config file, like server.address,
+// which is also the name of its flag. Environment variables keep
+// their established names, like ADDRESS.
+// Secrets can be read from a file: the key and the flag get a _file suffix,
+// like database.dsn_file, and the environment variable a _FILE suffix,
+// like DSN_FILE.
+package config
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/zvxte/kera/hash/argon2id"
+	"github.com/zvxte/kera/model/habit"
+	"github.com/zvxte/kera/model/session"
+	"github.com/zvxte/kera/model/user"
+)
+
+// Config represents the configuration of kera.
+type Config struct {
+	Server       Server       `toml:"server"`
+	Log          Log          `toml:"log"`
+	Metrics      Metrics      `toml:"metrics"`
+	Tracing      Tracing      `toml:"tracing"`
+	Database     Database     `toml:"database"`
+	Session      Session      `toml:"session"`
+	Registration Registration `toml:"registration"`
+	Habits       Habits       `toml:"habits"`
+	Jobs         Jobs         `toml:"jobs"`
+	Mail         Mail         `toml:"mail"`
+	OIDC         OIDC         `toml:"oidc"`
+	WebAuthn     WebAuthn     `toml:"webauthn"`
+	Password     Password     `toml:"password"`
+}
+
+type Server struct {
+	Address            string        `toml:"address" env:"ADDRESS" usage:"address the server listens on"`
+	ReadHeaderTimeout  time.Duration `toml:"read_header_timeout" env:"READ_HEADER_TIMEOUT" usage:"time to read request headers"`
+	ReadTimeout        time.Duration `toml:"read_timeout" env:"READ_TIMEOUT" usage:"time to read a whole request"`
+	WriteTimeout       time.Duration `toml:"write_timeout" env:"WRITE_TIMEOUT" usage:"time to write a response"`
+	IdleTimeout        time.Duration `toml:"idle_timeout" env:"IDLE_TIMEOUT" usage:"time to keep an idle connection open"`
+	RequestTimeout     time.Duration `toml:"request_timeout" env:"REQUEST_TIMEOUT" usage:"deadline of a request"`
+	LongRequestTimeout time.Duration `toml:"long_request_timeout" env:"LONG_REQUEST_TIMEOUT" usage:"deadline of exports, imports, calendar feeds, OIDC callbacks and admin password resets"`
+	MaxHeaderBytes     int           `toml:"max_header_bytes" env:"MAX_HEADER_BYTES" usage:"maximum size of request headers"`
+	ShutdownTimeout    time.Duration `toml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" usage:"time to drain requests and background work on shutdown"`
+	ShutdownDelay      time.Duration `toml:"shutdown_delay" env:"SHUTDOWN_DELAY" usage:"time /readyz fails before connections are refused on shutdown"`
+}
+
+type Log struct {
+	Level  string `toml:"level" env:"LOG_LEVEL" usage:"debug, info, warn or error"`
+	Format string `toml:"format" env:"LOG_FORMAT" usage:"json or text"`
+}
+
+type Metrics struct {
+	Enabled bool   `toml:"enabled" env:"METRICS_ENABLED" usage:"expose Prometheus metrics at /metrics"`
+	Address string `toml:"address" env:"METRICS_ADDRESS" usage:"separate address /metrics is served on, instead of the server address"`
+}
+
+type Tracing struct {
+	Enabled     bool    `toml:"enabled" env:"TRACING_ENABLED" usage:"export OpenTelemetry traces with OTLP over HTTP"`
+	Endpoint    string  `toml:"endpoint" env:"TRACING_ENDPOINT" usage:"URL traces are sent to"`
+	SampleRatio float64 `toml:"sample_ratio" env:"TRACING_SAMPLE_RATIO" usage:"ratio of traces started by the server that are recorded, from 0 to 1"`
+	ServiceName string  `toml:"service_name" env:"TRACING_SERVICE_NAME" usage:"service name of the traces"`
+}
+
+type Database struct {
+	DSN              Secret        `toml:"dsn" env:"DSN" usage:"data source name of the PostgreSQL database"`
+	MigrationTimeout time.Duration `toml:"migration_timeout" env:"MIGRATION_TIMEOUT" usage:"time to wait for the migration lock and apply pending migrations"`
+}
+
+type Session struct {
+	AbsoluteTimeout time.Duration `toml:"absolute_timeout" env:"SESSION_ABSOLUTE_TIMEOUT" usage:"maximum lifetime of a session"`
+	IdleTimeout     time.Duration `toml:"idle_timeout" env:"SESSION_IDLE_TIMEOUT" usage:"lifetime of a session without any activity"`
+}
+
+type Registration struct {
+	Mode           string   `toml:"mode" env:"REGISTRATION_MODE" usage:"open, closed, invite or approval"`
+	AdminUsernames []string `toml:"admin_usernames" env:"ADMIN_USERNAMES" usage:"users promoted to admins on startup"`
+}
+
+type Habits struct {
+	HistoryPatchWindow time.Duration `toml:"history_patch_window" env:"HISTORY_PATCH_WINDOW" usage:"how far back the history of a habit can be changed"`
+}
+
+type Jobs struct {
+	Interval time.Duration `toml:"interval" env:"JOB_INTERVAL" usage:"interval of background cleanup jobs"`
+	Jitter   time.Duration `toml:"jitter" env:"JOB_JITTER" usage:"maximum random delay added to the job interval"`
+}
+
+type Mail struct {
+	Mailer string `toml:"mailer" env:"MAILER" usage:"log, file or smtp"`
+	From   string `toml:"from" env:"MAIL_FROM" usage:"sender address of emails"`
+	File   string `toml:"file" env:"MAIL_FILE" usage:"file the emails are appended to, with the file mailer"`
+	SMTP   SMTP   `toml:"smtp"`
+}
+
+type SMTP struct {
+	Host     string `toml:"host" env:"SMTP_HOST" usage:"SMTP server of the smtp mailer"`
+	Port     uint16 `toml:"port" env:"SMTP_PORT" usage:"port of the SMTP server"`
+	Username string `toml:"username" env:"SMTP_USERNAME" usage:"username of the SMTP server"`
+	Password Secret `toml:"password" env:"SMTP_PASSWORD" usage:"password of the SMTP server"`
+}
+
+type OIDC struct {
+	IssuerURL     string   `toml:"issuer_url" env:"OIDC_ISSUER_URL" usage:"issuer of an OpenID provider, enables login with it"`
+	ClientID      string   `toml:"client_id" env:"OIDC_CLIENT_ID" usage:"client ID registered at the provider"`
+	ClientSecret  Secret   `toml:"client_secret" env:"OIDC_CLIENT_SECRET" usage:"client secret registered at the provider"`
+	RedirectURL   string   `toml:"redirect_url" env:"OIDC_REDIRECT_URL" usage:"redirect URL pointing to /auth/oidc/callback"`
+	Scopes        []string `toml:"scopes" env:"OIDC_SCOPES" usage:"requested scopes besides openid"`
+	AutoProvision bool     `toml:"auto_provision" env:"OIDC_AUTO_PROVISION" usage:"create a user for an unknown identity"`
+	PostLoginURL  string   `toml:"post_login_url" env:"OIDC_POST_LOGIN_URL" usage:"where to redirect after login"`
+}
+
+type WebAuthn struct {
+	RPID                    string   `toml:"rp_id" env:"WEBAUTHN_RP_ID" usage:"domain passkeys are bound to, enables passkeys"`
+	RPName                  string   `toml:"rp_name" env:"WEBAUTHN_RP_NAME" usage:"application name shown by authenticators"`
+	Origins                 []string `toml:"origins" env:"WEBAUTHN_ORIGINS" usage:"allowed origins, default https:// + rp_id"`
+	RequireUserVerification bool     `toml:"require_user_verification" env:"WEBAUTHN_REQUIRE_USER_VERIFICATION" usage:"require a PIN or biometrics"`
+}
+
+type Password struct {
+	MinEntropy   float64 `toml:"min_entropy" env:"PASSWORD_MIN_ENTROPY" usage:"minimum estimated entropy of new passwords in bits"`
+	BreachedList string  `toml:"breached_list" env:"PASSWORD_BREACHED_LIST" usage:"file or directory of SHA-1 hashes of breached passwords"`
+	Peppers      Secret  `toml:"peppers" env:"PASSWORD_PEPPERS" usage:"comma separated KEY_ID:BASE64_KEY secrets mixed into password hashes"`
+	Argon2       Argon2  `toml:"argon2"`
+}
+
+type Argon2 struct {
+	Memory      uint32        `toml:"memory" env:"ARGON2_MEMORY" usage:"argon2id memory in KiB"`
+	Iterations  uint32        `toml:"iterations" env:"ARGON2_ITERATIONS" usage:"argon2id iterations"`
+	Parallelism uint8         `toml:"parallelism" env:"ARGON2_PARALLELISM" usage:"argon2id parallelism"`
+	TargetTime  time.Duration `toml:"target_time" env:"ARGON2_TARGET_TIME" usage:"calibrate the iterations so hashing takes about this long"`
+}
+
+// Secret represents a value that must not be shown,
+// it's redacted when formatted or printed.
+type Secret string
+
+const redacted = "<redacted>"
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+// LogValue implements slog.LogValuer, so secrets are redacted in logs.
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(s.String())
+}
+
+// Value returns the secret value.
+func (s Secret) Value() string {
+	return string(s)
+}
+
+// Default returns a *Config with the default value of every option.
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Address:            ":8080",
+			ReadHeaderTimeout:  5 * time.Second,
+			ReadTimeout:        30 * time.Second,
+			WriteTimeout:       60 * time.Second,
+			IdleTimeout:        2 * time.Minute,
+			RequestTimeout:     5 * time.Second,
+			LongRequestTimeout: 30 * time.Second,
+			MaxHeaderBytes:     64 << 10,
+			ShutdownTimeout:    30 * time.Second,
+		},
+		Log: Log{
+			Level:  "info",
+			Format: "json",
+		},
+		Metrics: Metrics{
+			Enabled: true,
+		},
+		Tracing: Tracing{
+			Endpoint:    "http://localhost:4318/v1/traces",
+			SampleRatio: 1,
+			ServiceName: "kera",
+		},
+		Database: Database{
+			MigrationTimeout: 10 * time.Minute,
+		},
+		Session: Session{
+			AbsoluteTimeout: session.DefaultLifetime.AbsoluteTimeout,
+			IdleTimeout:     session.DefaultLifetime.IdleTimeout,
+		},
+		Registration: Registration{
+			Mode: "open",
+		},
+		Habits: Habits{
+			HistoryPatchWindow: habit.HistoryPatchWindow,
+		},
+		Jobs: Jobs{
+			Interval: time.Hour,
+			Jitter:   5 * time.Minute,
+		},
+		Mail: Mail{
+			Mailer: "log",
+			From:   "kera <no-reply@localhost>",
+			SMTP:   SMTP{Port: 587},
+		},
+		OIDC: OIDC{
+			Scopes:       []string{"email", "profile"},
+			PostLoginURL: "/",
+		},
+		WebAuthn: WebAuthn{
+			RPName: "kera",
+		},
+		Password: Password{
+			MinEntropy: user.DefaultPasswordPolicy.MinEntropy,
+			Argon2: Argon2{
+				Memory:      argon2id.DefaultParams.Memory(),
+				Iterations:  argon2id.DefaultParams.Iterations(),
+				Parallelism: argon2id.DefaultParams.Parallelism(),
+			},
+		},
+	}
+}
@@ -0,0 +1,327 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// fileSuffix is the suffix of keys and flags of secrets read from a file.
+	fileSuffix = "_file"
+
+	// configEnv is the environment variable of the config file path,
+	// if the -config flag is not set.
+	configEnv = "KERA_CONFIG"
+)
+
+var (
+	ErrUnknownKey   = errors.New("key is unknown")
+	ErrInvalidValue = errors.New("value is invalid")
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	secretType   = reflect.TypeOf(Secret(""))
+)
+
+// field represents an option of a Config.
+type field struct {
+	key   string
+	env   string
+	usage string
+	value reflect.Value
+}
+
+func (f field) secret() bool {
+	return f.value.Type() == secretType
+}
+
+// fields returns the options of the config in their order,
+// their values can be set.
+func fields(c *Config) []field {
+	var fs []field
+	var walk func(prefix string, v reflect.Value)
+	walk = func(prefix string, v reflect.Value) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			key := prefix + sf.Tag.Get("toml")
+			if sf.Type.Kind() == reflect.Struct {
+				walk(key+".", v.Field(i))
+				continue
+			}
+			fs = append(fs, field{
+				key:   key,
+				env:   sf.Tag.Get("env"),
+				usage: sf.Tag.Get("usage"),
+				value: v.Field(i),
+			})
+		}
+	}
+	walk("", reflect.ValueOf(c).Elem())
+	return fs
+}
+
+// Load returns the config from the defaults, the config file,
+// the environment variables looked up by lookupEnv and the flags
+// in the args, each overriding the previous.
+// The flags of the options and -config are defined on the FlagSet,
+// so it can define flags of its own. It's parsed with the args.
+// The returned config is not validated, see [Config.Validate].
+// It fails if the FlagSet fails, the config file can't be read,
+// or if a value is invalid.
+func Load(
+	fs *flag.FlagSet, args []string, lookupEnv func(string) (string, bool),
+) (*Config, error) {
+	c := Default()
+	options := fields(c)
+
+	path := fs.String("config", "", "path of the TOML config file, or "+configEnv)
+
+	// Flags are applied last, once the config file and env are loaded
+	type flagValue struct {
+		f     field
+		value string
+		file  bool
+	}
+	var flagValues []flagValue
+
+	for _, f := range options {
+		usage := f.usage
+		if f.env != "" {
+			usage += " (" + f.env + ")"
+		}
+		fs.Func(f.key, usage, func(s string) error {
+			flagValues = append(flagValues, flagValue{f, s, false})
+			return nil
+		})
+		if f.secret() {
+			fs.Func(f.key+fileSuffix, "file containing "+f.key, func(s string) error {
+				flagValues = append(flagValues, flagValue{f, s, true})
+				return nil
+			})
+		}
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *path == "" {
+		*path, _ = lookupEnv(configEnv)
+	}
+	if *path != "" {
+		if err := c.loadFile(*path, options); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, f := range options {
+		if f.env == "" {
+			continue
+		}
+		s, ok := lookupEnv(f.env)
+		if ok {
+			if err := set(f, s); err != nil {
+				return nil, fmt.Errorf("%s: %w", f.env, err)
+			}
+		}
+		if !f.secret() {
+			continue
+		}
+
+		fileEnv := f.env + strings.ToUpper(fileSuffix)
+		if path, fileOk := lookupEnv(fileEnv); fileOk {
+			if ok {
+				return nil, fmt.Errorf("%s: %w: %s is set too", fileEnv, ErrInvalidValue, f.env)
+			}
+			if err := setFromFile(f, path); err != nil {
+				return nil, fmt.Errorf("%s: %w", fileEnv, err)
+			}
+		}
+	}
+
+	// A secret can't be set both directly and from a file,
+	// either would win depending on the order of the flags
+	for _, fv := range flagValues {
+		if !fv.file {
+			continue
+		}
+		for _, other := range flagValues {
+			if !other.file && other.f.key == fv.f.key {
+				return nil, fmt.Errorf(
+					"-%s%s: %w: -%s is set too", fv.f.key, fileSuffix, ErrInvalidValue, fv.f.key,
+				)
+			}
+		}
+	}
+
+	for _, fv := range flagValues {
+		var err error
+		if fv.file {
+			err = setFromFile(fv.f, fv.value)
+		} else {
+			err = set(fv.f, fv.value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("-%s: %w", fv.f.key, err)
+		}
+	}
+
+	return c, nil
+}
+
+func (c *Config) loadFile(path string, options []field) error {
+	document, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	values, err := parse(string(document))
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	byKey := make(map[string]field, len(options))
+	for _, f := range options {
+		byKey[f.key] = f
+
+		// The values are not ordered, either would win
+		if v, ok := values[f.key+fileSuffix]; ok && f.secret() {
+			if _, ok := values[f.key]; ok {
+				return fmt.Errorf("%s: %w", path, &SyntaxError{
+					v.line, fmt.Errorf(
+						"%s%s: %w: %s is set too", f.key, fileSuffix, ErrInvalidValue, f.key,
+					),
+				})
+			}
+		}
+	}
+
+	for key, v := range values {
+		f, ok := byKey[key]
+		file := false
+		if !ok {
+			name, found := strings.CutSuffix(key, fileSuffix)
+			f, ok = byKey[name]
+			ok = ok && found && f.secret()
+			file = true
+		}
+		if !ok {
+			return fmt.Errorf("%s: %w", path, &SyntaxError{
+				v.line, fmt.Errorf("%w: %q", ErrUnknownKey, key),
+			})
+		}
+
+		switch {
+		case file:
+			if !v.quoted {
+				err = ErrInvalidValue
+			} else {
+				err = setFromFile(f, v.raw)
+			}
+		case v.isList():
+			err = setList(f, v.list)
+		case v.quoted != quoted(f):
+			err = ErrInvalidValue
+		default:
+			err = set(f, v.raw)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, &SyntaxError{
+				v.line, fmt.Errorf("%s: %w", key, err),
+			})
+		}
+	}
+
+	return nil
+}
+
+// quoted returns true if the value of the option is a string in a config file.
+func quoted(f field) bool {
+	t := f.value.Type()
+	return t.Kind() == reflect.String || t == durationType
+}
+
+// set sets the option from its string representation,
+// lists are separated by commas or spaces.
+func set(f field, s string) error {
+	v := f.value
+
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("%w: %q", ErrInvalidValue, s)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("%w: %q", ErrInvalidValue, s)
+		}
+		v.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%w: %q", ErrInvalidValue, s)
+		}
+		v.SetInt(i)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%w: %q", ErrInvalidValue, s)
+		}
+		v.SetUint(u)
+
+	case reflect.Float64:
+		x, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("%w: %q", ErrInvalidValue, s)
+		}
+		v.SetFloat(x)
+
+	case reflect.Slice:
+		return setList(f, strings.FieldsFunc(s, func(c rune) bool {
+			return c == ',' || c == ' ' || c == '\t' || c == '\n'
+		}))
+
+	default:
+		return fmt.Errorf("%w: unsupported type %s", ErrInvalidValue, v.Type())
+	}
+
+	return nil
+}
+
+func setList(f field, list []string) error {
+	if f.value.Kind() != reflect.Slice {
+		return ErrInvalidValue
+	}
+	f.value.Set(reflect.ValueOf(append([]string{}, list...)))
+	return nil
+}
+
+// setFromFile sets the secret option to the content of the file,
+// without trailing new lines.
+func setFromFile(f field, path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read secret: %w", err)
+	}
+	f.value.SetString(strings.TrimRight(string(content), "\r\n"))
+	return nil
+}
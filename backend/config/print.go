@@ -0,0 +1,91 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Print writes the config to w as a TOML config file,
+// with the values of secrets redacted.
+// It fails if w fails.
+func (c *Config) Print(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	table := ""
+
+	for _, f := range fields(c) {
+		name := f.key
+		if i := strings.LastIndexByte(f.key, '.'); i >= 0 {
+			if f.key[:i] != table {
+				if table != "" {
+					bw.WriteByte('\n')
+				}
+				table = f.key[:i]
+				fmt.Fprintf(bw, "[%s]\n", table)
+			}
+			name = f.key[i+1:]
+		}
+		fmt.Fprintf(bw, "%s = %s\n", name, format(f))
+	}
+
+	return bw.Flush()
+}
+
+// format returns the value of the option as a TOML value.
+func format(f field) string {
+	v := f.value
+
+	switch {
+	case f.secret():
+		return quote(v.Interface().(Secret).String())
+	case v.Type() == durationType:
+		return quote(time.Duration(v.Int()).String())
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return quote(v.String())
+	case reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = quote(v.Index(i).String())
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case reflect.Float64:
+		x := strconv.FormatFloat(v.Float(), 'f', -1, 64)
+		if !strings.Contains(x, ".") {
+			x += ".0"
+		}
+		return x
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+// quote returns s as a TOML basic string.
+func quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, c := range s {
+		switch {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(c)
+		case c == '\n':
+			b.WriteString(`\n`)
+		case c == '\t':
+			b.WriteString(`\t`)
+		case unicode.IsControl(c):
+			fmt.Fprintf(&b, `\u%04X`, c)
+		default:
+			b.WriteRune(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
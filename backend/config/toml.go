@@ -0,0 +1,281 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidSyntax = errors.New("syntax is invalid")
+	ErrDuplicateKey  = errors.New("key is defined twice")
+)
+
+// SyntaxError represents an error in a config file.
+type SyntaxError struct {
+	Line int
+	Err  error
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// value represents a value of a config file.
+type value struct {
+	// raw is the unquoted string or the literal of other values.
+	raw string
+	// quoted is true if the value is a string.
+	quoted bool
+	// list is set if the value is an array of strings.
+	list []string
+	line int
+}
+
+func (v value) isList() bool {
+	return v.list != nil
+}
+
+// parse returns the values of a TOML document by their full keys,
+// like mail.smtp.host. It supports the subset of TOML a config needs:
+// comments, tables, bare and dotted keys, basic and literal strings,
+// integers, floats, booleans and arrays of strings,
+// which can span multiple lines.
+// It fails with a *[SyntaxError] wrapping [ErrInvalidSyntax]
+// or [ErrDuplicateKey].
+func parse(document string) (map[string]value, error) {
+	values := make(map[string]value)
+	table := ""
+
+	lines := strings.Split(strings.ReplaceAll(document, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		number := i + 1
+		line := strings.TrimSpace(lines[i])
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		if line[0] == '[' {
+			name, ok := parseTable(line)
+			if !ok {
+				return nil, &SyntaxError{number, ErrInvalidSyntax}
+			}
+			table = name + "."
+			continue
+		}
+
+		rawKey, rawValue, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, &SyntaxError{number, ErrInvalidSyntax}
+		}
+		key, ok := parseKey(rawKey)
+		if !ok {
+			return nil, &SyntaxError{number, ErrInvalidSyntax}
+		}
+		key = table + key
+
+		rawValue = strings.TrimSpace(rawValue)
+		// Arrays continue until their closing bracket
+		for strings.HasPrefix(rawValue, "[") && !closed(rawValue) && i+1 < len(lines) {
+			i++
+			rawValue += "\n" + lines[i]
+		}
+
+		v, ok := parseValue(rawValue)
+		if !ok {
+			return nil, &SyntaxError{number, ErrInvalidSyntax}
+		}
+		v.line = number
+
+		if _, ok := values[key]; ok {
+			return nil, &SyntaxError{number, fmt.Errorf("%w: %q", ErrDuplicateKey, key)}
+		}
+		values[key] = v
+	}
+
+	return values, nil
+}
+
+func parseTable(line string) (string, bool) {
+	line = stripComment(line)
+	if !strings.HasPrefix(line, "[") || !strings.HasSuffix(line, "]") ||
+		strings.HasPrefix(line, "[[") {
+		return "", false
+	}
+	return parseKey(line[1 : len(line)-1])
+}
+
+// parseKey returns the dotted key without spaces around the dots.
+func parseKey(rawKey string) (string, bool) {
+	parts := strings.Split(rawKey, ".")
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return "", false
+		}
+		for _, c := range part {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' ||
+				c >= '0' && c <= '9' || c == '_' || c == '-') {
+				return "", false
+			}
+		}
+		parts[i] = part
+	}
+	return strings.Join(parts, "."), true
+}
+
+func parseValue(raw string) (value, bool) {
+	if strings.HasPrefix(raw, "[") {
+		list, rest, ok := parseArray(raw)
+		if !ok || stripComment(rest) != "" {
+			return value{}, false
+		}
+		return value{list: list}, true
+	}
+
+	if strings.HasPrefix(raw, `"`) || strings.HasPrefix(raw, "'") {
+		s, rest, ok := parseString(raw)
+		if !ok || stripComment(rest) != "" {
+			return value{}, false
+		}
+		return value{raw: s, quoted: true}, true
+	}
+
+	literal := stripComment(raw)
+	if literal == "" || strings.ContainsAny(literal, " \t\"'[]") {
+		return value{}, false
+	}
+	// Underscores may separate digits of numbers
+	return value{raw: strings.ReplaceAll(literal, "_", "")}, true
+}
+
+// parseArray parses an array of strings at the start of s,
+// and returns the rest of s.
+func parseArray(s string) ([]string, string, bool) {
+	list := []string{}
+	s = s[1:]
+
+	for {
+		s = skipBlank(s)
+		if strings.HasPrefix(s, "]") {
+			return list, s[1:], true
+		}
+
+		item, rest, ok := parseString(s)
+		if !ok {
+			return nil, "", false
+		}
+		list = append(list, item)
+
+		s = skipBlank(rest)
+		switch {
+		case strings.HasPrefix(s, ","):
+			s = s[1:]
+		case strings.HasPrefix(s, "]"):
+			return list, s[1:], true
+		default:
+			return nil, "", false
+		}
+	}
+}
+
+// parseString parses a basic or literal string at the start of s,
+// and returns the rest of s.
+func parseString(s string) (string, string, bool) {
+	if strings.HasPrefix(s, "'") {
+		end := strings.IndexAny(s[1:], "'\n")
+		if end < 0 || s[1+end] != '\'' {
+			return "", "", false
+		}
+		return s[1 : 1+end], s[2+end:], true
+	}
+
+	if !strings.HasPrefix(s, `"`) {
+		return "", "", false
+	}
+
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"':
+			return b.String(), s[i+1:], true
+		case '\n':
+			return "", "", false
+		case '\\':
+			if i+1 >= len(s) {
+				return "", "", false
+			}
+			i++
+			switch s[i] {
+			case '"', '\\':
+				b.WriteByte(s[i])
+			case 'b':
+				b.WriteByte('\b')
+			case 't':
+				b.WriteByte('\t')
+			case 'n':
+				b.WriteByte('\n')
+			case 'f':
+				b.WriteByte('\f')
+			case 'r':
+				b.WriteByte('\r')
+			case 'u', 'U':
+				n := 4
+				if s[i] == 'U' {
+					n = 8
+				}
+				if i+n >= len(s) {
+					return "", "", false
+				}
+				code, err := strconv.ParseUint(s[i+1:i+1+n], 16, 32)
+				if err != nil || !utf8.ValidRune(rune(code)) {
+					return "", "", false
+				}
+				b.WriteRune(rune(code))
+				i += n
+			default:
+				return "", "", false
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return "", "", false
+}
+
+// skipBlank skips whitespace, new lines and comments.
+func skipBlank(s string) string {
+	for {
+		s = strings.TrimLeft(s, " \t\n")
+		if !strings.HasPrefix(s, "#") {
+			return s
+		}
+		end := strings.IndexByte(s, '\n')
+		if end < 0 {
+			return ""
+		}
+		s = s[end:]
+	}
+}
+
+// stripComment returns s without a trailing comment and spaces,
+// s must not contain strings.
+func stripComment(s string) string {
+	if i := strings.IndexByte(s, '#'); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
+
+// closed returns true if the array at the start of s is closed.
+func closed(s string) bool {
+	_, _, ok := parseArray(s)
+	return ok
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/zvxte/kera/logging"
+)
+
+var (
+	ErrMissingValue = errors.New("value is required")
+	ErrNotPositive  = errors.New("value must be positive")
+	ErrNegative     = errors.New("value must not be negative")
+)
+
+// Validate returns nil if the config can be used to run the server,
+// else an error joining every problem found.
+// Options that need other packages to be validated, like argon2id params
+// or password peppers, are validated when the server is created.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(key string, err error) {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+		}
+	}
+
+	check("server.address", required(c.Server.Address))
+	check("server.read_header_timeout", positive(c.Server.ReadHeaderTimeout))
+	check("server.read_timeout", positive(c.Server.ReadTimeout))
+	check("server.write_timeout", positive(c.Server.WriteTimeout))
+	check("server.idle_timeout", positive(c.Server.IdleTimeout))
+	check("server.request_timeout", positive(c.Server.RequestTimeout))
+	check("server.long_request_timeout", positive(c.Server.LongRequestTimeout))
+	check("server.shutdown_timeout", positive(c.Server.ShutdownTimeout))
+	check("server.shutdown_delay", notNegative(c.Server.ShutdownDelay))
+	if c.Server.MaxHeaderBytes <= 0 {
+		check("server.max_header_bytes", ErrNotPositive)
+	}
+
+	_, err := logging.ParseLevel(c.Log.Level)
+	check("log.level", err)
+	check("log.format", oneOf(c.Log.Format, "json", "text"))
+
+	if c.Tracing.Enabled {
+		check("tracing.endpoint", required(c.Tracing.Endpoint))
+		check("tracing.service_name", required(c.Tracing.ServiceName))
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		check("tracing.sample_ratio", fmt.Errorf(
+			"%w: %v is not between 0 and 1", ErrInvalidValue, c.Tracing.SampleRatio,
+		))
+	}
+
+	check("database.dsn", required(c.Database.DSN.Value()))
+	check("database.migration_timeout", positive(c.Database.MigrationTimeout))
+
+	check("session.absolute_timeout", positive(c.Session.AbsoluteTimeout))
+	check("session.idle_timeout", positive(c.Session.IdleTimeout))
+
+	check("registration.mode", oneOf(
+		c.Registration.Mode, "open", "closed", "invite", "approval",
+	))
+
+	check("habits.history_patch_window", positive(c.Habits.HistoryPatchWindow))
+
+	check("jobs.interval", positive(c.Jobs.Interval))
+	check("jobs.jitter", notNegative(c.Jobs.Jitter))
+
+	check("mail.mailer", oneOf(c.Mail.Mailer, "log", "file", "smtp"))
+	switch c.Mail.Mailer {
+	case "file":
+		check("mail.file", required(c.Mail.File))
+	case "smtp":
+		check("mail.smtp.host", required(c.Mail.SMTP.Host))
+		if c.Mail.SMTP.Port == 0 {
+			check("mail.smtp.port", ErrNotPositive)
+		}
+	}
+
+	if c.OIDC.IssuerURL != "" {
+		check("oidc.client_id", required(c.OIDC.ClientID))
+		check("oidc.redirect_url", required(c.OIDC.RedirectURL))
+	}
+
+	if c.Password.MinEntropy < 0 {
+		check("password.min_entropy", ErrNegative)
+	}
+	check("password.argon2.target_time", notNegative(c.Password.Argon2.TargetTime))
+
+	return errors.Join(errs...)
+}
+
+func required(s string) error {
+	if s == "" {
+		return ErrMissingValue
+	}
+	return nil
+}
+
+func positive(d time.Duration) error {
+	if d <= 0 {
+		return ErrNotPositive
+	}
+	return nil
+}
+
+func notNegative(d time.Duration) error {
+	if d < 0 {
+		return ErrNegative
+	}
+	return nil
+}
+
+func oneOf(s string, names ...string) error {
+	if !slices.Contains(names, s) {
+		return fmt.Errorf("%w: %q", ErrInvalidValue, s)
+	}
+	return nil
+}
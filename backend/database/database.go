@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
@@ -10,6 +11,10 @@ import (
 
 const PostgresDriverName = "pgx"
 
+var ErrMigrationVersionMismatch = errors.New(
+	"database migrations do not match the embedded migrations",
+)
+
 type SqlDatabase struct {
 	DB *sql.DB
 }
@@ -38,78 +43,59 @@ func NewSqlDatabase(ctx context.Context, driverName string, dataSourceName strin
 	return &SqlDatabase{DB: db}, nil
 }
 
-// Setup sets up database migrations.
+// Setup applies every pending migration, see [Migrator].
 func (sd *SqlDatabase) Setup(ctx context.Context) error {
-	migrations, err := getMigrations()
+	migrator, err := NewMigrator(sd.DB)
 	if err != nil {
 		return err
 	}
 
-	// Migrations table exists if SqlDatabase.getDatabaseMigrationVersion() method does not return an error.
-	// In this case we want to exclude migrations with lower or equal version.
-
-	latestMigrationVersion := migrations[len(migrations)-1].version
-	databaseMigrationVersion, err := sd.getDatabaseMigrationVersion(ctx)
-	if err == nil {
-		if databaseMigrationVersion == latestMigrationVersion {
-			return nil
-		}
-
-		if databaseMigrationVersion > latestMigrationVersion {
-			return fmt.Errorf("database migration version %d is greater than latest migration version %d", databaseMigrationVersion, latestMigrationVersion)
-		}
-
-		for i, migration := range migrations {
-			if migration.version >= databaseMigrationVersion {
-				migrations = migrations[i+1:]
-				break
-			}
-		}
-	}
-
-	// Now we can execute remaining migrations in a single transaction.
-
-	tx, err := sd.DB.BeginTx(ctx, nil)
-	defer tx.Rollback()
+	_, err = migrator.Up(ctx, false)
+	return err
+}
 
-	for _, migration := range migrations {
-		_, err = tx.ExecContext(ctx, migration.query)
-		if err != nil {
-			return fmt.Errorf("failed to execute %q: %w", migration.query, err)
-		}
+// Ping verifies the connection to the database is alive.
+func (sd *SqlDatabase) Ping(ctx context.Context) error {
+	err := sd.DB.PingContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
 	}
+	return nil
+}
 
-	query := `
-		INSERT INTO migrations (id, version)
-		VALUES (0, $1)
-		ON CONFLICT (id)
-		DO UPDATE SET version = $1;
-	`
-	_, err = tx.ExecContext(ctx, query, latestMigrationVersion)
+// CheckMigrations returns nil if every embedded migration is applied
+// as it is, and no unknown one is, else [ErrMigrationVersionMismatch].
+// It fails if the migration history can't be read.
+func (sd *SqlDatabase) CheckMigrations(ctx context.Context) error {
+	migrator, err := NewMigrator(sd.DB)
 	if err != nil {
-		return fmt.Errorf("failed to update database migration version: %w", err)
+		return err
 	}
 
-	err = tx.Commit()
+	statuses, err := migrator.Status(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to commit migrations transaction: %w", err)
+		return err
 	}
 
-	return nil
-}
-
-func (sd *SqlDatabase) getDatabaseMigrationVersion(ctx context.Context) (uint16, error) {
-	query := `
-		SELECT version FROM migrations;
-	`
-	row := sd.DB.QueryRowContext(ctx, query)
+	var pending, edited, unknown int
+	for _, status := range statuses {
+		switch {
+		case !status.Applied:
+			pending++
+		case status.Edited:
+			edited++
+		case status.Unknown:
+			unknown++
+		}
+	}
 
-	var databaseMigrationVersion uint16
-	err := row.Scan(&databaseMigrationVersion)
-	if err != nil {
-		return databaseMigrationVersion, fmt.Errorf("failed to get migrations version: %w", err)
+	if pending+edited+unknown != 0 {
+		return fmt.Errorf(
+			"%w: %d pending, %d edited and %d unknown migrations",
+			ErrMigrationVersionMismatch, pending, edited, unknown,
+		)
 	}
-	return databaseMigrationVersion, nil
+	return nil
 }
 
 // Teardown drops database migrations.
@@ -89,8 +89,20 @@ func TestSqlDatabase(t *testing.T) {
 		}
 	})
 
-	t.Run("getDatabaseMigrationVersion", func(t *testing.T) {
-		if _, err := sqlDatabase.getDatabaseMigrationVersion(ctx); err != nil {
+	t.Run("readMigrationHistory", func(t *testing.T) {
+		if _, err := readMigrationHistory(ctx, sqlDatabase.DB, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Ping", func(t *testing.T) {
+		if err := sqlDatabase.Ping(ctx); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("CheckMigrations", func(t *testing.T) {
+		if err := sqlDatabase.CheckMigrations(ctx); err != nil {
 			t.Error(err)
 		}
 	})
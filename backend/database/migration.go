@@ -1,51 +1,120 @@
 package database
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 )
 
-const migrationsAssetsDir = "migrations"
+const (
+	migrationsAssetsDir = "migrations"
 
+	upMigrationSuffix   = ".up.sql"
+	downMigrationSuffix = ".down.sql"
+)
+
+// migration represents a pair of embedded migration files,
+// like 005_create_habits.up.sql and 005_create_habits.down.sql.
 type migration struct {
 	version uint16
-	query   string
+	// name is the file name without the direction and extension,
+	// like 005_create_habits.
+	name     string
+	up       string
+	down     string
+	checksum string
 }
 
-func newMigration(filePath string) (migration, error) {
-	fileName := filepath.Base(filePath)
-	if len(fileName) < 3 || filepath.Ext(strings.ToLower(fileName)) != ".sql" {
-		return migration{}, fmt.Errorf("invalid migration file name: %q", fileName)
+// newMigration returns the migration of the up file and its down file.
+// The checksum is the hex encoded SHA-256 hash of the up file,
+// it tells if an applied migration was edited since.
+func newMigration(upFilePath string) (migration, error) {
+	version, name, isUp, err := parseMigrationFileName(filepath.Base(upFilePath))
+	if err != nil {
+		return migration{}, err
+	}
+	if !isUp {
+		return migration{}, fmt.Errorf("not an up migration file: %q", upFilePath)
+	}
+	if filepath.Dir(upFilePath) != migrationsAssetsDir {
+		return migration{}, fmt.Errorf("invalid migration file path: %q", upFilePath)
 	}
 
-	version, err := strconv.ParseUint(fileName[:3], 10, 16)
+	up, err := assets.ReadFile(upFilePath)
 	if err != nil {
-		return migration{}, fmt.Errorf("failed to parse migration version: %q", fileName)
+		return migration{}, fmt.Errorf("failed to read %q: %w", upFilePath, err)
+	}
+
+	downFilePath := filepath.Join(migrationsAssetsDir, name+downMigrationSuffix)
+	down, err := assets.ReadFile(downFilePath)
+	if err != nil {
+		return migration{}, fmt.Errorf("failed to read %q: %w", downFilePath, err)
+	}
+
+	checksum := sha256.Sum256(up)
+	return migration{
+		version:  version,
+		name:     name,
+		up:       string(up),
+		down:     string(down),
+		checksum: hex.EncodeToString(checksum[:]),
+	}, nil
+}
+
+// parseMigrationFileName returns the version and name of a migration file
+// named like 005_create_habits.up.sql or 005_create_habits.down.sql,
+// and whether it's the up file.
+func parseMigrationFileName(fileName string) (uint16, string, bool, error) {
+	name, isUp := strings.CutSuffix(fileName, upMigrationSuffix)
+	if !isUp {
+		var isDown bool
+		name, isDown = strings.CutSuffix(fileName, downMigrationSuffix)
+		if !isDown {
+			return 0, "", false, fmt.Errorf("invalid migration file name: %q", fileName)
+		}
 	}
 
-	content, err := assets.ReadFile(filePath)
+	versionPart, description, found := strings.Cut(name, "_")
+	if !found || len(versionPart) != 3 || description == "" {
+		return 0, "", false, fmt.Errorf("invalid migration file name: %q", fileName)
+	}
+
+	version, err := strconv.ParseUint(versionPart, 10, 16)
 	if err != nil {
-		return migration{}, fmt.Errorf("failed to read %q: %w", fileName, err)
+		return 0, "", false, fmt.Errorf("failed to parse migration version: %q", fileName)
 	}
 
-	return migration{version: uint16(version), query: string(content)}, nil
+	return uint16(version), name, isUp, nil
 }
 
-// getMigrations returns all migrations found in the embedded migrations directory sorted by file name.
+// getMigrations returns all migrations found in the embedded migrations directory sorted by version.
+// It fails if a file is misnamed, lacks its pair or two migrations share a version.
 func getMigrations() ([]migration, error) {
 	entries, err := assets.ReadDir(migrationsAssetsDir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
 	}
 
-	migrations := make([]migration, 0, len(entries))
+	migrations := make([]migration, 0, len(entries)/2)
+	downFiles := 0
 	for _, entry := range entries {
 		if entry.IsDir() {
 			continue
 		}
 
+		_, _, isUp, err := parseMigrationFileName(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		if !isUp {
+			downFiles++
+			continue
+		}
+
 		entryPath := filepath.Join(migrationsAssetsDir, entry.Name())
 		migration, err := newMigration(entryPath)
 		if err != nil {
@@ -55,5 +124,23 @@ func getMigrations() ([]migration, error) {
 		migrations = append(migrations, migration)
 	}
 
+	if downFiles != len(migrations) {
+		return nil, fmt.Errorf(
+			"found %d down migrations for %d up migrations", downFiles, len(migrations),
+		)
+	}
+
+	slices.SortFunc(migrations, func(a, b migration) int {
+		return int(a.version) - int(b.version)
+	})
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].version == migrations[i-1].version {
+			return nil, fmt.Errorf(
+				"migrations %q and %q share version %d",
+				migrations[i-1].name, migrations[i].name, migrations[i].version,
+			)
+		}
+	}
+
 	return migrations, nil
 }
@@ -13,27 +13,42 @@ func TestNewMigration(t *testing.T) {
 	}{
 		{
 			"Valid",
-			filepath.Join(migrationsAssetsDir, "000_create_migrations.sql"),
+			filepath.Join(migrationsAssetsDir, "000_create_migrations.up.sql"),
 			false,
 		},
+		{
+			"Invalid: down file",
+			filepath.Join(migrationsAssetsDir, "000_create_migrations.down.sql"),
+			true,
+		},
+		{
+			"Invalid: missing file",
+			filepath.Join(migrationsAssetsDir, "999_missing.up.sql"),
+			true,
+		},
 		{
 			"Invalid: directory",
-			filepath.Join("migr", "000_create_migrations.sql"),
+			filepath.Join("migr", "000_create_migrations.up.sql"),
 			true,
 		},
 		{
 			"Invalid: empty directory",
-			filepath.Join("", "000_create_migrations.sql"),
+			filepath.Join("", "000_create_migrations.up.sql"),
 			true,
 		},
 		{
 			"Invalid: file name",
-			filepath.Join(migrationsAssetsDir, "00_create_migrations.sql"),
+			filepath.Join(migrationsAssetsDir, "00_create_migrations.up.sql"),
 			true,
 		},
 		{
 			"Invalid: file name",
-			filepath.Join(migrationsAssetsDir, "000.sql"),
+			filepath.Join(migrationsAssetsDir, "000.up.sql"),
+			true,
+		},
+		{
+			"Invalid: without direction",
+			filepath.Join(migrationsAssetsDir, "000_create_migrations.sql"),
 			true,
 		},
 		{
@@ -66,8 +81,57 @@ func TestNewMigration(t *testing.T) {
 	}
 }
 
+func TestParseMigrationFileName(t *testing.T) {
+	tests := []struct {
+		name      string
+		fileName  string
+		version   uint16
+		isUp      bool
+		shouldErr bool
+	}{
+		{"Valid: up", "005_create_habits.up.sql", 5, true, false},
+		{"Valid: down", "005_create_habits.down.sql", 5, false, false},
+		{"Invalid: without direction", "005_create_habits.sql", 0, false, true},
+		{"Invalid: without description", "005_.up.sql", 0, false, true},
+		{"Invalid: short version", "05_create_habits.up.sql", 0, false, true},
+		{"Invalid: version", "abc_create_habits.up.sql", 0, false, true},
+		{"Invalid: empty", "", 0, false, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			version, _, isUp, err := parseMigrationFileName(test.fileName)
+			if (err != nil) != test.shouldErr {
+				t.Fatalf(
+					"parseMigrationFileName(%q), error=%v, shouldErr=%v",
+					test.fileName, err, test.shouldErr,
+				)
+			}
+			if version != test.version || isUp != test.isUp {
+				t.Errorf(
+					"parseMigrationFileName(%q), version=%d, isUp=%v, wanted version=%d, isUp=%v",
+					test.fileName, version, isUp, test.version, test.isUp,
+				)
+			}
+		})
+	}
+}
+
 func TestGetMigrations(t *testing.T) {
-	if _, err := getMigrations(); err != nil {
-		t.Error(err)
+	migrations, err := getMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, migration := range migrations {
+		if i > 0 && migration.version <= migrations[i-1].version {
+			t.Errorf("getMigrations(), %q is not sorted by version", migration.name)
+		}
+		if migration.up == "" || migration.down == "" {
+			t.Errorf("getMigrations(), %q has an empty up or down file", migration.name)
+		}
+		if len(migration.checksum) != 64 {
+			t.Errorf("getMigrations(), %q has checksum %q", migration.name, migration.checksum)
+		}
 	}
 }
@@ -0,0 +1 @@
+DELETE FROM habit_statuses WHERE id IN (0, 1);
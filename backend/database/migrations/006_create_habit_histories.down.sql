@@ -0,0 +1 @@
+DROP TABLE IF EXISTS habit_histories;
@@ -0,0 +1,2 @@
+ALTER TABLE habit_histories
+DROP CONSTRAINT IF EXISTS habit_id_date_unique;
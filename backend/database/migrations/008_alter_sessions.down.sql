@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS sessions_user_id_index;
+DROP INDEX IF EXISTS sessions_public_id_unique;
+
+ALTER TABLE sessions
+DROP COLUMN IF EXISTS last_seen_date,
+DROP COLUMN IF EXISTS ip_address,
+DROP COLUMN IF EXISTS user_agent,
+DROP COLUMN IF EXISTS public_id;
@@ -0,0 +1,14 @@
+ALTER TABLE sessions
+ADD COLUMN IF NOT EXISTS public_id UUID NOT NULL DEFAULT gen_random_uuid(),
+ADD COLUMN IF NOT EXISTS user_agent VARCHAR(256) NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS ip_address VARCHAR(45) NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS last_seen_date DATE NOT NULL DEFAULT CURRENT_DATE;
+
+ALTER TABLE sessions
+ALTER COLUMN public_id DROP DEFAULT,
+ALTER COLUMN user_agent DROP DEFAULT,
+ALTER COLUMN ip_address DROP DEFAULT,
+ALTER COLUMN last_seen_date DROP DEFAULT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS sessions_public_id_unique ON sessions(public_id);
+CREATE INDEX IF NOT EXISTS sessions_user_id_index ON sessions(user_id);
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS sessions_expiration_time_index;
+
+ALTER TABLE sessions
+ALTER COLUMN creation_time TYPE DATE
+    USING (creation_time AT TIME ZONE 'UTC')::DATE,
+ALTER COLUMN expiration_time TYPE DATE
+    USING (expiration_time AT TIME ZONE 'UTC')::DATE,
+ALTER COLUMN last_seen_time TYPE DATE
+    USING (last_seen_time AT TIME ZONE 'UTC')::DATE;
+
+ALTER TABLE sessions RENAME COLUMN creation_time TO creation_date;
+ALTER TABLE sessions RENAME COLUMN expiration_time TO expiration_date;
+ALTER TABLE sessions RENAME COLUMN last_seen_time TO last_seen_date;
@@ -0,0 +1,13 @@
+ALTER TABLE sessions RENAME COLUMN creation_date TO creation_time;
+ALTER TABLE sessions RENAME COLUMN expiration_date TO expiration_time;
+ALTER TABLE sessions RENAME COLUMN last_seen_date TO last_seen_time;
+
+ALTER TABLE sessions
+ALTER COLUMN creation_time TYPE TIMESTAMPTZ
+    USING creation_time::TIMESTAMP AT TIME ZONE 'UTC',
+ALTER COLUMN expiration_time TYPE TIMESTAMPTZ
+    USING expiration_time::TIMESTAMP AT TIME ZONE 'UTC',
+ALTER COLUMN last_seen_time TYPE TIMESTAMPTZ
+    USING last_seen_time::TIMESTAMP AT TIME ZONE 'UTC';
+
+CREATE INDEX IF NOT EXISTS sessions_expiration_time_index ON sessions(expiration_time);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS users_verified_email_unique;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS email_verified,
+DROP COLUMN IF EXISTS email;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS user_tokens(
+    id BYTEA NOT NULL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose SMALLINT NOT NULL,
+    email VARCHAR(254) NOT NULL,
+    creation_time TIMESTAMPTZ NOT NULL,
+    expiration_time TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS user_tokens_user_id_index ON user_tokens(user_id);
+CREATE INDEX IF NOT EXISTS user_tokens_expiration_time_index ON user_tokens(expiration_time);
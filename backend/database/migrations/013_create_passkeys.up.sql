@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS passkeys(
+    id UUID NOT NULL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(64) NOT NULL,
+    credential_id BYTEA NOT NULL UNIQUE,
+    public_key BYTEA NOT NULL,
+    sign_count BIGINT NOT NULL,
+    creation_time TIMESTAMPTZ NOT NULL,
+    last_used_time TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS passkeys_user_id_index ON passkeys(user_id);
+
+CREATE TABLE IF NOT EXISTS webauthn_challenges(
+    id BYTEA NOT NULL PRIMARY KEY,
+    ceremony SMALLINT NOT NULL,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    expiration_time TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS webauthn_challenges_expiration_time_index ON webauthn_challenges(expiration_time);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS users_status_index;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS status,
+DROP COLUMN IF EXISTS role;
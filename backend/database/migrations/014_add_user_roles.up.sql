@@ -0,0 +1,5 @@
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS role SMALLINT NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS status SMALLINT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS users_status_index ON users(status);
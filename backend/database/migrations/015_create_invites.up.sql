@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS invites(
+    id UUID NOT NULL PRIMARY KEY,
+    hashed_code BYTEA NOT NULL UNIQUE,
+    creator_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    max_uses INTEGER NOT NULL,
+    uses INTEGER NOT NULL DEFAULT 0,
+    creation_time TIMESTAMPTZ NOT NULL,
+    expiration_time TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS invites_expiration_time_index ON invites(expiration_time);
@@ -0,0 +1,13 @@
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS last_login_time TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS admin_audit_log(
+    id UUID NOT NULL PRIMARY KEY,
+    admin_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    action SMALLINT NOT NULL,
+    target_id UUID NOT NULL,
+    details VARCHAR(256) NOT NULL,
+    creation_time TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS admin_audit_log_creation_time_index ON admin_audit_log(creation_time);
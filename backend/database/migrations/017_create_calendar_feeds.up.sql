@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS calendar_feeds(
+    id UUID NOT NULL PRIMARY KEY,
+    hashed_token BYTEA NOT NULL UNIQUE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    completions BOOLEAN NOT NULL,
+    creation_time TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS calendar_feeds_user_id_index ON calendar_feeds(user_id);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS migrations(
+    id SMALLINT NOT NULL PRIMARY KEY,
+    version SMALLINT NOT NULL
+);
+
+INSERT INTO migrations (id, version)
+VALUES (0, 17)
+ON CONFLICT (id)
+DO UPDATE SET version = 17;
@@ -0,0 +1,2 @@
+-- Applied migrations are recorded in migration_history instead
+DROP TABLE IF EXISTS migrations;
@@ -0,0 +1,509 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// migrationLockKey is the key of the PostgreSQL advisory lock
+// held while migrating, so replicas starting at once migrate one by one.
+const migrationLockKey int64 = 0x6b657261
+
+var (
+	ErrMigrationEdited         = errors.New("applied migration was edited since")
+	ErrUnknownMigration        = errors.New("applied migration is unknown to this version of kera")
+	ErrInvalidMigrationVersion = errors.New("migration version does not exist")
+	ErrInvalidMigrationCount   = errors.New("number of migrations must be positive")
+)
+
+// MigrationDirection represents whether a migration is applied or reverted.
+type MigrationDirection string
+
+const (
+	MigrationUp   MigrationDirection = "up"
+	MigrationDown MigrationDirection = "down"
+)
+
+// MigrationStep represents a migration applied or reverted by the Migrator.
+type MigrationStep struct {
+	Version   uint16
+	Name      string
+	Direction MigrationDirection
+}
+
+// MigrationStatus represents the state of a migration in the database.
+type MigrationStatus struct {
+	Version     uint16
+	Name        string
+	Applied     bool
+	AppliedTime time.Time
+	// Edited is true if the migration file changed since it was applied.
+	Edited bool
+	// Unknown is true if the migration was applied by another version
+	// of kera and has no file.
+	Unknown bool
+}
+
+// appliedMigration represents a record of the migration history.
+type appliedMigration struct {
+	version     uint16
+	name        string
+	checksum    string
+	appliedTime time.Time
+}
+
+// migrationHistory represents the migrations applied to the database.
+type migrationHistory struct {
+	applied map[uint16]appliedMigration
+	// exists is false until the history table is created,
+	// applied is then read from the version of the legacy migrations table.
+	exists bool
+}
+
+// Migrator applies and reverts the embedded migrations,
+// each one in its own transaction, and records them in the
+// migration_history table along with the checksum of their file.
+// Migrations run while holding an advisory lock, and are refused if
+// an applied migration was edited or is unknown.
+// Every applied version is tracked, so versions may have gaps,
+// and a pending migration older than applied ones, e.g. merged from
+// another branch, is still applied.
+type Migrator struct {
+	db         *sql.DB
+	migrations []migration
+}
+
+// NewMigrator returns a new *Migrator of the embedded migrations.
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+	if db == nil {
+		return nil, errors.New("function called with nil *sql.DB")
+	}
+
+	migrations, err := getMigrations()
+	if err != nil {
+		return nil, err
+	}
+	if len(migrations) == 0 {
+		return nil, errors.New("no migrations found")
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// LatestVersion returns the version of the latest embedded migration.
+func (m *Migrator) LatestVersion() uint16 {
+	return m.migrations[len(m.migrations)-1].version
+}
+
+// Status returns the status of every embedded migration, and of unknown
+// applied ones, sorted by version.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	history, err := readMigrationHistory(ctx, m.db, m.migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Version: migration.version, Name: migration.name}
+		if applied, ok := history.applied[migration.version]; ok {
+			status.Applied = true
+			status.AppliedTime = applied.appliedTime
+			status.Edited = applied.checksum != migration.checksum
+		}
+		statuses = append(statuses, status)
+	}
+
+	for _, applied := range history.applied {
+		if m.find(applied.version) != nil {
+			continue
+		}
+		statuses = append(statuses, MigrationStatus{
+			Version:     applied.version,
+			Name:        applied.name,
+			Applied:     true,
+			AppliedTime: applied.appliedTime,
+			Unknown:     true,
+		})
+	}
+
+	slices.SortFunc(statuses, func(a, b MigrationStatus) int {
+		return int(a.Version) - int(b.Version)
+	})
+	return statuses, nil
+}
+
+// Up applies every pending migration.
+// It returns the steps taken, or planned if it's a dry run.
+func (m *Migrator) Up(ctx context.Context, dryRun bool) ([]MigrationStep, error) {
+	return m.run(ctx, dryRun, func(applied map[uint16]appliedMigration) []MigrationStep {
+		return m.planTo(applied, m.LatestVersion())
+	})
+}
+
+// Down reverts the last n applied migrations.
+// It returns the steps taken, or planned if it's a dry run.
+func (m *Migrator) Down(ctx context.Context, n uint, dryRun bool) ([]MigrationStep, error) {
+	if n == 0 {
+		return nil, ErrInvalidMigrationCount
+	}
+
+	return m.run(ctx, dryRun, func(applied map[uint16]appliedMigration) []MigrationStep {
+		return m.planDown(applied, n)
+	})
+}
+
+// To applies or reverts migrations until the migration of the version
+// is the latest applied one.
+// It returns the steps taken, or planned if it's a dry run.
+func (m *Migrator) To(ctx context.Context, version uint16, dryRun bool) ([]MigrationStep, error) {
+	if m.find(version) == nil {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidMigrationVersion, version)
+	}
+
+	return m.run(ctx, dryRun, func(applied map[uint16]appliedMigration) []MigrationStep {
+		return m.planTo(applied, version)
+	})
+}
+
+// run takes the migration lock, verifies the history,
+// then takes the steps of the plan, unless it's a dry run.
+// If a step fails, the steps taken until then are returned with the error.
+func (m *Migrator) run(
+	ctx context.Context, dryRun bool,
+	plan func(applied map[uint16]appliedMigration) []MigrationStep,
+) ([]MigrationStep, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database connection: %w", err)
+	}
+	defer conn.Close()
+
+	_, err = conn.ExecContext(ctx, "SELECT pg_advisory_lock($1);", migrationLockKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to take migration lock: %w", err)
+	}
+	defer conn.ExecContext(
+		context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1);", migrationLockKey,
+	)
+
+	history, err := readMigrationHistory(ctx, conn, m.migrations)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.verify(history.applied); err != nil {
+		return nil, err
+	}
+
+	steps := plan(history.applied)
+	if dryRun || len(steps) == 0 {
+		return steps, nil
+	}
+
+	if !history.exists {
+		err = createMigrationHistory(ctx, conn, history.applied)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i, step := range steps {
+		if err := m.take(ctx, conn, step); err != nil {
+			return steps[:i], err
+		}
+	}
+	return steps, nil
+}
+
+// verify returns an error if an applied migration was edited
+// or is unknown.
+func (m *Migrator) verify(applied map[uint16]appliedMigration) error {
+	var errs []error
+	for _, version := range slices.Sorted(maps.Keys(applied)) {
+		applied := applied[version]
+		migration := m.find(version)
+		switch {
+		case migration == nil:
+			errs = append(errs, fmt.Errorf("%w: %s", ErrUnknownMigration, applied.name))
+		case migration.checksum != applied.checksum:
+			errs = append(errs, fmt.Errorf("%w: %s", ErrMigrationEdited, applied.name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// planTo returns the steps reverting the applied migrations
+// newer than the version, latest first, then applying the pending ones
+// up to the version, oldest first.
+func (m *Migrator) planTo(applied map[uint16]appliedMigration, version uint16) []MigrationStep {
+	var steps []MigrationStep
+	for _, migration := range slices.Backward(m.migrations) {
+		if _, ok := applied[migration.version]; ok && migration.version > version {
+			steps = append(steps, migration.step(MigrationDown))
+		}
+	}
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.version]; !ok && migration.version <= version {
+			steps = append(steps, migration.step(MigrationUp))
+		}
+	}
+	return steps
+}
+
+// planDown returns the steps reverting the last n applied migrations,
+// latest first.
+func (m *Migrator) planDown(applied map[uint16]appliedMigration, n uint) []MigrationStep {
+	var steps []MigrationStep
+	for _, migration := range slices.Backward(m.migrations) {
+		if uint(len(steps)) == n {
+			break
+		}
+		if _, ok := applied[migration.version]; ok {
+			steps = append(steps, migration.step(MigrationDown))
+		}
+	}
+	return steps
+}
+
+// take applies or reverts the migration of the step in a transaction,
+// along with its record in the history.
+func (m *Migrator) take(ctx context.Context, conn *sql.Conn, step MigrationStep) error {
+	migration := m.find(step.Version)
+	fileName := migration.name + upMigrationSuffix
+	if step.Direction == MigrationDown {
+		fileName = migration.name + downMigrationSuffix
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin %s transaction: %w", fileName, err)
+	}
+	defer tx.Rollback()
+
+	if step.Direction == MigrationUp {
+		_, err = tx.ExecContext(ctx, migration.up)
+		if err != nil {
+			return newMigrationError(fileName, migration.up, err)
+		}
+
+		query := `
+			INSERT INTO migration_history (version, name, checksum, applied_time)
+			VALUES ($1, $2, $3, $4);
+		`
+		_, err = tx.ExecContext(
+			ctx, query, migration.version, migration.name,
+			migration.checksum, time.Now().UTC(),
+		)
+	} else {
+		_, err = tx.ExecContext(ctx, migration.down)
+		if err != nil {
+			return newMigrationError(fileName, migration.down, err)
+		}
+
+		query := `
+			DELETE FROM migration_history
+			WHERE version = $1;
+		`
+		_, err = tx.ExecContext(ctx, query, migration.version)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record %s: %w", fileName, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit %s: %w", fileName, err)
+	}
+	return nil
+}
+
+// MigrationError represents the failure of a migration file.
+type MigrationError struct {
+	FileName string
+	// Line is the line of the failing statement, or 0 if it's unknown.
+	Line int
+	Err  error
+}
+
+// newMigrationError returns the *MigrationError of the file,
+// located at the line of the error position reported by PostgreSQL.
+func newMigrationError(fileName, query string, err error) *MigrationError {
+	migrationErr := &MigrationError{FileName: fileName, Err: err}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Position <= 0 {
+		return migrationErr
+	}
+
+	// The position is the 1-based index of a character, not a byte
+	migrationErr.Line = 1
+	position := 1
+	for _, c := range query {
+		if position == int(pgErr.Position) {
+			break
+		}
+		if c == '\n' {
+			migrationErr.Line++
+		}
+		position++
+	}
+	return migrationErr
+}
+
+func (e *MigrationError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("failed to apply %s: %v", e.FileName, e.Err)
+	}
+	return fmt.Sprintf("failed to apply %s at line %d: %v", e.FileName, e.Line, e.Err)
+}
+
+func (e *MigrationError) Unwrap() error {
+	return e.Err
+}
+
+// find returns the embedded migration of the version, or nil.
+func (m *Migrator) find(version uint16) *migration {
+	i, found := slices.BinarySearchFunc(m.migrations, version, func(m migration, v uint16) int {
+		return int(m.version) - int(v)
+	})
+	if !found {
+		return nil
+	}
+	return &m.migrations[i]
+}
+
+func (m migration) step(direction MigrationDirection) MigrationStep {
+	return MigrationStep{Version: m.version, Name: m.name, Direction: direction}
+}
+
+// querier represents a *sql.DB or *sql.Conn.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// readMigrationHistory returns the applied migrations.
+// Without a history table, the migrations up to the version of the legacy
+// migrations table are considered applied, with their current checksum.
+func readMigrationHistory(
+	ctx context.Context, q querier, migrations []migration,
+) (*migrationHistory, error) {
+	history := &migrationHistory{applied: make(map[uint16]appliedMigration)}
+
+	query := `
+		SELECT to_regclass('migration_history') IS NOT NULL,
+		       to_regclass('migrations') IS NOT NULL;
+	`
+	var legacy bool
+	err := q.QueryRowContext(ctx, query).Scan(&history.exists, &legacy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration history: %w", err)
+	}
+
+	if !history.exists {
+		if !legacy {
+			return history, nil
+		}
+
+		var legacyVersion uint16
+		query = `
+			SELECT version FROM migrations;
+		`
+		err = q.QueryRowContext(ctx, query).Scan(&legacyVersion)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("failed to get migrations version: %w", err)
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			return history, nil
+		}
+
+		for _, migration := range migrations {
+			if migration.version <= legacyVersion {
+				history.applied[migration.version] = appliedMigration{
+					version:  migration.version,
+					name:     migration.name,
+					checksum: migration.checksum,
+				}
+			}
+		}
+		return history, nil
+	}
+
+	query = `
+		SELECT version, name, checksum, applied_time
+		FROM migration_history;
+	`
+	rows, err := q.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration history: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var applied appliedMigration
+		err := rows.Scan(
+			&applied.version, &applied.name, &applied.checksum, &applied.appliedTime,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration history: %w", err)
+		}
+		history.applied[applied.version] = applied
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read migration history: %w", err)
+	}
+
+	return history, nil
+}
+
+// createMigrationHistory creates the history table,
+// recording the migrations applied before it existed.
+func createMigrationHistory(
+	ctx context.Context, conn *sql.Conn, applied map[uint16]appliedMigration,
+) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration history transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		CREATE TABLE IF NOT EXISTS migration_history(
+			version SMALLINT NOT NULL PRIMARY KEY,
+			name TEXT NOT NULL,
+			checksum TEXT NOT NULL,
+			applied_time TIMESTAMPTZ NOT NULL
+		);
+	`
+	_, err = tx.ExecContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to create migration history: %w", err)
+	}
+
+	now := time.Now().UTC()
+	query = `
+		INSERT INTO migration_history (version, name, checksum, applied_time)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (version) DO NOTHING;
+	`
+	for _, applied := range applied {
+		_, err = tx.ExecContext(
+			ctx, query, applied.version, applied.name, applied.checksum, now,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to record %s: %w", applied.name, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit migration history: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,386 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// newTestMigrator returns a *Migrator of migrations with the versions,
+// without a database.
+func newTestMigrator(versions ...uint16) *Migrator {
+	migrations := make([]migration, 0, len(versions))
+	for _, version := range versions {
+		name := fmt.Sprintf("%03d_test", version)
+		migrations = append(migrations, migration{
+			version: version, name: name, checksum: name,
+		})
+	}
+	return &Migrator{migrations: migrations}
+}
+
+// newApplied returns the applied migrations of the versions
+// as recorded by newTestMigrator.
+func newApplied(versions ...uint16) map[uint16]appliedMigration {
+	applied := make(map[uint16]appliedMigration, len(versions))
+	for _, version := range versions {
+		name := fmt.Sprintf("%03d_test", version)
+		applied[version] = appliedMigration{
+			version: version, name: name, checksum: name,
+		}
+	}
+	return applied
+}
+
+// stepNames returns the steps as "name direction" strings.
+func stepNames(steps []MigrationStep) []string {
+	names := make([]string, 0, len(steps))
+	for _, step := range steps {
+		names = append(names, step.Name+" "+string(step.Direction))
+	}
+	return names
+}
+
+func TestPlanTo(t *testing.T) {
+	migrator := newTestMigrator(0, 1, 5, 9, 10)
+
+	tests := []struct {
+		name    string
+		applied map[uint16]appliedMigration
+		version uint16
+		steps   []string
+	}{
+		{
+			"Up: empty database",
+			newApplied(),
+			10,
+			[]string{"000_test up", "001_test up", "005_test up", "009_test up", "010_test up"},
+		},
+		{
+			"Up: with gaps",
+			newApplied(0, 1, 5),
+			10,
+			[]string{"009_test up", "010_test up"},
+		},
+		{
+			"Up: older pending migration",
+			newApplied(0, 1, 9, 10),
+			10,
+			[]string{"005_test up"},
+		},
+		{
+			"Up: nothing pending",
+			newApplied(0, 1, 5, 9, 10),
+			10,
+			nil,
+		},
+		{
+			"Down: to a version",
+			newApplied(0, 1, 5, 9, 10),
+			1,
+			[]string{"010_test down", "009_test down", "005_test down"},
+		},
+		{
+			"Down and up: older pending migration",
+			newApplied(0, 9, 10),
+			5,
+			[]string{"010_test down", "009_test down", "001_test up", "005_test up"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			steps := stepNames(migrator.planTo(test.applied, test.version))
+			if !slices.Equal(steps, test.steps) {
+				t.Errorf("planTo(%d), steps=%q, wanted=%q", test.version, steps, test.steps)
+			}
+		})
+	}
+}
+
+func TestPlanDown(t *testing.T) {
+	migrator := newTestMigrator(0, 1, 5, 9, 10)
+
+	tests := []struct {
+		name    string
+		applied map[uint16]appliedMigration
+		n       uint
+		steps   []string
+	}{
+		{"One", newApplied(0, 1, 5, 9, 10), 1, []string{"010_test down"}},
+		{"With gaps", newApplied(0, 1, 5, 10), 2, []string{"010_test down", "005_test down"}},
+		{"More than applied", newApplied(0, 1), 5, []string{"001_test down", "000_test down"}},
+		{"Empty database", newApplied(), 1, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			steps := stepNames(migrator.planDown(test.applied, test.n))
+			if !slices.Equal(steps, test.steps) {
+				t.Errorf("planDown(%d), steps=%q, wanted=%q", test.n, steps, test.steps)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	migrator := newTestMigrator(0, 1, 5)
+
+	edited := newApplied(0, 1)
+	edited[1] = appliedMigration{version: 1, name: "001_test", checksum: "edited"}
+
+	tests := []struct {
+		name    string
+		applied map[uint16]appliedMigration
+		wanted  error
+	}{
+		{"Valid", newApplied(0, 1), nil},
+		{"Valid: empty database", newApplied(), nil},
+		{"Invalid: edited", edited, ErrMigrationEdited},
+		{"Invalid: unknown", newApplied(0, 1, 7), ErrUnknownMigration},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := migrator.verify(test.applied)
+			if test.wanted == nil && err != nil || !errors.Is(err, test.wanted) {
+				t.Errorf("verify(), error=%v, wanted=%v", err, test.wanted)
+			}
+		})
+	}
+}
+
+func TestNewMigrationError(t *testing.T) {
+	query := "CREATE TABLE a(id INT);\n\nCRATE TABLE b(id INT);\n"
+
+	tests := []struct {
+		name string
+		err  error
+		line int
+	}{
+		{"Position", &pgconn.PgError{Position: 26}, 3},
+		{"Position: first line", &pgconn.PgError{Position: 1}, 1},
+		{"Position: blank line", &pgconn.PgError{Position: 25}, 2},
+		{"Wrapped", fmt.Errorf("exec: %w", &pgconn.PgError{Position: 26}), 3},
+		{"Without position", &pgconn.PgError{}, 0},
+		{"Not a PostgreSQL error", errors.New("conn closed"), 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := newMigrationError("002_test.up.sql", query, test.err)
+			if err.Line != test.line {
+				t.Errorf("newMigrationError(%v), line=%d, wanted=%d", test.err, err.Line, test.line)
+			}
+			if !errors.Is(err, test.err) {
+				t.Errorf("newMigrationError(%v), error=%v does not wrap it", test.err, err)
+			}
+		})
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	dataSourceName := os.Getenv("DSN")
+	if dataSourceName == "" {
+		t.Skip("skipping: DSN is not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	sqlDatabase, err := NewSqlDatabase(ctx, PostgresDriverName, dataSourceName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDatabase.DB.Close()
+
+	if err := sqlDatabase.Teardown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDatabase.Teardown(ctx)
+
+	migrator, err := NewMigrator(sqlDatabase.DB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest := migrator.LatestVersion()
+
+	// pending returns the number of pending migrations
+	pending := func(t *testing.T) int {
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := 0
+		for _, status := range statuses {
+			if !status.Applied {
+				n++
+			}
+		}
+		return n
+	}
+
+	t.Run("Up: dry run", func(t *testing.T) {
+		steps, err := migrator.Up(ctx, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(steps) != len(migrator.migrations) {
+			t.Errorf("Up(dryRun), steps=%d, wanted=%d", len(steps), len(migrator.migrations))
+		}
+		if n := pending(t); n != len(migrator.migrations) {
+			t.Errorf("Up(dryRun), pending=%d, wanted=%d", n, len(migrator.migrations))
+		}
+	})
+
+	t.Run("Up: concurrent", func(t *testing.T) {
+		var wg sync.WaitGroup
+		errs := make([]error, 3)
+		for i := range errs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, errs[i] = migrator.Up(ctx, false)
+			}()
+		}
+		wg.Wait()
+
+		if err := errors.Join(errs...); err != nil {
+			t.Fatal(err)
+		}
+		if err := sqlDatabase.CheckMigrations(ctx); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Down", func(t *testing.T) {
+		steps, err := migrator.Down(ctx, 2, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(steps) != 2 || pending(t) != 2 {
+			t.Errorf("Down(2), steps=%d, pending=%d, wanted 2", len(steps), pending(t))
+		}
+	})
+
+	t.Run("To", func(t *testing.T) {
+		if _, err := migrator.To(ctx, latest, false); err != nil {
+			t.Fatal(err)
+		}
+		if n := pending(t); n != 0 {
+			t.Errorf("To(%d), pending=%d, wanted 0", latest, n)
+		}
+	})
+
+	t.Run("Up: legacy migrations table", func(t *testing.T) {
+		// Reverting 018_drop_migrations restores the legacy table at version 17
+		const legacyVersion = 17
+		if _, err := migrator.To(ctx, legacyVersion, false); err != nil {
+			t.Fatal(err)
+		}
+		_, err := sqlDatabase.DB.ExecContext(ctx, "DROP TABLE migration_history;")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		steps, err := migrator.Up(ctx, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, step := range steps {
+			if step.Version <= legacyVersion {
+				t.Errorf("Up(), applied %s again", step.Name)
+			}
+		}
+		if n := pending(t); n != 0 {
+			t.Errorf("Up(), pending=%d, wanted 0", n)
+		}
+	})
+
+	t.Run("Up: edited migration", func(t *testing.T) {
+		query := "UPDATE migration_history SET checksum = 'edited' WHERE version = 1;"
+		if _, err := sqlDatabase.DB.ExecContext(ctx, query); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := migrator.Up(ctx, false)
+		if !errors.Is(err, ErrMigrationEdited) {
+			t.Errorf("Up(), error=%v, wanted=%v", err, ErrMigrationEdited)
+		}
+		if err := sqlDatabase.CheckMigrations(ctx); !errors.Is(err, ErrMigrationVersionMismatch) {
+			t.Errorf("CheckMigrations(), error=%v, wanted=%v", err, ErrMigrationVersionMismatch)
+		}
+
+		query = "UPDATE migration_history SET checksum = $1 WHERE version = 1;"
+		_, err = sqlDatabase.DB.ExecContext(ctx, query, migrator.find(1).checksum)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("Up: failing migration", func(t *testing.T) {
+		failing := &Migrator{
+			db: sqlDatabase.DB,
+			migrations: append(slices.Clone(migrator.migrations),
+				migration{
+					version: latest + 1, name: "998_test", checksum: "998",
+					up: "CREATE TABLE test(id INT);", down: "DROP TABLE test;",
+				},
+				migration{
+					version: latest + 2, name: "999_test", checksum: "999",
+					up: "SELECT 1;\nSELEC 2;", down: "",
+				},
+			),
+		}
+
+		steps, err := failing.Up(ctx, false)
+		var migrationErr *MigrationError
+		if !errors.As(err, &migrationErr) {
+			t.Fatalf("Up(), error=%v, wanted a *MigrationError", err)
+		}
+		if migrationErr.FileName != "999_test.up.sql" || migrationErr.Line != 2 {
+			t.Errorf(
+				"Up(), file=%q, line=%d, wanted 999_test.up.sql at line 2",
+				migrationErr.FileName, migrationErr.Line,
+			)
+		}
+		// The migrations before the failing one stay applied
+		if len(steps) != 1 || steps[0].Name != "998_test" {
+			t.Errorf("Up(), steps=%q, wanted only 998_test", stepNames(steps))
+		}
+
+		if _, err := failing.Down(ctx, 1, false); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Setup", func(t *testing.T) {
+		if err := sqlDatabase.Teardown(ctx); err != nil {
+			t.Fatal(err)
+		}
+		for range 2 {
+			if err := sqlDatabase.Setup(ctx); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := sqlDatabase.CheckMigrations(ctx); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Down: everything", func(t *testing.T) {
+		if _, err := migrator.Down(ctx, uint(len(migrator.migrations)), false); err != nil {
+			t.Fatal(err)
+		}
+		if n := pending(t); n != len(migrator.migrations) {
+			t.Errorf("Down(all), pending=%d, wanted=%d", n, len(migrator.migrations))
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/zvxte/kera/export"
+	"github.com/zvxte/kera/store/habitstore"
+	"github.com/zvxte/kera/store/sessionstore"
+	"github.com/zvxte/kera/store/userstore"
+)
+
+// runExport writes a kera archive of an account,
+// the same way as the export endpoint does.
+// The database is read from database.dsn of the config.
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	username := flags.String("username", "", "username of the account to export")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: kera export -username USERNAME [flags] [FILE]")
+		flags.PrintDefaults()
+	}
+	cfg, err := loadConfig(flags, args)
+	if err != nil {
+		return err
+	}
+	if *username == "" || flags.NArg() > 1 {
+		flags.Usage()
+		return errors.New("export: username is required")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	sqlDatabase, err := openDatabase(ctx, cfg, "export")
+	if err != nil {
+		return err
+	}
+	defer sqlDatabase.DB.Close()
+
+	userStore, err := userstore.NewSql(sqlDatabase.DB)
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	habitStore, err := habitstore.NewSql(sqlDatabase.DB)
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	sessionStore, err := sessionstore.NewSql(sqlDatabase.DB)
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+
+	user, err := getUser(ctx, userStore, *username)
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+
+	takeout, err := export.New(userStore, habitStore, sessionStore).Collect(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+
+	// Named like the archive of the export endpoint by default
+	fileName := flags.Arg(0)
+	if fileName == "" {
+		fileName = "kera-export-" + takeout.CreationTime.Format(time.DateOnly) + ".zip"
+	}
+	if err := writeExport(fileName, takeout); err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+
+	fmt.Printf("exported %q to %s\n", user.Username, fileName)
+	return nil
+}
+
+// writeExport writes the archive to a new file,
+// which is removed if writing fails.
+func writeExport(fileName string, takeout *export.Export) error {
+	file, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+
+	err = takeout.WriteZip(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(fileName)
+		return err
+	}
+	return nil
+}
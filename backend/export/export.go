@@ -0,0 +1,220 @@
+// Package export builds and reads kera archives of a user's data.
+//
+// A kera archive is a ZIP file with export.json holding the whole [Export],
+// and CSV files with the same data for spreadsheet applications.
+// Only export.json is read back, the CSV files are informational.
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zvxte/kera/model/date"
+	"github.com/zvxte/kera/model/habit"
+	"github.com/zvxte/kera/model/uuid"
+	"github.com/zvxte/kera/store/habitstore"
+	"github.com/zvxte/kera/store/sessionstore"
+	"github.com/zvxte/kera/store/userstore"
+)
+
+// Version is the version of the archive format,
+// it changes whenever a field is removed or changes its meaning.
+// Archives of this or an older version can be read.
+const Version = 1
+
+var (
+	ErrUserNotFound       = errors.New("export: user not found")
+	ErrInvalidArchive     = errors.New("archive is invalid")
+	ErrUnsupportedVersion = errors.New("archive version is not supported")
+)
+
+// Export represents a snapshot of all data of a user.
+// Secrets, such as the hashed password or session IDs, are never included.
+// All time fields are in UTC.
+type Export struct {
+	Version      int       `json:"version"`
+	CreationTime time.Time `json:"creation_time"`
+	Profile      Profile   `json:"profile"`
+	Habits       []Habit   `json:"habits"`
+	Sessions     []Session `json:"sessions"`
+}
+
+type Profile struct {
+	ID            string    `json:"id"`
+	Username      string    `json:"username"`
+	DisplayName   string    `json:"display_name"`
+	Email         string    `json:"email"`
+	EmailVerified bool      `json:"email_verified"`
+	Role          string    `json:"role"`
+	CreationDate  time.Time `json:"creation_date"`
+}
+
+// Habit represents a habit with its decoded history.
+// The description holds the user's notes about the habit.
+type Habit struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Status      string     `json:"status"`
+	WeekDays    []string   `json:"week_days"`
+	StartDate   time.Time  `json:"start_date"`
+	EndDate     *time.Time `json:"end_date"`
+	History     []Day      `json:"history"`
+}
+
+// Day represents a single day of a habit's history.
+type Day struct {
+	Date   time.Time `json:"date"`
+	Status string    `json:"status"`
+}
+
+// Session represents metadata of a session.
+type Session struct {
+	ID             string    `json:"id"`
+	UserAgent      string    `json:"user_agent"`
+	IPAddress      string    `json:"ip_address"`
+	CreationTime   time.Time `json:"creation_time"`
+	LastSeenTime   time.Time `json:"last_seen_time"`
+	ExpirationTime time.Time `json:"expiration_time"`
+}
+
+// Exporter collects exports from the stores,
+// so it works with any store implementation.
+type Exporter struct {
+	userStore    userstore.Store
+	habitStore   habitstore.Store
+	sessionStore sessionstore.Store
+}
+
+// New returns a new *Exporter.
+func New(
+	userStore userstore.Store,
+	habitStore habitstore.Store,
+	sessionStore sessionstore.Store,
+) *Exporter {
+	return &Exporter{
+		userStore:    userStore,
+		habitStore:   habitStore,
+		sessionStore: sessionStore,
+	}
+}
+
+// Collect returns the export of the user.
+// The history of every habit is read month by month,
+// from its start date up to its end date or today.
+// It fails with [ErrUserNotFound] if the user does not exist,
+// or if there is a connection issue.
+func (e *Exporter) Collect(ctx context.Context, userID uuid.UUID) (*Export, error) {
+	user, err := e.userStore.Get(ctx, userstore.IDColumn, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect export: %w", err)
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	export := &Export{
+		Version:      Version,
+		CreationTime: time.Now().UTC(),
+		Profile: Profile{
+			ID:            user.ID.String(),
+			Username:      user.Username,
+			DisplayName:   user.DisplayName,
+			Email:         user.Email,
+			EmailVerified: user.EmailVerified,
+			Role:          user.Role.String(),
+			CreationDate:  time.Time(user.CreationDate),
+		},
+	}
+
+	habits, err := e.habitStore.GetAll(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect export: %w", err)
+	}
+
+	export.Habits = make([]Habit, len(habits))
+	for i, h := range habits {
+		history, err := e.history(ctx, h, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to collect export: %w", err)
+		}
+		export.Habits[i] = newHabit(h, history)
+	}
+
+	sessions, err := e.sessionStore.GetAll(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect export: %w", err)
+	}
+
+	export.Sessions = make([]Session, len(sessions))
+	for i, s := range sessions {
+		export.Sessions[i] = Session{
+			ID:             s.PublicID.String(),
+			UserAgent:      s.UserAgent,
+			IPAddress:      s.IPAddress,
+			CreationTime:   s.CreationTime,
+			LastSeenTime:   s.LastSeenTime,
+			ExpirationTime: s.ExpirationTime,
+		}
+	}
+
+	return export, nil
+}
+
+// history returns the tracked days of the habit's history.
+func (e *Exporter) history(
+	ctx context.Context, h *habit.Habit, userID uuid.UUID,
+) ([]Day, error) {
+	last := date.Now()
+	if !h.EndDate.IsZero() && h.EndDate.Before(last) {
+		last = h.EndDate
+	}
+
+	days := []Day{}
+	for month := h.StartDate.FirstOfMonth(); !month.After(last); {
+		history, err := e.habitStore.GetMonthHistory(ctx, h.ID, month, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, day := range history {
+			if day.Status == habit.DayUntracked {
+				continue
+			}
+			days = append(days, Day{
+				Date:   time.Time(day.Date),
+				Status: day.Status.String(),
+			})
+		}
+
+		month = date.Date(time.Time(month).AddDate(0, 1, 0))
+	}
+
+	return days, nil
+}
+
+func newHabit(h *habit.Habit, history []Day) Habit {
+	weekDays := h.TrackedWeekDays.WeekDays()
+	names := make([]string, len(weekDays))
+	for i, day := range weekDays {
+		names[i] = day.String()
+	}
+
+	out := Habit{
+		ID:          h.ID.String(),
+		Title:       h.Title,
+		Description: h.Description,
+		Status:      h.Status.String(),
+		WeekDays:    names,
+		StartDate:   time.Time(h.StartDate),
+		History:     history,
+	}
+	if !h.EndDate.IsZero() {
+		endDate := time.Time(h.EndDate)
+		out.EndDate = &endDate
+	}
+
+	return out
+}
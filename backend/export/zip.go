@@ -0,0 +1,198 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	dateLayout = time.DateOnly
+
+	jsonFile = "export.json"
+
+	// jsonMaxBytes limits the decompressed size of export.json.
+	jsonMaxBytes = 64 << 20
+)
+
+// WriteZip writes the export to w as a ZIP archive containing
+// export.json with all the data, and a CSV file per table:
+// profile.csv, habits.csv, history.csv and sessions.csv.
+// It fails if w fails.
+func (e *Export) WriteZip(w io.Writer) error {
+	zw := zip.NewWriter(w)
+
+	files := []struct {
+		name  string
+		write func(io.Writer) error
+	}{
+		{jsonFile, e.writeJson},
+		{"profile.csv", e.writeProfileCsv},
+		{"habits.csv", e.writeHabitsCsv},
+		{"history.csv", e.writeHistoryCsv},
+		{"sessions.csv", e.writeSessionsCsv},
+	}
+
+	for _, file := range files {
+		fw, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: e.CreationTime,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write export: %w", err)
+		}
+		if err := file.write(fw); err != nil {
+			return fmt.Errorf("failed to write export %s: %w", file.name, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	return nil
+}
+
+// ReadZip returns the export read from a kera archive,
+// written by [Export.WriteZip] of this or an older version.
+// Unknown fields are ignored, so newer archives of the same version
+// can be read too.
+// It fails with [ErrInvalidArchive] or [ErrUnsupportedVersion].
+func ReadZip(r io.ReaderAt, size int64) (*Export, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrInvalidArchive
+	}
+
+	rc, err := archive.Open(jsonFile)
+	if err != nil {
+		return nil, ErrInvalidArchive
+	}
+	defer rc.Close()
+
+	var e Export
+	decoder := json.NewDecoder(io.LimitReader(rc, jsonMaxBytes))
+	if err := decoder.Decode(&e); err != nil {
+		return nil, ErrInvalidArchive
+	}
+
+	if e.Version < 1 || e.Version > Version {
+		return nil, ErrUnsupportedVersion
+	}
+
+	return &e, nil
+}
+
+func (e *Export) writeJson(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(e)
+}
+
+func (e *Export) writeProfileCsv(w io.Writer) error {
+	p := e.Profile
+	return writeCsv(w,
+		[]string{
+			"id", "username", "display_name", "email",
+			"email_verified", "role", "creation_date",
+		},
+		[][]string{{
+			p.ID, p.Username, p.DisplayName, p.Email,
+			strconv.FormatBool(p.EmailVerified), p.Role,
+			p.CreationDate.Format(dateLayout),
+		}},
+	)
+}
+
+func (e *Export) writeHabitsCsv(w io.Writer) error {
+	records := make([][]string, len(e.Habits))
+	for i, h := range e.Habits {
+		var endDate string
+		if h.EndDate != nil {
+			endDate = h.EndDate.Format(dateLayout)
+		}
+
+		records[i] = []string{
+			h.ID, h.Title, h.Description, h.Status,
+			strings.Join(h.WeekDays, ";"),
+			h.StartDate.Format(dateLayout), endDate,
+		}
+	}
+
+	return writeCsv(w,
+		[]string{
+			"id", "title", "description", "status",
+			"week_days", "start_date", "end_date",
+		},
+		records,
+	)
+}
+
+func (e *Export) writeHistoryCsv(w io.Writer) error {
+	var records [][]string
+	for _, h := range e.Habits {
+		for _, day := range h.History {
+			records = append(records, []string{
+				h.ID, day.Date.Format(dateLayout), day.Status,
+			})
+		}
+	}
+
+	return writeCsv(w, []string{"habit_id", "date", "status"}, records)
+}
+
+func (e *Export) writeSessionsCsv(w io.Writer) error {
+	records := make([][]string, len(e.Sessions))
+	for i, s := range e.Sessions {
+		records[i] = []string{
+			s.ID, s.UserAgent, s.IPAddress,
+			s.CreationTime.Format(time.RFC3339),
+			s.LastSeenTime.Format(time.RFC3339),
+			s.ExpirationTime.Format(time.RFC3339),
+		}
+	}
+
+	return writeCsv(w,
+		[]string{
+			"id", "user_agent", "ip_address",
+			"creation_time", "last_seen_time", "expiration_time",
+		},
+		records,
+	)
+}
+
+func writeCsv(w io.Writer, header []string, records [][]string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, record := range records {
+		for i, field := range record {
+			record[i] = escapeFormula(field)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// escapeFormula prefixes user provided fields that spreadsheet applications
+// would evaluate as a formula with a single quote.
+func escapeFormula(field string) string {
+	if field == "" {
+		return field
+	}
+	switch field[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return "'" + field
+	default:
+		return field
+	}
+}
@@ -0,0 +1,202 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestWriteZip(t *testing.T) {
+	now := time.Date(2024, time.March, 10, 12, 0, 0, 0, time.UTC)
+	endDate := time.Date(2024, time.March, 2, 0, 0, 0, 0, time.UTC)
+
+	export := &Export{
+		Version:      Version,
+		CreationTime: now,
+		Profile: Profile{
+			ID:           "0192d5b6-35a4-7c3a-9a9e-0b1a2b3c4d5e",
+			Username:     "username",
+			DisplayName:  "=display name",
+			Role:         "user",
+			CreationDate: endDate,
+		},
+		Habits: []Habit{
+			{
+				ID:          "0192d5b6-35a4-7c3a-9a9e-0b1a2b3c4d5f",
+				Title:       "Read, write",
+				Description: "notes\nwith a new line",
+				Status:      "ended",
+				WeekDays:    []string{"monday", "friday"},
+				StartDate:   endDate.AddDate(0, -1, 0),
+				EndDate:     &endDate,
+				History: []Day{
+					{endDate.AddDate(0, 0, -2), "done"},
+					{endDate, "missed"},
+				},
+			},
+		},
+		Sessions: []Session{},
+	}
+
+	var buf bytes.Buffer
+	if err := export.WriteZip(&buf); err != nil {
+		t.Fatalf("WriteZip(), error=%v", err)
+	}
+
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("WriteZip(), invalid archive, error=%v", err)
+	}
+
+	files := make(map[string][]byte)
+	for _, file := range r.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[file.Name] = content
+	}
+
+	var decoded Export
+	if err := json.Unmarshal(files["export.json"], &decoded); err != nil {
+		t.Fatalf("export.json, error=%v", err)
+	}
+	if decoded.Profile.DisplayName != export.Profile.DisplayName ||
+		len(decoded.Habits) != 1 || len(decoded.Habits[0].History) != 2 {
+		t.Errorf("export.json, got=%+v, expected=%+v", decoded, *export)
+	}
+
+	tests := []struct {
+		name     string
+		file     string
+		expected [][]string
+	}{
+		{
+			"Profile",
+			"profile.csv",
+			[][]string{
+				{"id", "username", "display_name", "email", "email_verified", "role", "creation_date"},
+				{export.Profile.ID, "username", "'=display name", "", "false", "user", "2024-03-02"},
+			},
+		},
+		{
+			"Habits",
+			"habits.csv",
+			[][]string{
+				{"id", "title", "description", "status", "week_days", "start_date", "end_date"},
+				{
+					export.Habits[0].ID, "Read, write", "notes\nwith a new line",
+					"ended", "monday;friday", "2024-02-02", "2024-03-02",
+				},
+			},
+		},
+		{
+			"History",
+			"history.csv",
+			[][]string{
+				{"habit_id", "date", "status"},
+				{export.Habits[0].ID, "2024-02-29", "done"},
+				{export.Habits[0].ID, "2024-03-02", "missed"},
+			},
+		},
+		{
+			"Sessions",
+			"sessions.csv",
+			[][]string{
+				{"id", "user_agent", "ip_address", "creation_time", "last_seen_time", "expiration_time"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			content, ok := files[test.file]
+			if !ok {
+				t.Fatalf("WriteZip(), missing file=%q", test.file)
+			}
+
+			records, err := csv.NewReader(bytes.NewReader(content)).ReadAll()
+			if err != nil {
+				t.Fatalf("%s, error=%v", test.file, err)
+			}
+
+			if len(records) != len(test.expected) {
+				t.Fatalf("%s, got=%q, expected=%q", test.file, records, test.expected)
+			}
+			for i := range records {
+				for j := range records[i] {
+					if records[i][j] != test.expected[i][j] {
+						t.Errorf("%s, got=%q, expected=%q", test.file, records[i], test.expected[i])
+						break
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestReadZip(t *testing.T) {
+	endDate := time.Date(2024, time.March, 2, 0, 0, 0, 0, time.UTC)
+	export := &Export{
+		Version:      Version,
+		CreationTime: endDate,
+		Profile:      Profile{DisplayName: "display name"},
+		Habits: []Habit{{
+			ID:       "0192d5b6-35a4-7c3a-9a9e-0b1a2b3c4d5f",
+			Title:    "Read",
+			WeekDays: []string{"monday"},
+			EndDate:  &endDate,
+			History:  []Day{{endDate, "done"}},
+		}},
+	}
+
+	var valid bytes.Buffer
+	if err := export.WriteZip(&valid); err != nil {
+		t.Fatal(err)
+	}
+
+	future := *export
+	future.Version = Version + 1
+	var unsupported bytes.Buffer
+	if err := future.WriteZip(&unsupported); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		input     []byte
+		shouldErr bool
+	}{
+		{"Valid", valid.Bytes(), false},
+		{"Invalid: version", unsupported.Bytes(), true},
+		{"Invalid: not a zip", []byte("export.json"), true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := ReadZip(bytes.NewReader(test.input), int64(len(test.input)))
+			if (err != nil) != test.shouldErr {
+				t.Fatalf("ReadZip(), error=%v, shouldErr=%v", err, test.shouldErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if result.Profile.DisplayName != export.Profile.DisplayName ||
+				len(result.Habits) != 1 ||
+				!result.Habits[0].EndDate.Equal(endDate) ||
+				result.Habits[0].History[0] != export.Habits[0].History[0] {
+				t.Errorf("ReadZip(), got=%+v, expected=%+v", *result, *export)
+			}
+		})
+	}
+}
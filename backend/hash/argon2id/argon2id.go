@@ -87,12 +87,38 @@ func NewParams(
 	return &Params{memory, iterations, parallelism, keyLength, saltLength}, nil
 }
 
+// Memory returns the memory cost in KiB.
+func (p *Params) Memory() uint32 {
+	return p.memory
+}
+
+// Iterations returns the time cost.
+func (p *Params) Iterations() uint32 {
+	return p.iterations
+}
+
+// Parallelism returns the number of lanes.
+func (p *Params) Parallelism() uint8 {
+	return p.parallelism
+}
+
+// String returns the params in the PHC string format, e.g. "m=19456,t=2,p=1".
+func (p *Params) String() string {
+	return fmt.Sprintf("m=%d,t=%d,p=%d", p.memory, p.iterations, p.parallelism)
+}
+
 func Hash(input string, params *Params) (string, error) {
 	inputLength := len(input)
 	if inputLength < InputMinLength || inputLength > InputMaxLength {
 		return "", ErrInvalidInput
 	}
 
+	return hash([]byte(input), params, "")
+}
+
+// hash returns the encoded hash of the input.
+// The key ID is included in the params part if it's not empty.
+func hash(input []byte, params *Params, keyID string) (string, error) {
 	if params == nil {
 		return "", ErrNilParamsPointer
 	}
@@ -103,7 +129,7 @@ func Hash(input string, params *Params) (string, error) {
 	}
 
 	key := argon2.IDKey(
-		[]byte(input),
+		input,
 		salt,
 		params.iterations,
 		params.memory,
@@ -111,23 +137,39 @@ func Hash(input string, params *Params) (string, error) {
 		params.keyLength,
 	)
 
+	encodedParams := params.String()
+	if keyID != "" {
+		encodedParams += ",keyid=" + keyID
+	}
+
 	output := fmt.Sprintf(
-		"$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
-		Variant, Version, params.memory, params.iterations, params.parallelism,
+		"$%s$v=%d$%s$%s$%s",
+		Variant, Version, encodedParams,
 		base64.RawStdEncoding.EncodeToString(salt),
 		base64.RawStdEncoding.EncodeToString(key),
 	)
 	return output, nil
 }
 
+// VerifyHash returns true if the input matches the hashed input.
+// It fails with [ErrUnknownKeyID] if the hash was created with a pepper,
+// such hashes are verified by [Hasher].
 func VerifyHash(input, hashedInput string) (bool, error) {
-	params, salt, otherKey, err := decodeHash(hashedInput)
+	params, keyID, salt, otherKey, err := decodeHash(hashedInput)
 	if err != nil {
 		return false, err
 	}
 
+	if keyID != "" {
+		return false, ErrUnknownKeyID
+	}
+
+	return verify([]byte(input), params, salt, otherKey), nil
+}
+
+func verify(input []byte, params *Params, salt, otherKey []byte) bool {
 	key := argon2.IDKey(
-		[]byte(input),
+		input,
 		salt,
 		params.iterations,
 		params.memory,
@@ -136,51 +178,75 @@ func VerifyHash(input, hashedInput string) (bool, error) {
 	)
 
 	if result := subtle.ConstantTimeEq(int32(len(key)), int32(len(otherKey))); result != 1 {
-		return false, nil
+		return false
 	}
 
 	if result := subtle.ConstantTimeCompare(key, otherKey); result != 1 {
-		return false, nil
+		return false
 	}
 
-	return true, nil
+	return true
 }
 
-func decodeHash(hashedInput string) (*Params, []byte, []byte, error) {
+// NeedsRehash returns true if the hashed input was created
+// with params other than the provided ones,
+// or with a pepper, so it should be hashed again once the input is known.
+// It fails if the hashed input is invalid.
+func NeedsRehash(hashedInput string, params *Params) (bool, error) {
+	if params == nil {
+		return false, ErrNilParamsPointer
+	}
+
+	hashParams, keyID, _, _, err := decodeHash(hashedInput)
+	if err != nil {
+		return false, err
+	}
+
+	return keyID != "" || *hashParams != *params, nil
+}
+
+// decodeHash returns the params, pepper key ID, salt and key of the hashed input.
+// The key ID is empty if the hash was created without a pepper.
+func decodeHash(hashedInput string) (*Params, string, []byte, []byte, error) {
 	parts := strings.Split(hashedInput, "$")
 
 	if len(parts) != 6 {
-		return nil, nil, nil, ErrInvalidHashedInput
+		return nil, "", nil, nil, ErrInvalidHashedInput
 	}
 
 	if parts[1] != Variant {
-		return nil, nil, nil, ErrInvalidVariant
+		return nil, "", nil, nil, ErrInvalidVariant
 	}
 
 	var v uint
 	_, err := fmt.Sscanf(parts[2], "v=%d", &v)
 	if err != nil {
-		return nil, nil, nil, ErrInvalidHashedInput
+		return nil, "", nil, nil, ErrInvalidHashedInput
 	}
 	if v != Version {
-		return nil, nil, nil, ErrInvalidVersion
+		return nil, "", nil, nil, ErrInvalidVersion
+	}
+
+	encodedParams, keyID, hasKeyID := strings.Cut(parts[3], ",keyid=")
+	if hasKeyID && !isValidKeyID(keyID) {
+		return nil, "", nil, nil, ErrInvalidHashedInput
 	}
 
 	var m, t uint32
 	var p uint8
-	_, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &m, &t, &p)
+	_, err = fmt.Sscanf(encodedParams, "m=%d,t=%d,p=%d", &m, &t, &p)
 	if err != nil {
-		return nil, nil, nil, ErrInvalidHashedInput
+		return nil, "", nil, nil, ErrInvalidHashedInput
 	}
 
 	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
 	if err != nil {
-		return nil, nil, nil, ErrInvalidHashedInput
+		return nil, "", nil, nil, ErrInvalidHashedInput
 	}
 
 	key, err := base64.RawStdEncoding.DecodeString(parts[5])
 	if err != nil {
-		return nil, nil, nil, ErrInvalidHashedInput
+		return nil, "", nil, nil, ErrInvalidHashedInput
 	}
 
 	params, err := NewParams(
@@ -189,10 +255,10 @@ func decodeHash(hashedInput string) (*Params, []byte, []byte, error) {
 		uint32(len(salt)),
 	)
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, "", nil, nil, err
 	}
 
-	return params, salt, key, nil
+	return params, keyID, salt, key, nil
 }
 
 func generateSalt(length uint32) ([]byte, error) {
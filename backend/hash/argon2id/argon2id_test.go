@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestHash(t *testing.T) {
@@ -121,6 +122,7 @@ func TestDecodeHash(t *testing.T) {
 		name           string
 		hashedInput    string
 		expectedParams *Params
+		expectedKeyID  string
 		expectedSalt   []byte
 		expectedKey    []byte
 		shouldErr      bool
@@ -129,6 +131,7 @@ func TestDecodeHash(t *testing.T) {
 			"Valid",
 			"$argon2id$v=19$m=19456,t=2,p=1$YWFhYUFBQUFhYWFhQUFBQQ$sxzziMCgbNOhfrgUXet7cS5rE2gq2pLe5hUaXLC966I",
 			DefaultParams,
+			"",
 			[]byte("aaaaAAAAaaaaAAAA"),
 			[]byte{179, 28, 243, 136, 192, 160, 108, 211, 161, 126, 184, 20, 93, 235, 123, 113, 46, 107, 19, 104, 42, 218, 146, 222, 230, 21, 26, 92, 176, 189, 235, 162},
 			false,
@@ -143,15 +146,25 @@ func TestDecodeHash(t *testing.T) {
 				keyLength:   32,
 				saltLength:  16,
 			},
+			"",
 			[]byte("bbbbBBBBbbbbBBBB"),
 			[]byte{207, 141, 229, 231, 56, 245, 226, 253, 170, 66, 115, 197, 45, 108, 210, 248, 40, 186, 28, 90, 0, 220, 134, 222, 206, 107, 207, 181, 89, 194, 76, 9},
 			false,
 		},
+		{
+			"Valid: key ID",
+			"$argon2id$v=19$m=19456,t=2,p=1,keyid=2024-01$YWFhYUFBQUFhYWFhQUFBQQ$sxzziMCgbNOhfrgUXet7cS5rE2gq2pLe5hUaXLC966I",
+			DefaultParams,
+			"2024-01",
+			[]byte("aaaaAAAAaaaaAAAA"),
+			[]byte{179, 28, 243, 136, 192, 160, 108, 211, 161, 126, 184, 20, 93, 235, 123, 113, 46, 107, 19, 104, 42, 218, 146, 222, 230, 21, 26, 92, 176, 189, 235, 162},
+			false,
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			params, salt, key, err := decodeHash(test.hashedInput)
+			params, keyID, salt, key, err := decodeHash(test.hashedInput)
 			if (err != nil) != test.shouldErr {
 				t.Errorf(
 					"decodeHash(%q), error=%v, shouldErr=%v",
@@ -164,6 +177,12 @@ func TestDecodeHash(t *testing.T) {
 					test.hashedInput, params, test.expectedParams,
 				)
 			}
+			if keyID != test.expectedKeyID {
+				t.Errorf(
+					"decodeHash(%q), keyID=%q, expectedKeyID=%q",
+					test.hashedInput, keyID, test.expectedKeyID,
+				)
+			}
 			if !bytes.Equal(salt, test.expectedSalt) {
 				t.Errorf(
 					"decodeHash(%q), salt=%v, expectedSalt=%v",
@@ -186,3 +205,106 @@ func TestGenerateSalt(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestNeedsRehash(t *testing.T) {
+	stronger, err := NewParams(64*1024, 3, 1, 32, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hashedInput, err := Hash("password", DefaultParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		hashedInput string
+		params      *Params
+		shouldErr   bool
+		shouldBe    bool
+	}{
+		{
+			"Valid: same params",
+			hashedInput,
+			DefaultParams,
+			false,
+			false,
+		},
+		{
+			"Valid: outdated params",
+			hashedInput,
+			stronger,
+			false,
+			true,
+		},
+		{
+			"Valid: different key length",
+			"$argon2id$v=19$m=19456,t=2,p=1$YWFhYUFBQUFhYWFhQUFBQQ$KdIUCTl6NPY+m4WM+pHJW0fWIQMLQV5L",
+			DefaultParams,
+			false,
+			true,
+		},
+		{
+			"Invalid: hashed input",
+			"$argon2id$v=19$m=19456",
+			DefaultParams,
+			true,
+			false,
+		},
+		{
+			"Invalid: nil params",
+			hashedInput,
+			nil,
+			true,
+			false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := NeedsRehash(test.hashedInput, test.params)
+			if (err != nil) != test.shouldErr || got != test.shouldBe {
+				t.Errorf(
+					"NeedsRehash(%q, %v), got=%v, error=%v, shouldErr=%v, shouldBe=%v",
+					test.hashedInput, test.params, got, err, test.shouldErr, test.shouldBe,
+				)
+			}
+		})
+	}
+}
+
+func TestCalibrate(t *testing.T) {
+	tests := []struct {
+		name               string
+		target             time.Duration
+		memory             uint32
+		parallelism        uint8
+		shouldErr          bool
+		expectedIterations uint32
+	}{
+		{"Valid: no target", 0, 64, 1, false, 1},
+		{"Valid: unreachable target", time.Hour, 64, 1, false, CalibrateMaxIterations},
+		{"Invalid: memory", 0, 1, 1, true, 0},
+		{"Invalid: parallelism", 0, 64, 0, true, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			params, _, err := Calibrate(test.target, test.memory, test.parallelism)
+			if (err != nil) != test.shouldErr {
+				t.Errorf(
+					"Calibrate(%v, %d, %d), error=%v, shouldErr=%v",
+					test.target, test.memory, test.parallelism, err, test.shouldErr,
+				)
+			}
+			if err == nil && params.Iterations() != test.expectedIterations {
+				t.Errorf(
+					"Calibrate(%v, %d, %d), iterations=%d, expected=%d",
+					test.target, test.memory, test.parallelism,
+					params.Iterations(), test.expectedIterations,
+				)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package argon2id
+
+import (
+	"time"
+)
+
+// CalibrateMaxIterations limits the iterations tried by [Calibrate].
+const CalibrateMaxIterations = 32
+
+// Calibrate returns params with the provided memory and parallelism,
+// and the lowest number of iterations for which hashing takes
+// at least the target duration on the host, together with the measured
+// duration. Iterations are capped at [CalibrateMaxIterations].
+// Key and salt lengths are the ones of [DefaultParams].
+// It fails if the memory and parallelism are invalid.
+func Calibrate(
+	target time.Duration, memory uint32, parallelism uint8,
+) (*Params, time.Duration, error) {
+	var params *Params
+	var elapsed time.Duration
+
+	for iterations := uint32(IterationsMin); iterations <= CalibrateMaxIterations; iterations++ {
+		var err error
+		params, err = NewParams(
+			memory, iterations, parallelism,
+			DefaultParams.keyLength, DefaultParams.saltLength,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		start := time.Now()
+		if _, err := Hash("calibrate", params); err != nil {
+			return nil, 0, err
+		}
+		elapsed = time.Since(start)
+
+		if elapsed >= target {
+			break
+		}
+	}
+
+	return params, elapsed, nil
+}
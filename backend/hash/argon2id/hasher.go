@@ -0,0 +1,142 @@
+package argon2id
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+)
+
+const (
+	PepperMinLength = 32
+	KeyIDMaxLength  = 32
+)
+
+var (
+	ErrInvalidPepper = errors.New("argon2id: pepper is invalid")
+	ErrUnknownKeyID  = errors.New("argon2id: pepper key ID is unknown")
+)
+
+// Pepper is a secret key mixed into the input with HMAC-SHA256
+// before hashing, so leaked hashes can't be cracked without it.
+// The key ID is stored in the hash, so the pepper can be rotated.
+type Pepper struct {
+	KeyID string
+	Key   []byte
+}
+
+// Hasher hashes and verifies passwords with argon2id and optional peppers.
+type Hasher struct {
+	params  *Params
+	keyID   string
+	peppers map[string][]byte
+}
+
+// NewHasher returns a new *Hasher hashing with the provided params.
+// New hashes use the first of the provided peppers, the others are only
+// used to verify hashes created before the rotation.
+// Without peppers, the input is hashed as-is.
+// It fails if the params are nil, or a pepper is invalid or repeated.
+func NewHasher(params *Params, peppers ...Pepper) (*Hasher, error) {
+	if params == nil {
+		return nil, ErrNilParamsPointer
+	}
+
+	h := &Hasher{
+		params:  params,
+		peppers: make(map[string][]byte, len(peppers)),
+	}
+
+	for i, pepper := range peppers {
+		if !isValidKeyID(pepper.KeyID) || len(pepper.Key) < PepperMinLength {
+			return nil, ErrInvalidPepper
+		}
+		if _, ok := h.peppers[pepper.KeyID]; ok {
+			return nil, ErrInvalidPepper
+		}
+		h.peppers[pepper.KeyID] = pepper.Key
+
+		if i == 0 {
+			h.keyID = pepper.KeyID
+		}
+	}
+
+	return h, nil
+}
+
+// Params returns the params new hashes are created with.
+func (h *Hasher) Params() *Params {
+	return h.params
+}
+
+// Hash returns the encoded hash of the input,
+// peppered with the current pepper if there is one.
+func (h *Hasher) Hash(input string) (string, error) {
+	inputLength := len(input)
+	if inputLength < InputMinLength || inputLength > InputMaxLength {
+		return "", ErrInvalidInput
+	}
+
+	return hash(h.pepper([]byte(input), h.keyID), h.params, h.keyID)
+}
+
+// Verify returns true if the input matches the hashed input.
+// Hashes created without a pepper are verified too,
+// [Hasher.NeedsRehash] reports them to be upgraded.
+// It fails with [ErrUnknownKeyID] if the pepper of the hash is not configured.
+func (h *Hasher) Verify(input, hashedInput string) (bool, error) {
+	params, keyID, salt, key, err := decodeHash(hashedInput)
+	if err != nil {
+		return false, err
+	}
+
+	if _, ok := h.peppers[keyID]; keyID != "" && !ok {
+		return false, ErrUnknownKeyID
+	}
+
+	return verify(h.pepper([]byte(input), keyID), params, salt, key), nil
+}
+
+// NeedsRehash returns true if the hashed input was created with
+// params other than the hasher's or a pepper other than the current one.
+// It fails if the hashed input is invalid.
+func (h *Hasher) NeedsRehash(hashedInput string) (bool, error) {
+	params, keyID, _, _, err := decodeHash(hashedInput)
+	if err != nil {
+		return false, err
+	}
+
+	return keyID != h.keyID || *params != *h.params, nil
+}
+
+// pepper returns HMAC-SHA256 of the input keyed with the pepper
+// of the key ID, or the input itself if the key ID is empty.
+func (h *Hasher) pepper(input []byte, keyID string) []byte {
+	if keyID == "" {
+		return input
+	}
+
+	mac := hmac.New(sha256.New, h.peppers[keyID])
+	mac.Write(input)
+	return mac.Sum(nil)
+}
+
+// isValidKeyID returns true if the key ID can be stored in the params part
+// of a hash, it may consist of ASCII letters, digits, '-' and '_'.
+func isValidKeyID(keyID string) bool {
+	if len(keyID) == 0 || len(keyID) > KeyIDMaxLength {
+		return false
+	}
+
+	for _, r := range keyID {
+		switch {
+		case 'a' <= r && r <= 'z':
+		case 'A' <= r && r <= 'Z':
+		case '0' <= r && r <= '9':
+		case r == '-' || r == '_':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
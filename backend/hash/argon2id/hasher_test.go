@@ -0,0 +1,122 @@
+package argon2id
+
+import (
+	"bytes"
+	"testing"
+)
+
+var (
+	testParams  = &Params{memory: 1024, iterations: 1, parallelism: 1, keyLength: 32, saltLength: 16}
+	testPepper1 = Pepper{"k1", bytes.Repeat([]byte{1}, PepperMinLength)}
+	testPepper2 = Pepper{"k2", bytes.Repeat([]byte{2}, PepperMinLength)}
+)
+
+func TestNewHasher(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    *Params
+		peppers   []Pepper
+		shouldErr bool
+	}{
+		{"Valid: no pepper", testParams, nil, false},
+		{"Valid: pepper", testParams, []Pepper{testPepper1}, false},
+		{"Valid: rotated pepper", testParams, []Pepper{testPepper2, testPepper1}, false},
+		{"Invalid: nil params", nil, nil, true},
+		{"Invalid: short key", testParams, []Pepper{{"k1", []byte("short")}}, true},
+		{"Invalid: empty key ID", testParams, []Pepper{{"", testPepper1.Key}}, true},
+		{"Invalid: key ID with '$'", testParams, []Pepper{{"k$1", testPepper1.Key}}, true},
+		{"Invalid: repeated key ID", testParams, []Pepper{testPepper1, testPepper1}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewHasher(test.params, test.peppers...)
+			if (err != nil) != test.shouldErr {
+				t.Errorf(
+					"NewHasher(%v, %v), error=%v, shouldErr=%v",
+					test.params, test.peppers, err, test.shouldErr,
+				)
+			}
+		})
+	}
+}
+
+func TestHasher(t *testing.T) {
+	plain, err := NewHasher(testParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	peppered, err := NewHasher(testParams, testPepper1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotated, err := NewHasher(testParams, testPepper2, testPepper1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPepper, err := NewHasher(testParams, testPepper2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plainHash, err := plain.Hash("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pepperedHash, err := peppered.Hash("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaultHash, err := Hash("password", DefaultParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name             string
+		hasher           *Hasher
+		input            string
+		hashedInput      string
+		shouldBe         bool
+		shouldNeedRehash bool
+		shouldErr        bool
+	}{
+		{"Valid: no pepper", plain, "password", plainHash, true, false, false},
+		{"Valid: pepper", peppered, "password", pepperedHash, true, false, false},
+		{"Valid: rotated pepper", rotated, "password", pepperedHash, true, true, false},
+		{"Valid: pepper added", peppered, "password", plainHash, true, true, false},
+		{"Valid: outdated params", plain, "password", defaultHash, true, true, false},
+		{"Valid: wrong input", peppered, "passw0rd", pepperedHash, false, false, false},
+		{"Invalid: unknown pepper", otherPepper, "password", pepperedHash, false, true, true},
+		{"Invalid: missing pepper", plain, "password", pepperedHash, false, true, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			isValid, err := test.hasher.Verify(test.input, test.hashedInput)
+			if (err != nil) != test.shouldErr {
+				t.Fatalf(
+					"Verify(%q, %q), error=%v, shouldErr=%v",
+					test.input, test.hashedInput, err, test.shouldErr,
+				)
+			}
+			if isValid != test.shouldBe {
+				t.Errorf(
+					"Verify(%q, %q), got=%v, expected=%v",
+					test.input, test.hashedInput, isValid, test.shouldBe,
+				)
+			}
+
+			needsRehash, err := test.hasher.NeedsRehash(test.hashedInput)
+			if err != nil {
+				t.Fatalf("NeedsRehash(%q), error=%v", test.hashedInput, err)
+			}
+			if needsRehash != test.shouldNeedRehash {
+				t.Errorf(
+					"NeedsRehash(%q), got=%v, expected=%v",
+					test.hashedInput, needsRehash, test.shouldNeedRehash,
+				)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+// Package bcrypt verifies bcrypt hashes created by other applications.
+// New hashes are not created, they are meant to be upgraded to argon2id.
+package bcrypt
+
+import (
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrInvalidHashedInput = errors.New("bcrypt: hashed input is invalid")
+
+var prefixes = []string{"$2a$", "$2b$", "$2y$"}
+
+// IsHash returns true if the hashed input looks like a bcrypt hash.
+func IsHash(hashedInput string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(hashedInput, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// VerifyHash returns true if the input matches the hashed input.
+// It fails if the hashed input is not a valid bcrypt hash.
+func VerifyHash(input, hashedInput string) (bool, error) {
+	if !IsHash(hashedInput) {
+		return false, ErrInvalidHashedInput
+	}
+
+	err := bcrypt.CompareHashAndPassword([]byte(hashedInput), []byte(input))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	if err != nil {
+		return false, ErrInvalidHashedInput
+	}
+
+	return true, nil
+}
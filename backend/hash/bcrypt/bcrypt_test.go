@@ -0,0 +1,73 @@
+package bcrypt
+
+import (
+	"testing"
+)
+
+// Hashes of "password" with cost 4, the prefixes differ between implementations
+const (
+	hash2a = "$2a$04$2qeqSEzx1Lw5ya3n/N6lJ.V7SL2nlBC9wP65ThvvUutDdWcGYw/by"
+	hash2b = "$2b$04$2qeqSEzx1Lw5ya3n/N6lJ.V7SL2nlBC9wP65ThvvUutDdWcGYw/by"
+	hash2y = "$2y$04$2qeqSEzx1Lw5ya3n/N6lJ.V7SL2nlBC9wP65ThvvUutDdWcGYw/by"
+)
+
+func TestIsHash(t *testing.T) {
+	tests := []struct {
+		name        string
+		hashedInput string
+		shouldBe    bool
+	}{
+		{"Valid: 2a", hash2a, true},
+		{"Valid: 2b", hash2b, true},
+		{"Valid: 2y", hash2y, true},
+		{"Invalid: argon2id", "$argon2id$v=19$m=19456,t=2,p=1$c2FsdA$a2V5", false},
+		{"Invalid: empty", "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			isHash := IsHash(test.hashedInput)
+			if isHash != test.shouldBe {
+				t.Errorf(
+					"IsHash(%q), got=%v, expected=%v",
+					test.hashedInput, isHash, test.shouldBe,
+				)
+			}
+		})
+	}
+}
+
+func TestVerifyHash(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		hashedInput string
+		shouldBe    bool
+		shouldErr   bool
+	}{
+		{"Valid: 2a", "password", hash2a, true, false},
+		{"Valid: 2b", "password", hash2b, true, false},
+		{"Valid: 2y", "password", hash2y, true, false},
+		{"Valid: wrong input", "passw0rd", hash2a, false, false},
+		{"Invalid: truncated hash", "password", hash2a[:20], false, true},
+		{"Invalid: not bcrypt", "password", "$1$salt$hash", false, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			isValid, err := VerifyHash(test.input, test.hashedInput)
+			if (err != nil) != test.shouldErr {
+				t.Fatalf(
+					"VerifyHash(%q, %q), error=%v, shouldErr=%v",
+					test.input, test.hashedInput, err, test.shouldErr,
+				)
+			}
+			if isValid != test.shouldBe {
+				t.Errorf(
+					"VerifyHash(%q, %q), got=%v, expected=%v",
+					test.input, test.hashedInput, isValid, test.shouldBe,
+				)
+			}
+		})
+	}
+}
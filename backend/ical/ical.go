@@ -0,0 +1,184 @@
+// Package ical writes iCalendar (RFC 5545) feeds of habits,
+// so they can be subscribed to from calendar applications.
+// Events are all-day events: a recurring event per habit
+// on its tracked days of the week, and an event per done day.
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/zvxte/kera/model/date"
+	"github.com/zvxte/kera/model/habit"
+)
+
+const (
+	prodID = "-//kera//kera//EN"
+
+	// uidDomain makes event UIDs globally unique, as RFC 5545 recommends.
+	uidDomain = "kera"
+
+	// maxLineLen is the maximum length of a content line in octets,
+	// excluding the line break.
+	maxLineLen = 75
+
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405Z"
+)
+
+// byDay represents the BYDAY values of the days of the week,
+// indexed by [habit.WeekDay].
+var byDay = [...]string{"MO", "TU", "WE", "TH", "FR", "SA", "SU"}
+
+// Calendar represents an iCalendar object with the events of habits.
+type Calendar struct {
+	Name   string
+	Events []Event
+}
+
+// Event represents an all-day event.
+// If WeekDays is not empty, the event repeats weekly on these days
+// from Date until Until, or forever if Until is zero.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Date        date.Date
+	WeekDays    []habit.WeekDay
+	Until       date.Date
+}
+
+// NewHabitEvent returns the recurring event of the habit,
+// on its tracked days of the week between its start and end dates.
+// It returns false if the habit has no tracked day between these dates.
+func NewHabitEvent(h *habit.Habit) (Event, bool) {
+	first := h.StartDate
+	for i := 0; i < 7 && !h.TrackedWeekDays.Tracked(habit.WeekDay(first.WeekDay())); i++ {
+		first = first.Add(24 * time.Hour)
+	}
+	if !h.TrackedWeekDays.Tracked(habit.WeekDay(first.WeekDay())) ||
+		(!h.EndDate.IsZero() && first.After(h.EndDate)) {
+		return Event{}, false
+	}
+
+	return Event{
+		UID:         h.ID.String() + "@" + uidDomain,
+		Summary:     h.Title,
+		Description: h.Description,
+		Date:        first,
+		WeekDays:    h.TrackedWeekDays.WeekDays(),
+		Until:       h.EndDate,
+	}, true
+}
+
+// NewDoneEvent returns the event of the habit's day that is done.
+func NewDoneEvent(h *habit.Habit, d date.Date) Event {
+	return Event{
+		UID:     h.ID.String() + "-" + time.Time(d).Format(dateLayout) + "@" + uidDomain,
+		Summary: "Done: " + h.Title,
+		Date:    d,
+	}
+}
+
+// Write writes the calendar to w, stamped with the provided time.
+// It fails if w fails.
+func (c *Calendar) Write(w io.Writer, now time.Time) error {
+	cw := &writer{w: bufio.NewWriter(w)}
+	stamp := now.UTC().Format(dateTimeLayout)
+
+	cw.line("BEGIN:VCALENDAR")
+	cw.line("VERSION:2.0")
+	cw.line("PRODID:" + prodID)
+	cw.line("CALSCALE:GREGORIAN")
+	cw.line("METHOD:PUBLISH")
+	if c.Name != "" {
+		cw.line("X-WR-CALNAME:" + escapeText(c.Name))
+	}
+
+	for _, event := range c.Events {
+		cw.line("BEGIN:VEVENT")
+		cw.line("UID:" + event.UID)
+		cw.line("DTSTAMP:" + stamp)
+		cw.line("DTSTART;VALUE=DATE:" + time.Time(event.Date).Format(dateLayout))
+		cw.line("DTEND;VALUE=DATE:" + time.Time(event.Date).AddDate(0, 0, 1).Format(dateLayout))
+		if len(event.WeekDays) > 0 {
+			cw.line("RRULE:" + rule(event.WeekDays, event.Until))
+		}
+		cw.line("SUMMARY:" + escapeText(event.Summary))
+		if event.Description != "" {
+			cw.line("DESCRIPTION:" + escapeText(event.Description))
+		}
+		// All-day habits should not show as busy
+		cw.line("TRANSP:TRANSPARENT")
+		cw.line("END:VEVENT")
+	}
+
+	cw.line("END:VCALENDAR")
+
+	if cw.err != nil {
+		return cw.err
+	}
+	return cw.w.Flush()
+}
+
+// rule returns the weekly recurrence rule on the days of the week,
+// until the date if it's not zero.
+func rule(weekDays []habit.WeekDay, until date.Date) string {
+	days := make([]string, len(weekDays))
+	for i, day := range weekDays {
+		days[i] = byDay[day]
+	}
+
+	r := "FREQ=WEEKLY;BYDAY=" + strings.Join(days, ",")
+	if !until.IsZero() {
+		r += ";UNTIL=" + time.Time(until).Format(dateLayout)
+	}
+	return r
+}
+
+// escapeText escapes a TEXT property value.
+func escapeText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(s)
+}
+
+// writer writes content lines, keeping the first error.
+type writer struct {
+	w   *bufio.Writer
+	err error
+}
+
+// line writes the content line ended by CRLF,
+// folded into lines of at most [maxLineLen] octets.
+// Continuation lines start with a space, lines are never
+// folded in the middle of a UTF-8 encoded character.
+func (cw *writer) line(s string) {
+	if cw.err != nil {
+		return
+	}
+
+	limit := maxLineLen
+	for len(s) > limit {
+		i := limit
+		// Step back to the first byte of a UTF-8 encoded character
+		for i > 0 && s[i]&0xC0 == 0x80 {
+			i--
+		}
+
+		if _, cw.err = cw.w.WriteString(s[:i] + "\r\n "); cw.err != nil {
+			return
+		}
+		s = s[i:]
+		limit = maxLineLen - 1
+	}
+
+	_, cw.err = cw.w.WriteString(s + "\r\n")
+}
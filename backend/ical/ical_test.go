@@ -0,0 +1,194 @@
+package ical
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zvxte/kera/model/date"
+	"github.com/zvxte/kera/model/habit"
+	"github.com/zvxte/kera/model/uuid"
+)
+
+func TestNewHabitEvent(t *testing.T) {
+	id, _ := uuid.Parse("0192d5b6-35a4-7c3a-9a9e-0b1a2b3c4d5e")
+	monday := date.New(2024, time.March, 4)
+	mondayFriday := habit.TrackedWeekDays(1<<habit.Monday | 1<<habit.Friday)
+
+	tests := []struct {
+		name            string
+		trackedWeekDays habit.TrackedWeekDays
+		startDate       date.Date
+		endDate         date.Date
+		expectedOk      bool
+		expectedDate    date.Date
+	}{
+		{
+			"Valid: starts on a tracked day",
+			mondayFriday, monday, date.Date{},
+			true, monday,
+		},
+		{
+			"Valid: starts before a tracked day",
+			mondayFriday, monday.Add(24 * time.Hour), date.Date{},
+			true, date.New(2024, time.March, 8),
+		},
+		{
+			"Valid: starts before a tracked day of the next week",
+			mondayFriday, date.New(2024, time.March, 9), date.Date{},
+			true, date.New(2024, time.March, 11),
+		},
+		{
+			"Valid: ended on the first tracked day",
+			mondayFriday, monday.Add(24 * time.Hour), date.New(2024, time.March, 8),
+			true, date.New(2024, time.March, 8),
+		},
+		{
+			"Invalid: ended before a tracked day",
+			mondayFriday, monday.Add(24 * time.Hour), date.New(2024, time.March, 7),
+			false, date.Date{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status := habit.Active
+			if !test.endDate.IsZero() {
+				status = habit.Ended
+			}
+			h, err := habit.Load(
+				id, status, "title", "", test.trackedWeekDays,
+				test.startDate, test.endDate,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			event, ok := NewHabitEvent(h)
+			if ok != test.expectedOk {
+				t.Fatalf("NewHabitEvent(), got=%v, expected=%v", ok, test.expectedOk)
+			}
+			if !ok {
+				return
+			}
+
+			if !event.Date.Equal(test.expectedDate) {
+				t.Errorf(
+					"NewHabitEvent(), got=%v, expected=%v",
+					event.Date, test.expectedDate,
+				)
+			}
+			if !event.Until.Equal(test.endDate) {
+				t.Errorf(
+					"NewHabitEvent(), until=%v, expected=%v",
+					event.Until, test.endDate,
+				)
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	id, _ := uuid.Parse("0192d5b6-35a4-7c3a-9a9e-0b1a2b3c4d5e")
+	h, err := habit.Load(
+		id, habit.Ended, "Read, write; repeat", "back\\slash, comma",
+		habit.TrackedWeekDays(1<<habit.Monday|1<<habit.Friday),
+		date.New(2024, time.March, 4), date.New(2024, time.March, 29),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event, ok := NewHabitEvent(h)
+	if !ok {
+		t.Fatal("NewHabitEvent(), no event")
+	}
+
+	calendar := &Calendar{
+		Name: "kera",
+		Events: []Event{
+			event,
+			NewDoneEvent(h, date.New(2024, time.March, 8)),
+		},
+	}
+
+	var buf bytes.Buffer
+	now := time.Date(2024, time.March, 10, 12, 30, 0, 0, time.UTC)
+	if err := calendar.Write(&buf, now); err != nil {
+		t.Fatalf("Write(), error=%v", err)
+	}
+
+	expected := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//kera//kera//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:kera",
+		"BEGIN:VEVENT",
+		"UID:0192d5b6-35a4-7c3a-9a9e-0b1a2b3c4d5e@kera",
+		"DTSTAMP:20240310T123000Z",
+		"DTSTART;VALUE=DATE:20240304",
+		"DTEND;VALUE=DATE:20240305",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO,FR;UNTIL=20240329",
+		`SUMMARY:Read\, write\; repeat`,
+		`DESCRIPTION:back\\slash\, comma`,
+		"TRANSP:TRANSPARENT",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:0192d5b6-35a4-7c3a-9a9e-0b1a2b3c4d5e-20240308@kera",
+		"DTSTAMP:20240310T123000Z",
+		"DTSTART;VALUE=DATE:20240308",
+		"DTEND;VALUE=DATE:20240309",
+		`SUMMARY:Done: Read\, write\; repeat`,
+		"TRANSP:TRANSPARENT",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+
+	if buf.String() != expected {
+		t.Errorf("Write(), got=%q, expected=%q", buf.String(), expected)
+	}
+}
+
+func TestLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Valid: short", "SUMMARY:a", "SUMMARY:a\r\n"},
+		{
+			"Valid: exactly the limit",
+			strings.Repeat("a", maxLineLen),
+			strings.Repeat("a", maxLineLen) + "\r\n",
+		},
+		{
+			"Valid: folded",
+			strings.Repeat("a", maxLineLen+maxLineLen),
+			strings.Repeat("a", maxLineLen) + "\r\n " +
+				strings.Repeat("a", maxLineLen-1) + "\r\n a\r\n",
+		},
+		{
+			"Valid: folded before a multi-byte character",
+			strings.Repeat("a", maxLineLen-1) + "ąb",
+			strings.Repeat("a", maxLineLen-1) + "\r\n ąb\r\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := &writer{w: bufio.NewWriter(&buf)}
+			w.line(test.input)
+			w.w.Flush()
+
+			if buf.String() != test.expected {
+				t.Errorf("line(%q), got=%q, expected=%q", test.input, buf.String(), test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/zvxte/kera/export"
+	"github.com/zvxte/kera/importer"
+	"github.com/zvxte/kera/store/habitstore"
+	"github.com/zvxte/kera/store/userstore"
+)
+
+// runImport restores a kera archive into an existing account,
+// the same way as the import endpoint does.
+// The database is read from database.dsn of the config.
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	username := flags.String("username", "", "username of the account to import into")
+	onConflict := flags.String("on-conflict", "fail", "fail, skip or merge habits with existing titles")
+	dryRun := flags.Bool("dry-run", false, "report the import without changing anything")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: kera import -username USERNAME [flags] FILE")
+		flags.PrintDefaults()
+	}
+	cfg, err := loadConfig(flags, args)
+	if err != nil {
+		return err
+	}
+	if *username == "" || flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("import: username and file are required")
+	}
+
+	mode, err := importer.ParseConflictMode(*onConflict)
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+
+	archive, err := export.ReadZip(file, info.Size())
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	sqlDatabase, err := openDatabase(ctx, cfg, "import")
+	if err != nil {
+		return err
+	}
+	defer sqlDatabase.DB.Close()
+
+	userStore, err := userstore.NewSql(sqlDatabase.DB)
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+
+	habitStore, err := habitstore.NewSql(sqlDatabase.DB)
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+
+	user, err := getUser(ctx, userStore, *username)
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+
+	plan, err := importer.New(userStore, habitStore).Restore(
+		ctx, user.ID, archive, mode, *dryRun,
+	)
+	if plan != nil {
+		printPlan(plan, *dryRun)
+	}
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+
+	return nil
+}
+
+func printPlan(plan *importer.Plan, dryRun bool) {
+	if dryRun {
+		fmt.Println("dry run, nothing is imported")
+	}
+	fmt.Printf("habits created: %d\n", plan.HabitsCreated)
+	fmt.Printf("habits merged: %d\n", plan.HabitsMerged)
+	fmt.Printf("habits skipped: %d\n", plan.HabitsSkipped)
+	fmt.Printf("days imported: %d\n", plan.DaysImported)
+	if plan.DisplayName != "" {
+		fmt.Printf("display name: %s\n", plan.DisplayName)
+	}
+
+	for _, conflict := range plan.Conflicts {
+		if conflict.Date.IsZero() {
+			fmt.Printf("conflict: %q %s\n", conflict.Title, conflict.Reason)
+			continue
+		}
+		fmt.Printf(
+			"conflict: %q %s %s\n", conflict.Title,
+			time.Time(conflict.Date).Format(time.DateOnly), conflict.Reason,
+		)
+	}
+}
@@ -0,0 +1,106 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ReadCsv returns the habits read from a generic CSV file.
+//
+// The first line is a header with the habit, date and value columns
+// in any order, an optional description column, and any other columns,
+// which are ignored. Every other line is a day of a habit's history:
+//   - habit is the title of the habit
+//   - date is in the YYYY-MM-DD format
+//   - value is done if it's 1, true, yes, y, x, done or a positive number,
+//     and not done if it's empty, 0, false, no, n or a non-positive number
+//
+// The description of a habit is taken from its first non-empty description.
+// It fails with a *[ParseError] if a line is invalid.
+func ReadCsv(r io.Reader) ([]*Habit, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, &ParseError{Line: 1, Err: ErrInvalidFormat}
+	}
+
+	columns := map[string]int{"habit": -1, "date": -1, "value": -1, "description": -1}
+	for i, name := range header {
+		name = columnName(name)
+		if index, ok := columns[name]; ok && index == -1 {
+			columns[name] = i
+		}
+	}
+	if columns["habit"] == -1 || columns["date"] == -1 || columns["value"] == -1 {
+		return nil, &ParseError{Line: 1, Err: ErrInvalidFormat}
+	}
+
+	c := newCollector()
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			var csvErr *csv.ParseError
+			if errors.As(err, &csvErr) {
+				line = csvErr.Line
+			}
+			return nil, &ParseError{Line: line, Err: ErrInvalidFormat}
+		}
+
+		field := func(name string) string {
+			if i := columns[name]; i != -1 && i < len(record) {
+				return record[i]
+			}
+			return ""
+		}
+
+		h, err := c.habit(field("habit"))
+		if err != nil {
+			return nil, &ParseError{Line: line, Err: err}
+		}
+
+		if description := strings.TrimSpace(field("description")); h.Description == "" {
+			h.Description = description
+		}
+
+		d, err := parseDate(field("date"))
+		if err != nil {
+			return nil, &ParseError{Line: line, Err: err}
+		}
+
+		done, err := parseValue(field("value"))
+		if err != nil {
+			return nil, &ParseError{Line: line, Err: err}
+		}
+		if done {
+			c.setDone(h, d)
+		}
+	}
+
+	return c.result(), nil
+}
+
+// parseValue returns true if the value means a habit is done on a day.
+func parseValue(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "1", "true", "yes", "y", "x", "done":
+		return true, nil
+	case "", "0", "false", "no", "n":
+		return false, nil
+	}
+
+	number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return false, ErrInvalidValue
+	}
+	return number > 0, nil
+}
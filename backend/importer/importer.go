@@ -0,0 +1,143 @@
+// Package importer reads habits and their history from other applications,
+// and plans how to add them to the existing habits of a user.
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zvxte/kera/model/date"
+)
+
+const (
+	dateLayout = time.DateOnly
+
+	// MaxHabits is the maximum number of habits in a single import.
+	MaxHabits = 500
+)
+
+var (
+	ErrInvalidFormat = errors.New("file format is invalid")
+	ErrInvalidDate   = errors.New("date is invalid")
+	ErrInvalidValue  = errors.New("value is invalid")
+	ErrTooManyHabits = errors.New("file has too many habits")
+	ErrInvalidID     = errors.New("ID is invalid")
+)
+
+// Habit represents a habit read from an import file.
+type Habit struct {
+	Title       string
+	Description string
+	Archived    bool
+
+	// DoneDates are sorted in ascending order and unique.
+	DoneDates []date.Date
+}
+
+// ParseError represents an error at a line of an import file.
+// The error message is safe for client-side message.
+type ParseError struct {
+	File string
+	Line int
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("%s: line %d: %v", e.File, e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// HabitError represents an imported habit that does not meet
+// the application requirements.
+// The error message is safe for client-side message.
+type HabitError struct {
+	Title string
+	Err   error
+}
+
+func (e *HabitError) Error() string {
+	return fmt.Sprintf("habit %q: %v", e.Title, e.Err)
+}
+
+func (e *HabitError) Unwrap() error {
+	return e.Err
+}
+
+// collector collects habits by their title,
+// in order of their first occurrence.
+type collector struct {
+	habits []*Habit
+	titles map[string]*Habit
+	done   map[*Habit]map[int64]date.Date
+}
+
+func newCollector() *collector {
+	return &collector{
+		titles: make(map[string]*Habit),
+		done:   make(map[*Habit]map[int64]date.Date),
+	}
+}
+
+// habit returns the habit with the title, adding it if it's new.
+func (c *collector) habit(title string) (*Habit, error) {
+	title = strings.TrimSpace(title)
+	if h, ok := c.titles[title]; ok {
+		return h, nil
+	}
+
+	if len(c.habits) >= MaxHabits {
+		return nil, ErrTooManyHabits
+	}
+
+	h := &Habit{Title: title}
+	c.habits = append(c.habits, h)
+	c.titles[title] = h
+	c.done[h] = make(map[int64]date.Date)
+	return h, nil
+}
+
+func (c *collector) setDone(h *Habit, d date.Date) {
+	c.done[h][time.Time(d).Unix()] = d
+}
+
+// result returns the collected habits with sorted done dates.
+func (c *collector) result() []*Habit {
+	for _, h := range c.habits {
+		h.DoneDates = sortedDates(c.done[h])
+	}
+	return c.habits
+}
+
+// sortedDates returns the dates of the set in ascending order.
+func sortedDates(set map[int64]date.Date) []date.Date {
+	dates := make([]date.Date, 0, len(set))
+	for _, d := range set {
+		dates = append(dates, d)
+	}
+	slices.SortFunc(dates, func(a, b date.Date) int {
+		return time.Time(a).Compare(time.Time(b))
+	})
+	return dates
+}
+
+// columnName returns the normalized name of a CSV header column.
+func columnName(name string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+}
+
+func parseDate(value string) (date.Date, error) {
+	t, err := time.Parse(dateLayout, strings.TrimSpace(value))
+	if err != nil || t.Year() < 1970 {
+		return date.Date{}, ErrInvalidDate
+	}
+	return date.Load(t), nil
+}
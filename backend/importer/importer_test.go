@@ -0,0 +1,172 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/zvxte/kera/model/date"
+)
+
+func TestReadCsv(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		expected  map[string]int
+		shouldErr bool
+	}{
+		{
+			"Valid",
+			"habit,date,value\nRead,2024-03-01,1\nRead,2024-03-02,0\nRun,2024-03-01,yes\n",
+			map[string]int{"Read": 1, "Run": 1},
+			false,
+		},
+		{
+			"Valid: columns in other order",
+			"value,extra,date,habit\n2.5,x,2024-03-01,Read\n",
+			map[string]int{"Read": 1},
+			false,
+		},
+		{
+			"Valid: repeated day",
+			"Habit,Date,Value\nRead,2024-03-01,x\nRead,2024-03-01,done\n",
+			map[string]int{"Read": 1},
+			false,
+		},
+		{
+			"Valid: no done days",
+			"habit,date,value\nRead,2024-03-01,\n",
+			map[string]int{"Read": 0},
+			false,
+		},
+		{"Invalid: missing column", "habit,date\nRead,2024-03-01\n", nil, true},
+		{"Invalid: date", "habit,date,value\nRead,03/01/2024,1\n", nil, true},
+		{"Invalid: value", "habit,date,value\nRead,2024-03-01,maybe\n", nil, true},
+		{"Invalid: empty", "", nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			habits, err := ReadCsv(strings.NewReader(test.input))
+			if (err != nil) != test.shouldErr {
+				t.Fatalf(
+					"ReadCsv(%q), error=%v, shouldErr=%v",
+					test.input, err, test.shouldErr,
+				)
+			}
+			if err == nil {
+				checkDoneDates(t, habits, test.expected)
+			}
+		})
+	}
+}
+
+func TestReadLoop(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		expected  map[string]int
+		shouldErr bool
+	}{
+		{
+			"Valid",
+			"Date,Read,Run,\n2024-03-02,2,0,\n2024-03-01,1,2,\n2024-02-29,-1,2,\n",
+			map[string]int{"Read": 1, "Run": 2},
+			false,
+		},
+		{"Invalid: no habits", "Date\n2024-03-01\n", nil, true},
+		{"Invalid: date", "Date,Read,\nyesterday,2,\n", nil, true},
+		{"Invalid: value", "Date,Read,\n2024-03-01,yes,\n", nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			habits, err := ReadLoop(strings.NewReader(test.input))
+			if (err != nil) != test.shouldErr {
+				t.Fatalf(
+					"ReadLoop(%q), error=%v, shouldErr=%v",
+					test.input, err, test.shouldErr,
+				)
+			}
+			if err == nil {
+				checkDoneDates(t, habits, test.expected)
+			}
+		})
+	}
+}
+
+func TestReadLoopZip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := map[string]string{
+		"Habits.csv": "Position,Name,Type,Question,Description,Unit,Archived?\n" +
+			"001,Read,0,Did you read?,,,False\n" +
+			"002,Water,1,,Glasses of water,glasses,True\n",
+		"Checkmarks.csv":      "Date,Read,Water,\n2024-03-02,2,0,\n2024-03-01,1,3000,\n",
+		"001 Read/Scores.csv": "Date,Score\n",
+	}
+	for name, content := range files {
+		fw, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fw.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	habits, err := ReadLoopZip(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("ReadLoopZip(), error=%v", err)
+	}
+	checkDoneDates(t, habits, map[string]int{"Read": 1, "Water": 1})
+
+	for _, h := range habits {
+		switch h.Title {
+		case "Read":
+			if h.Description != "Did you read?" || h.Archived {
+				t.Errorf("ReadLoopZip(), got=%+v", *h)
+			}
+		case "Water":
+			if h.Description != "Glasses of water" || !h.Archived {
+				t.Errorf("ReadLoopZip(), got=%+v", *h)
+			}
+		}
+	}
+
+	_, err = ReadLoopZip(bytes.NewReader([]byte("not a zip")), 9)
+	if err == nil {
+		t.Errorf("ReadLoopZip(%q), error=%v, shouldErr=%v", "not a zip", err, true)
+	}
+}
+
+func checkDoneDates(t *testing.T, habits []*Habit, expected map[string]int) {
+	t.Helper()
+
+	if len(habits) != len(expected) {
+		t.Fatalf("got %d habits, expected=%v", len(habits), expected)
+	}
+	for _, h := range habits {
+		count, ok := expected[h.Title]
+		if !ok || len(h.DoneDates) != count {
+			t.Errorf("habit %q, got=%v, expected %d done dates", h.Title, h.DoneDates, count)
+		}
+		for i := 1; i < len(h.DoneDates); i++ {
+			if !h.DoneDates[i].After(h.DoneDates[i-1]) {
+				t.Errorf("habit %q, done dates are not sorted, got=%v", h.Title, h.DoneDates)
+			}
+		}
+	}
+}
+
+func TestParseDateRange(t *testing.T) {
+	if _, err := parseDate("1969-12-31"); err == nil {
+		t.Errorf("parseDate(%q), error=%v, shouldErr=%v", "1969-12-31", err, true)
+	}
+	d, err := parseDate(" 2024-03-01 ")
+	if err != nil || !d.Equal(date.New(2024, 3, 1)) {
+		t.Errorf("parseDate(%q), got=%v, error=%v", " 2024-03-01 ", d, err)
+	}
+}
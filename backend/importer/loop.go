@@ -0,0 +1,222 @@
+package importer
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"errors"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	loopHabitsFile     = "Habits.csv"
+	loopCheckmarksFile = "Checkmarks.csv"
+
+	// loopYesManual is the checkmark value of a boolean habit done by the user,
+	// other values mean it was implied by the frequency, skipped, or not done.
+	loopYesManual = 2
+)
+
+// loopHabit represents a line of the Loop Habits.csv file.
+type loopHabit struct {
+	description string
+	archived    bool
+	numerical   bool
+}
+
+// ReadLoop returns the habits read from the Checkmarks.csv file
+// of a Loop Habit Tracker CSV export.
+// All habits are treated as yes-or-no habits, see [ReadLoopZip]
+// to import numerical habits, descriptions and archived habits.
+// It fails with a *[ParseError] if a line is invalid.
+func ReadLoop(r io.Reader) ([]*Habit, error) {
+	return readLoopCheckmarks(r, nil)
+}
+
+// ReadLoopZip returns the habits read from a Loop Habit Tracker
+// CSV export archive. The history is read from Checkmarks.csv,
+// and the descriptions, archived state and type of the habits
+// from Habits.csv, if the archive has it.
+// Archived habits are imported as ended.
+// It fails with [ErrInvalidFormat] if the archive is invalid
+// or has no Checkmarks.csv, or a *[ParseError] if a line is invalid.
+func ReadLoopZip(r io.ReaderAt, size int64) ([]*Habit, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrInvalidFormat
+	}
+
+	// The archive also has a directory per habit with its own files,
+	// only the files at the top level are read.
+	var habitsFile, checkmarksFile *zip.File
+	for _, file := range archive.File {
+		if path.Dir(file.Name) != "." {
+			continue
+		}
+		switch file.Name {
+		case loopHabitsFile:
+			habitsFile = file
+		case loopCheckmarksFile:
+			checkmarksFile = file
+		}
+	}
+	if checkmarksFile == nil {
+		return nil, ErrInvalidFormat
+	}
+
+	var habits map[string]loopHabit
+	if habitsFile != nil {
+		rc, err := habitsFile.Open()
+		if err != nil {
+			return nil, ErrInvalidFormat
+		}
+		habits, err = readLoopHabits(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rc, err := checkmarksFile.Open()
+	if err != nil {
+		return nil, ErrInvalidFormat
+	}
+	defer rc.Close()
+
+	return readLoopCheckmarks(rc, habits)
+}
+
+// readLoopHabits returns the habits of the Habits.csv file by their names.
+func readLoopHabits(r io.Reader) (map[string]loopHabit, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, &ParseError{File: loopHabitsFile, Line: 1, Err: ErrInvalidFormat}
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[columnName(name)] = i
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, &ParseError{File: loopHabitsFile, Line: 1, Err: ErrInvalidFormat}
+	}
+
+	habits := make(map[string]loopHabit)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			line, _ := reader.FieldPos(0)
+			return nil, &ParseError{File: loopHabitsFile, Line: line, Err: ErrInvalidFormat}
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		description := field("description")
+		if description == "" {
+			description = field("question")
+		}
+
+		var numerical bool
+		if _, ok := columns["type"]; ok {
+			kind := strings.ToLower(field("type"))
+			numerical = kind == "1" || strings.HasPrefix(kind, "numeric")
+		} else {
+			numerical = field("unit") != ""
+		}
+
+		archived, _ := strconv.ParseBool(strings.ToLower(field("archived?")))
+
+		habits[field("name")] = loopHabit{
+			description: description,
+			archived:    archived,
+			numerical:   numerical,
+		}
+	}
+
+	return habits, nil
+}
+
+// readLoopCheckmarks returns the habits of the Checkmarks.csv file,
+// which has a date column followed by a column per habit,
+// described by the provided habits if they are known.
+func readLoopCheckmarks(r io.Reader, habits map[string]loopHabit) ([]*Habit, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil || len(header) < 2 {
+		return nil, &ParseError{File: loopCheckmarksFile, Line: 1, Err: ErrInvalidFormat}
+	}
+
+	c := newCollector()
+
+	// Loop ends every line with a comma, which adds an empty column.
+	names := header[1:]
+	if names[len(names)-1] == "" {
+		names = names[:len(names)-1]
+	}
+
+	columns := make([]*Habit, len(names))
+	infos := make([]loopHabit, len(names))
+	for i, name := range names {
+		h, err := c.habit(name)
+		if err != nil {
+			return nil, &ParseError{File: loopCheckmarksFile, Line: 1, Err: err}
+		}
+
+		info := habits[name]
+		h.Description = info.description
+		h.Archived = info.archived
+		columns[i], infos[i] = h, info
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			var csvErr *csv.ParseError
+			if errors.As(err, &csvErr) {
+				line = csvErr.Line
+			}
+			return nil, &ParseError{File: loopCheckmarksFile, Line: line, Err: ErrInvalidFormat}
+		}
+
+		d, err := parseDate(record[0])
+		if err != nil {
+			return nil, &ParseError{File: loopCheckmarksFile, Line: line, Err: err}
+		}
+
+		for i, value := range record[1:] {
+			if i >= len(columns) || strings.TrimSpace(value) == "" {
+				continue
+			}
+
+			number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				return nil, &ParseError{File: loopCheckmarksFile, Line: line, Err: ErrInvalidValue}
+			}
+
+			if (infos[i].numerical && number > 0) || number == loopYesManual {
+				c.setDone(columns[i], d)
+			}
+		}
+	}
+
+	return c.result(), nil
+}
@@ -0,0 +1,239 @@
+package importer
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/zvxte/kera/model/date"
+	"github.com/zvxte/kera/model/habit"
+	"github.com/zvxte/kera/store/habitstore"
+)
+
+var ErrInvalidConflictMode = errors.New("conflict mode is invalid")
+
+// ConflictMode represents what happens to an imported habit
+// with the same title as an existing habit.
+type ConflictMode uint8
+
+const (
+	// ConflictFail blocks the whole import.
+	ConflictFail ConflictMode = iota
+
+	// ConflictSkip skips the imported habit.
+	ConflictSkip
+
+	// ConflictMerge adds the done days of the imported habit
+	// to the existing habit.
+	ConflictMerge
+)
+
+func (m ConflictMode) String() string {
+	switch m {
+	case ConflictFail:
+		return "fail"
+	case ConflictSkip:
+		return "skip"
+	case ConflictMerge:
+		return "merge"
+	default:
+		return ""
+	}
+}
+
+// ParseConflictMode returns the ConflictMode of the provided name,
+// an empty name is [ConflictFail].
+// It fails with [ErrInvalidConflictMode] if the name is unknown.
+func ParseConflictMode(name string) (ConflictMode, error) {
+	switch name {
+	case "", "fail":
+		return ConflictFail, nil
+	case "skip":
+		return ConflictSkip, nil
+	case "merge":
+		return ConflictMerge, nil
+	default:
+		return 0, ErrInvalidConflictMode
+	}
+}
+
+// Reason represents why a part of an import is left out or changed.
+type Reason uint8
+
+const (
+	// HabitExists means a habit with the same title already exists.
+	HabitExists Reason = iota
+
+	// DayInFuture means a done day is after today and is skipped.
+	DayInFuture
+
+	// DayNotTracked means a done day is not tracked by the existing habit
+	// it's merged into and is skipped.
+	DayNotTracked
+
+	// DescriptionDropped means the description does not meet
+	// the application requirements, the habit is imported without it.
+	DescriptionDropped
+)
+
+func (r Reason) String() string {
+	switch r {
+	case HabitExists:
+		return "habit_exists"
+	case DayInFuture:
+		return "day_in_future"
+	case DayNotTracked:
+		return "day_not_tracked"
+	case DescriptionDropped:
+		return "description_dropped"
+	default:
+		return ""
+	}
+}
+
+// Conflict represents a part of an import that is left out or changed.
+// The date is zero if the conflict concerns the whole habit.
+type Conflict struct {
+	Title  string
+	Date   date.Date
+	Reason Reason
+}
+
+// Plan represents the changes of an import.
+type Plan struct {
+	Imports   []habitstore.Import
+	Conflicts []Conflict
+
+	HabitsCreated int
+	HabitsMerged  int
+	HabitsSkipped int
+	DaysImported  int
+
+	// Blocked is true if the import must not be done,
+	// because habits exist and the mode is [ConflictFail].
+	Blocked bool
+
+	// DisplayName is the display name restored from a kera archive,
+	// it's empty if the display name does not change.
+	DisplayName string
+}
+
+// NewPlan returns the plan to import the habits next to the existing ones.
+// New habits track every day of the week, start at their first done day
+// and archived ones end at their last done day.
+// Habits are matched to the existing ones by their title, ignoring case.
+// It fails with a *[HabitError] if a title does not meet
+// the application requirements.
+func NewPlan(
+	habits []*Habit, existing []*habit.Habit, mode ConflictMode, today date.Date,
+) (*Plan, error) {
+	titles := make(map[string]*habit.Habit, len(existing))
+	for _, h := range existing {
+		titles[strings.ToLower(h.Title)] = h
+	}
+
+	plan := &Plan{}
+
+	for _, imported := range habits {
+		doneDates := plan.pastDates(imported.Title, imported.DoneDates, today)
+
+		if match, ok := titles[strings.ToLower(imported.Title)]; ok {
+			plan.conflict(imported.Title, date.Date{}, HabitExists)
+
+			if mode != ConflictMerge {
+				plan.Blocked = plan.Blocked || mode == ConflictFail
+				plan.HabitsSkipped++
+				continue
+			}
+
+			plan.merge(imported.Title, match, doneDates)
+			continue
+		}
+
+		h, err := newHabit(plan, imported, doneDates, today)
+		if err != nil {
+			return nil, err
+		}
+		titles[strings.ToLower(h.Title)] = h
+
+		plan.Imports = append(plan.Imports, habitstore.Import{
+			Habit: h, DoneDates: doneDates,
+		})
+		plan.HabitsCreated++
+		plan.DaysImported += len(doneDates)
+	}
+
+	return plan, nil
+}
+
+func newHabit(
+	plan *Plan, imported *Habit, doneDates []date.Date, today date.Date,
+) (*habit.Habit, error) {
+	description := imported.Description
+	if habit.ValidateDescription(description) != nil {
+		plan.conflict(imported.Title, date.Date{}, DescriptionDropped)
+		description = ""
+	}
+
+	h, err := habit.New(
+		imported.Title, description,
+		habit.Monday, habit.Tuesday, habit.Wednesday, habit.Thursday,
+		habit.Friday, habit.Saturday, habit.Sunday,
+	)
+	if err != nil {
+		return nil, &HabitError{Title: imported.Title, Err: err}
+	}
+
+	h.StartDate = today
+	if len(doneDates) > 0 {
+		h.StartDate = doneDates[0]
+	}
+
+	if imported.Archived {
+		h.Status = habit.Ended
+		h.EndDate = h.StartDate
+		if len(doneDates) > 0 {
+			h.EndDate = doneDates[len(doneDates)-1]
+		}
+	}
+
+	return h, nil
+}
+
+// merge adds the import of the done dates into the existing habit,
+// skipping the days the habit does not track.
+func (p *Plan) merge(title string, existing *habit.Habit, doneDates []date.Date) {
+	var tracked []date.Date
+	for _, d := range doneDates {
+		if d.Before(existing.StartDate) ||
+			(!existing.EndDate.IsZero() && d.After(existing.EndDate)) ||
+			!existing.TrackedWeekDays.Tracked(habit.WeekDay(d.WeekDay())) {
+			p.conflict(title, d, DayNotTracked)
+			continue
+		}
+		tracked = append(tracked, d)
+	}
+
+	p.Imports = append(p.Imports, habitstore.Import{
+		Habit: existing, Existing: true, DoneDates: tracked,
+	})
+	p.HabitsMerged++
+	p.DaysImported += len(tracked)
+}
+
+// pastDates returns the dates up to today,
+// later dates are reported as conflicts.
+func (p *Plan) pastDates(title string, dates []date.Date, today date.Date) []date.Date {
+	var past []date.Date
+	for _, d := range dates {
+		if d.After(today) {
+			p.conflict(title, d, DayInFuture)
+			continue
+		}
+		past = append(past, d)
+	}
+	return past
+}
+
+func (p *Plan) conflict(title string, d date.Date, reason Reason) {
+	p.Conflicts = append(p.Conflicts, Conflict{Title: title, Date: d, Reason: reason})
+}
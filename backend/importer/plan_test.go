@@ -0,0 +1,146 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zvxte/kera/model/date"
+	"github.com/zvxte/kera/model/habit"
+)
+
+func TestNewPlan(t *testing.T) {
+	today := date.New(2024, time.March, 10)
+
+	// Tracked on Mondays only, since Monday, March 4.
+	existing, err := habit.New("Read", "", habit.Monday)
+	if err != nil {
+		t.Fatal(err)
+	}
+	existing.StartDate = date.New(2024, time.March, 4)
+
+	read := &Habit{
+		Title: "read",
+		DoneDates: []date.Date{
+			date.New(2024, time.February, 26),
+			date.New(2024, time.March, 4),
+			date.New(2024, time.March, 5),
+		},
+	}
+	run := &Habit{
+		Title:    "Run",
+		Archived: true,
+		DoneDates: []date.Date{
+			date.New(2024, time.March, 1),
+			date.New(2024, time.March, 3),
+			date.New(2024, time.March, 11),
+		},
+	}
+
+	tests := []struct {
+		name            string
+		habits          []*Habit
+		mode            ConflictMode
+		expectedBlocked bool
+		expectedCreated int
+		expectedMerged  int
+		expectedDays    int
+		expectedReasons []Reason
+		shouldErr       bool
+	}{
+		{
+			"Valid: fail",
+			[]*Habit{read, run}, ConflictFail,
+			true, 1, 0, 2,
+			[]Reason{HabitExists, DayInFuture},
+			false,
+		},
+		{
+			"Valid: skip",
+			[]*Habit{read, run}, ConflictSkip,
+			false, 1, 0, 2,
+			[]Reason{HabitExists, DayInFuture},
+			false,
+		},
+		{
+			"Valid: merge",
+			[]*Habit{read, run}, ConflictMerge,
+			false, 1, 1, 3,
+			[]Reason{HabitExists, DayNotTracked, DayNotTracked, DayInFuture},
+			false,
+		},
+		{
+			"Valid: description dropped",
+			[]*Habit{{Title: "Walk", Description: strings.Repeat("a", 300)}}, ConflictFail,
+			false, 1, 0, 0,
+			[]Reason{DescriptionDropped},
+			false,
+		},
+		{
+			"Invalid: title",
+			[]*Habit{{Title: "a"}}, ConflictFail,
+			false, 0, 0, 0, nil,
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			plan, err := NewPlan(test.habits, []*habit.Habit{existing}, test.mode, today)
+			if (err != nil) != test.shouldErr {
+				t.Fatalf(
+					"NewPlan(%v), error=%v, shouldErr=%v",
+					test.mode, err, test.shouldErr,
+				)
+			}
+			if err != nil {
+				return
+			}
+
+			if plan.Blocked != test.expectedBlocked ||
+				plan.HabitsCreated != test.expectedCreated ||
+				plan.HabitsMerged != test.expectedMerged ||
+				plan.DaysImported != test.expectedDays {
+				t.Errorf("NewPlan(%v), got=%+v", test.mode, *plan)
+			}
+
+			reasons := make([]Reason, len(plan.Conflicts))
+			for i, conflict := range plan.Conflicts {
+				reasons[i] = conflict.Reason
+			}
+			if len(reasons) != len(test.expectedReasons) {
+				t.Fatalf("NewPlan(%v), got=%v, expected=%v", test.mode, reasons, test.expectedReasons)
+			}
+			for i := range reasons {
+				if reasons[i] != test.expectedReasons[i] {
+					t.Errorf("NewPlan(%v), got=%v, expected=%v", test.mode, reasons, test.expectedReasons)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestNewPlanArchived(t *testing.T) {
+	today := date.New(2024, time.March, 10)
+	habits := []*Habit{{
+		Title:    "Run",
+		Archived: true,
+		DoneDates: []date.Date{
+			date.New(2024, time.March, 1),
+			date.New(2024, time.March, 3),
+		},
+	}}
+
+	plan, err := NewPlan(habits, nil, ConflictFail, today)
+	if err != nil {
+		t.Fatalf("NewPlan(), error=%v", err)
+	}
+
+	h := plan.Imports[0].Habit
+	if h.Status != habit.Ended ||
+		!h.StartDate.Equal(date.New(2024, time.March, 1)) ||
+		!h.EndDate.Equal(date.New(2024, time.March, 3)) {
+		t.Errorf("NewPlan(), got=%+v", *h)
+	}
+}
@@ -0,0 +1,151 @@
+package importer
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/zvxte/kera/export"
+	"github.com/zvxte/kera/model/date"
+	"github.com/zvxte/kera/model/habit"
+	"github.com/zvxte/kera/model/uuid"
+	"github.com/zvxte/kera/store/habitstore"
+)
+
+var (
+	ErrInvalidStatus  = errors.New("status is invalid")
+	ErrInvalidWeekDay = errors.New("day of the week is invalid")
+)
+
+// RestoredID returns the ID of a habit restored from a kera archive
+// into the account of the user. It's derived from the user's ID
+// and the habit's ID in the archive, so restoring the same archive
+// again results in the same IDs.
+func RestoredID(userID, sourceID uuid.UUID) uuid.UUID {
+	return uuid.NewV5(userID, sourceID[:])
+}
+
+// NewRestorePlan returns the plan to restore the habits of a kera archive
+// into the account of the user, next to its existing habits.
+// Habits previously restored from the archive, or habits the archive
+// was exported from, are merged, so restoring is idempotent.
+// Other habits with the same title are conflicts handled by the mode.
+// The habits are validated as if they were loaded from the store,
+// only the done days of their history are restored.
+// It fails with a *[HabitError] if a habit of the archive is invalid.
+func NewRestorePlan(
+	archive *export.Export, existing []*habit.Habit, userID uuid.UUID,
+	mode ConflictMode, today date.Date,
+) (*Plan, error) {
+	ids := make(map[uuid.UUID]*habit.Habit, len(existing))
+	titles := make(map[string]*habit.Habit, len(existing))
+	for _, h := range existing {
+		ids[h.ID] = h
+		titles[strings.ToLower(h.Title)] = h
+	}
+
+	plan := &Plan{}
+
+	for _, source := range archive.Habits {
+		restored, doneDates, err := loadHabit(source, userID)
+		if err != nil {
+			return nil, &HabitError{Title: source.Title, Err: err}
+		}
+		doneDates = plan.pastDates(restored.Title, doneDates, today)
+
+		sourceID, _ := uuid.Parse(source.ID)
+		match, ok := ids[restored.ID]
+		if !ok {
+			match, ok = ids[sourceID]
+		}
+		if ok {
+			plan.merge(restored.Title, match, doneDates)
+			continue
+		}
+
+		if match, ok := titles[strings.ToLower(restored.Title)]; ok {
+			plan.conflict(restored.Title, date.Date{}, HabitExists)
+
+			if mode != ConflictMerge {
+				plan.Blocked = plan.Blocked || mode == ConflictFail
+				plan.HabitsSkipped++
+				continue
+			}
+
+			plan.merge(restored.Title, match, doneDates)
+			continue
+		}
+
+		ids[restored.ID] = restored
+		titles[strings.ToLower(restored.Title)] = restored
+
+		plan.Imports = append(plan.Imports, habitstore.Import{
+			Habit: restored, DoneDates: doneDates,
+		})
+		plan.HabitsCreated++
+		plan.DaysImported += len(doneDates)
+	}
+
+	return plan, nil
+}
+
+// loadHabit returns the habit of the archive with its restored ID,
+// and its done dates.
+func loadHabit(source export.Habit, userID uuid.UUID) (*habit.Habit, []date.Date, error) {
+	sourceID, err := uuid.Parse(source.ID)
+	if err != nil {
+		return nil, nil, ErrInvalidID
+	}
+
+	var status habit.Status
+	switch source.Status {
+	case habit.Active.String():
+		status = habit.Active
+	case habit.Ended.String():
+		status = habit.Ended
+	default:
+		return nil, nil, ErrInvalidStatus
+	}
+
+	var trackedWeekDays habit.TrackedWeekDays
+	for _, name := range source.WeekDays {
+		day, ok := parseWeekDay(name)
+		if !ok {
+			return nil, nil, ErrInvalidWeekDay
+		}
+		trackedWeekDays |= 1 << day
+	}
+
+	var endDate date.Date
+	if source.EndDate != nil {
+		endDate = date.Load(*source.EndDate)
+	}
+
+	h, err := habit.Load(
+		RestoredID(userID, sourceID), status, source.Title, source.Description,
+		trackedWeekDays, date.Load(source.StartDate), endDate,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	done := make(map[int64]date.Date)
+	for _, day := range source.History {
+		if day.Status == habit.DayDone.String() {
+			d := date.Load(day.Date)
+			done[time.Time(d).Unix()] = d
+		}
+	}
+	doneDates := sortedDates(done)
+
+	return h, doneDates, nil
+}
+
+func parseWeekDay(name string) (habit.WeekDay, bool) {
+	for day := habit.Monday; day <= habit.Sunday; day++ {
+		if day.String() == name {
+			return day, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,97 @@
+package importer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zvxte/kera/export"
+	"github.com/zvxte/kera/model/date"
+	"github.com/zvxte/kera/model/habit"
+	"github.com/zvxte/kera/model/uuid"
+)
+
+func TestNewRestorePlan(t *testing.T) {
+	today := date.New(2024, time.March, 10)
+	userID, err := uuid.NewV7()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	valid := export.Habit{
+		ID:        "0192d5b6-35a4-7c3a-9a9e-0b1a2b3c4d5f",
+		Title:     "Read",
+		Status:    "active",
+		WeekDays:  []string{"monday", "friday"},
+		StartDate: start,
+		History: []export.Day{
+			{Date: start, Status: "done"},
+			{Date: start.AddDate(0, 0, 3), Status: "missed"},
+			{Date: start.AddDate(0, 0, 7), Status: "done"},
+			{Date: start.AddDate(0, 0, 14), Status: "done"},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		habit     func(export.Habit) export.Habit
+		shouldErr bool
+	}{
+		{"Valid", func(h export.Habit) export.Habit { return h }, false},
+		{"Invalid: ID", func(h export.Habit) export.Habit { h.ID = "1"; return h }, true},
+		{"Invalid: title", func(h export.Habit) export.Habit { h.Title = " a"; return h }, true},
+		{"Invalid: status", func(h export.Habit) export.Habit { h.Status = "paused"; return h }, true},
+		{"Invalid: week day", func(h export.Habit) export.Habit { h.WeekDays = []string{"mon"}; return h }, true},
+		{"Invalid: no week days", func(h export.Habit) export.Habit { h.WeekDays = nil; return h }, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			archive := &export.Export{Habits: []export.Habit{test.habit(valid)}}
+			_, err := NewRestorePlan(archive, nil, userID, ConflictFail, today)
+			if (err != nil) != test.shouldErr {
+				t.Errorf("NewRestorePlan(), error=%v, shouldErr=%v", err, test.shouldErr)
+			}
+		})
+	}
+
+	archive := &export.Export{Habits: []export.Habit{valid}}
+
+	first, err := NewRestorePlan(archive, nil, userID, ConflictFail, today)
+	if err != nil {
+		t.Fatalf("NewRestorePlan(), error=%v", err)
+	}
+	if first.HabitsCreated != 1 || first.DaysImported != 2 || len(first.Conflicts) != 1 {
+		t.Fatalf("NewRestorePlan(), got=%+v", *first)
+	}
+
+	restored := first.Imports[0].Habit
+	sourceID, _ := uuid.Parse(valid.ID)
+	if restored.ID != RestoredID(userID, sourceID) || restored.ID == sourceID {
+		t.Errorf("NewRestorePlan(), ID is not remapped, got=%v", restored.ID)
+	}
+
+	// Restoring again merges into the restored habit, even if it was renamed.
+	renamed := *restored
+	renamed.Title = "Read books"
+	second, err := NewRestorePlan(archive, []*habit.Habit{&renamed}, userID, ConflictFail, today)
+	if err != nil {
+		t.Fatalf("NewRestorePlan(), error=%v", err)
+	}
+	if second.Blocked || second.HabitsCreated != 0 || second.HabitsMerged != 1 {
+		t.Errorf("NewRestorePlan(), restoring again, got=%+v", *second)
+	}
+
+	// A different habit with the same title is a conflict.
+	other, err := habit.New("read", "", habit.Monday)
+	if err != nil {
+		t.Fatal(err)
+	}
+	third, err := NewRestorePlan(archive, []*habit.Habit{other}, userID, ConflictFail, today)
+	if err != nil {
+		t.Fatalf("NewRestorePlan(), error=%v", err)
+	}
+	if !third.Blocked || third.HabitsSkipped != 1 {
+		t.Errorf("NewRestorePlan(), existing title, got=%+v", *third)
+	}
+}
@@ -0,0 +1,105 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/zvxte/kera/export"
+	"github.com/zvxte/kera/model/date"
+	"github.com/zvxte/kera/model/user"
+	"github.com/zvxte/kera/model/uuid"
+	"github.com/zvxte/kera/store/habitstore"
+	"github.com/zvxte/kera/store/userstore"
+)
+
+var (
+	ErrBlocked      = errors.New("imported habits already exist")
+	ErrUserNotFound = errors.New("import: user not found")
+)
+
+// Importer imports into the stores,
+// so it works with any store implementation.
+type Importer struct {
+	userStore  userstore.Store
+	habitStore habitstore.Store
+}
+
+// New returns a new *Importer.
+func New(userStore userstore.Store, habitStore habitstore.Store) *Importer {
+	return &Importer{userStore: userStore, habitStore: habitStore}
+}
+
+// Import imports the habits into the account of the user,
+// as planned by [NewPlan]. With dryRun, only the plan is returned.
+// It fails with [ErrBlocked] if the plan is blocked,
+// an error of [NewPlan], or if there is a connection issue.
+func (i *Importer) Import(
+	ctx context.Context, userID uuid.UUID, habits []*Habit,
+	mode ConflictMode, dryRun bool,
+) (*Plan, error) {
+	existing, err := i.habitStore.GetAll(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import habits: %w", err)
+	}
+
+	plan, err := NewPlan(habits, existing, mode, date.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return plan, i.run(ctx, userID, plan, dryRun)
+}
+
+// Restore restores the kera archive into the account of the user,
+// as planned by [NewRestorePlan], including the display name if it's valid.
+// The display name is set in the same transaction as the habits.
+// With dryRun, only the plan is returned.
+// It fails with [ErrUserNotFound] if the user does not exist,
+// [ErrBlocked] if the plan is blocked, an error of [NewRestorePlan],
+// or if there is a connection issue.
+func (i *Importer) Restore(
+	ctx context.Context, userID uuid.UUID, archive *export.Export,
+	mode ConflictMode, dryRun bool,
+) (*Plan, error) {
+	u, err := i.userStore.Get(ctx, userstore.IDColumn, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore archive: %w", err)
+	}
+	if u == nil {
+		return nil, ErrUserNotFound
+	}
+
+	existing, err := i.habitStore.GetAll(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore archive: %w", err)
+	}
+
+	plan, err := NewRestorePlan(archive, existing, userID, mode, date.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	displayName := archive.Profile.DisplayName
+	if displayName != u.DisplayName && user.ValidateDisplayName(displayName) == nil {
+		plan.DisplayName = displayName
+	}
+
+	return plan, i.run(ctx, userID, plan, dryRun)
+}
+
+func (i *Importer) run(
+	ctx context.Context, userID uuid.UUID, plan *Plan, dryRun bool,
+) error {
+	if dryRun {
+		return nil
+	}
+	if plan.Blocked {
+		return ErrBlocked
+	}
+
+	if err := i.habitStore.Import(ctx, plan.Imports, userID, plan.DisplayName); err != nil {
+		return fmt.Errorf("failed to import habits: %w", err)
+	}
+	return nil
+}
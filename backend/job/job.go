@@ -0,0 +1,35 @@
+// Package job provides a runner for periodic background jobs
+// executed inside the server process.
+package job
+
+import (
+	"context"
+	"time"
+)
+
+// Job represents a background task run periodically by a [Runner].
+type Job interface {
+	// Name returns a short identifier of the job used in logs and stats.
+	Name() string
+
+	// Run executes a single run of the job.
+	// It should return early if the ctx is done.
+	Run(ctx context.Context) (Result, error)
+}
+
+// Result represents what a single run of a job did.
+type Result struct {
+	// Affected is the number of records processed by the run.
+	Affected uint
+}
+
+// Stats represents accumulated results of a job.
+type Stats struct {
+	Name         string
+	Runs         uint
+	Failures     uint
+	Affected     uint
+	LastRunTime  time.Time
+	LastDuration time.Duration
+	LastErr      error
+}
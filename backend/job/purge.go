@@ -0,0 +1,56 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DefaultBatchSize represents the number of records deleted in a single query.
+const DefaultBatchSize = 1000
+
+// Purger is implemented by stores of records that expire,
+// such as [sessionstore.Store] and [tokenstore.Store].
+type Purger interface {
+	// DeleteExpired deletes up to limit records
+	// with expiration time at or before the provided time.
+	// It returns the number of deleted records.
+	DeleteExpired(ctx context.Context, now time.Time, limit uint) (uint, error)
+}
+
+// Purge represents a job that deletes expired records in batches.
+type Purge struct {
+	name      string
+	purger    Purger
+	batchSize uint
+}
+
+// NewPurge returns a new *Purge with the provided name.
+// A zero batch size falls back to [DefaultBatchSize],
+// as no record could be deleted otherwise.
+func NewPurge(name string, purger Purger, batchSize uint) *Purge {
+	if batchSize == 0 {
+		batchSize = DefaultBatchSize
+	}
+	return &Purge{name: name, purger: purger, batchSize: batchSize}
+}
+
+func (j *Purge) Name() string {
+	return j.name
+}
+
+func (j *Purge) Run(ctx context.Context) (Result, error) {
+	var result Result
+	now := time.Now().UTC()
+
+	for {
+		deleted, err := j.purger.DeleteExpired(ctx, now, j.batchSize)
+		result.Affected += deleted
+		if err != nil {
+			return result, fmt.Errorf("failed to purge %s: %w", j.name, err)
+		}
+		if deleted < j.batchSize {
+			return result, nil
+		}
+	}
+}
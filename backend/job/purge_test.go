@@ -0,0 +1,61 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakePurger struct {
+	expired uint
+	err     error
+}
+
+func (s *fakePurger) DeleteExpired(
+	ctx context.Context, now time.Time, limit uint,
+) (uint, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	deleted := min(s.expired, limit)
+	s.expired -= deleted
+	return deleted, nil
+}
+
+func TestPurge(t *testing.T) {
+	tests := []struct {
+		name      string
+		expired   uint
+		batchSize uint
+		err       error
+		expected  uint
+		shouldErr bool
+	}{
+		{"Valid: nothing to purge", 0, 10, nil, 0, false},
+		{"Valid: single batch", 5, 10, nil, 5, false},
+		{"Valid: multiple batches", 25, 10, nil, 25, false},
+		{"Valid: exact batches", 20, 10, nil, 20, false},
+		{"Valid: zero batch size", 2500, 0, nil, 2500, false},
+		{"Invalid: store error", 5, 10, errors.New("connection issue"), 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			purger := &fakePurger{expired: test.expired, err: test.err}
+			result, err := NewPurge("sessions", purger, test.batchSize).Run(context.Background())
+			if (err != nil) != test.shouldErr {
+				t.Errorf(
+					"Purge.Run(), error=%v, shouldErr=%v",
+					err, test.shouldErr,
+				)
+			}
+			if result.Affected != test.expected {
+				t.Errorf(
+					"Purge.Run(), got=%d, expected=%d",
+					result.Affected, test.expected,
+				)
+			}
+		})
+	}
+}
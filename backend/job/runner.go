@@ -0,0 +1,193 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+var (
+	ErrInvalidInterval = errors.New(
+		"job interval is invalid: it must be positive and jitter must not be negative",
+	)
+	ErrNotRunning = errors.New("job runner is not running")
+	ErrStalled    = errors.New("job runner is stalled: jobs did not finish in time")
+)
+
+// Runner runs jobs periodically in a single background goroutine.
+// Each run waits for the interval plus a random duration up to the jitter,
+// so multiple server instances don't hit the database at the same moment.
+type Runner struct {
+	interval time.Duration
+	jitter   time.Duration
+	jobs     []Job
+	logger   *slog.Logger
+
+	mu      sync.Mutex
+	stats   map[string]*Stats
+	running bool
+	nextRun time.Time
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewRunner returns a new *Runner.
+// It fails if the interval is not positive or the jitter is negative.
+func NewRunner(
+	interval, jitter time.Duration, logger *slog.Logger, jobs ...Job,
+) (*Runner, error) {
+	if interval <= 0 || jitter < 0 {
+		return nil, ErrInvalidInterval
+	}
+
+	stats := make(map[string]*Stats, len(jobs))
+	for _, job := range jobs {
+		stats[job.Name()] = &Stats{Name: job.Name()}
+	}
+
+	return &Runner{
+		interval: interval,
+		jitter:   jitter,
+		jobs:     jobs,
+		logger:   logger,
+		stats:    stats,
+	}, nil
+}
+
+// Start starts running the jobs in a background goroutine.
+// It must be called at most once.
+func (r *Runner) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+
+	r.mu.Lock()
+	r.running = true
+	r.mu.Unlock()
+	delay := r.schedule()
+
+	go func() {
+		defer close(r.done)
+		defer func() {
+			r.mu.Lock()
+			r.running = false
+			r.mu.Unlock()
+		}()
+
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+				r.RunAll(ctx)
+				timer.Reset(r.schedule())
+			}
+		}
+	}()
+}
+
+// Stop cancels a run in progress and waits for the background goroutine
+// to return. It's a no-op if the runner was never started.
+func (r *Runner) Stop() {
+	if r.cancel == nil {
+		return
+	}
+	r.cancel()
+	<-r.done
+}
+
+// RunAll runs every job once, one after another.
+// It stops early if the ctx is done.
+func (r *Runner) RunAll(ctx context.Context) {
+	for _, job := range r.jobs {
+		if ctx.Err() != nil {
+			return
+		}
+		r.run(ctx, job)
+	}
+}
+
+// Check returns nil if the runner is running and its jobs finish in time.
+// It returns [ErrNotRunning] if the runner is not started or stopped,
+// and [ErrStalled] if the jobs are still running an interval after
+// they were scheduled.
+func (r *Runner) Check(now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.running {
+		return ErrNotRunning
+	}
+	if now.After(r.nextRun.Add(r.interval)) {
+		return ErrStalled
+	}
+	return nil
+}
+
+// Stats returns a snapshot of accumulated stats of every job,
+// in the order the jobs were provided.
+func (r *Runner) Stats() []Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := make([]Stats, len(r.jobs))
+	for i, job := range r.jobs {
+		stats[i] = *r.stats[job.Name()]
+	}
+	return stats
+}
+
+func (r *Runner) run(ctx context.Context, job Job) {
+	start := time.Now()
+	result, err := job.Run(ctx)
+	duration := time.Since(start)
+
+	r.mu.Lock()
+	stats := r.stats[job.Name()]
+	stats.Runs++
+	stats.Affected += result.Affected
+	stats.LastRunTime = start
+	stats.LastDuration = duration
+	stats.LastErr = err
+	if err != nil {
+		stats.Failures++
+	}
+	r.mu.Unlock()
+
+	if err != nil {
+		r.logger.Error(
+			"job failed", "job", job.Name(), "duration", duration,
+			"affected", result.Affected, "error", err,
+		)
+		return
+	}
+	r.logger.Info(
+		"job finished", "job", job.Name(), "duration", duration,
+		"affected", result.Affected,
+	)
+}
+
+// schedule returns the delay of the next run and records when it's due.
+func (r *Runner) schedule() time.Duration {
+	delay := r.nextDelay()
+
+	r.mu.Lock()
+	r.nextRun = time.Now().Add(delay)
+	r.mu.Unlock()
+
+	return delay
+}
+
+func (r *Runner) nextDelay() time.Duration {
+	if r.jitter == 0 {
+		return r.interval
+	}
+	return r.interval + rand.N(r.jitter)
+}
@@ -0,0 +1,140 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+type fakeJob struct {
+	name     string
+	affected uint
+	err      error
+	runs     chan struct{}
+}
+
+func (j *fakeJob) Name() string {
+	return j.name
+}
+
+func (j *fakeJob) Run(ctx context.Context) (Result, error) {
+	if j.runs != nil {
+		select {
+		case j.runs <- struct{}{}:
+		default:
+		}
+	}
+	return Result{Affected: j.affected}, j.err
+}
+
+func TestNewRunner(t *testing.T) {
+	tests := []struct {
+		name      string
+		interval  time.Duration
+		jitter    time.Duration
+		shouldErr bool
+	}{
+		{"Valid", time.Hour, time.Minute, false},
+		{"Valid: no jitter", time.Hour, 0, false},
+		{"Invalid: zero interval", 0, time.Minute, true},
+		{"Invalid: negative jitter", time.Hour, -time.Minute, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewRunner(test.interval, test.jitter, slog.New(slog.NewTextHandler(io.Discard, nil)))
+			if (err != nil) != test.shouldErr {
+				t.Errorf(
+					"NewRunner(%v, %v), error=%v, shouldErr=%v",
+					test.interval, test.jitter, err, test.shouldErr,
+				)
+			}
+		})
+	}
+}
+
+func TestRunAll(t *testing.T) {
+	errJob := errors.New("job failed")
+	runner, err := NewRunner(
+		time.Hour, 0, slog.New(slog.NewTextHandler(io.Discard, nil)),
+		&fakeJob{name: "ok", affected: 3},
+		&fakeJob{name: "failing", affected: 1, err: errJob},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runner.RunAll(context.Background())
+	runner.RunAll(context.Background())
+
+	stats := runner.Stats()
+	if len(stats) != 2 {
+		t.Fatalf("Stats(), got=%d jobs, expected=2", len(stats))
+	}
+
+	if stats[0].Runs != 2 || stats[0].Affected != 6 || stats[0].Failures != 0 {
+		t.Errorf("Stats()[0], got=%+v", stats[0])
+	}
+	if stats[1].Runs != 2 || stats[1].Failures != 2 || stats[1].LastErr != errJob {
+		t.Errorf("Stats()[1], got=%+v", stats[1])
+	}
+}
+
+func TestStartStop(t *testing.T) {
+	job := &fakeJob{name: "ok", runs: make(chan struct{}, 1)}
+	runner, err := NewRunner(
+		time.Millisecond, time.Millisecond, slog.New(slog.NewTextHandler(io.Discard, nil)), job,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runner.Start()
+
+	select {
+	case <-job.runs:
+	case <-time.After(time.Second):
+		t.Error("Start(), job did not run")
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		runner.Stop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Error("Stop(), runner did not stop")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	runner, err := NewRunner(
+		time.Hour, 0, slog.New(slog.NewTextHandler(io.Discard, nil)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runner.Check(time.Now()); err != ErrNotRunning {
+		t.Errorf("Check(), before Start(), error=%v, expected=%v", err, ErrNotRunning)
+	}
+
+	runner.Start()
+	if err := runner.Check(time.Now()); err != nil {
+		t.Errorf("Check(), after Start(), error=%v, expected=nil", err)
+	}
+	if err := runner.Check(time.Now().Add(3 * time.Hour)); err != ErrStalled {
+		t.Errorf("Check(), past the next run, error=%v, expected=%v", err, ErrStalled)
+	}
+
+	runner.Stop()
+	if err := runner.Check(time.Now()); err != ErrNotRunning {
+		t.Errorf("Check(), after Stop(), error=%v, expected=%v", err, ErrNotRunning)
+	}
+}
@@ -0,0 +1,104 @@
+// Package logging creates the structured logger of kera,
+// and carries request-scoped loggers in contexts.
+//
+// Attributes named after secrets, like password or session_id,
+// are redacted by the logger, whatever their value is.
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+var (
+	ErrInvalidLevel  = errors.New("log level is invalid: it must be debug, info, warn or error")
+	ErrInvalidFormat = errors.New("log format is invalid: it must be json or text")
+)
+
+const redacted = "<redacted>"
+
+// sensitiveKeys are the attribute keys whose values are redacted.
+// Keys ending with _ and one of them are redacted too, like new_password.
+var sensitiveKeys = []string{
+	"password", "session_id", "token", "secret",
+	"authorization", "cookie", "dsn", "peppers",
+}
+
+type contextKey struct{}
+
+// New returns a *slog.Logger writing to w at the level,
+// as JSON or text depending on the format.
+// It fails if the level or the format is invalid.
+func New(w io.Writer, level, format string) (*slog.Logger, error) {
+	l, err := ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+
+	options := &slog.HandlerOptions{Level: l, ReplaceAttr: redact}
+
+	switch format {
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, options)), nil
+	case "text":
+		return slog.New(slog.NewTextHandler(w, options)), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidFormat, format)
+	}
+}
+
+// ParseLevel returns the slog.Level of its name.
+// It fails if the name is not debug, info, warn or error.
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrInvalidLevel, name)
+	}
+}
+
+// redact replaces the value of sensitive attributes.
+func redact(groups []string, a slog.Attr) slog.Attr {
+	if Sensitive(a.Key) {
+		return slog.String(a.Key, redacted)
+	}
+	return a
+}
+
+// Sensitive returns true if the value of an attribute
+// with the key must not be logged.
+func Sensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if key == sensitive || strings.HasSuffix(key, "_"+sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithLogger returns a copy of the context carrying the logger.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger carried by the context,
+// or slog.Default() if there is none.
+func FromContext(ctx context.Context) *slog.Logger {
+	logger, ok := ctx.Value(contextKey{}).(*slog.Logger)
+	if !ok {
+		return slog.Default()
+	}
+	return logger
+}
@@ -0,0 +1,123 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name      string
+		level     string
+		format    string
+		shouldErr bool
+	}{
+		{"Valid: json", "info", "json", false},
+		{"Valid: text", "debug", "text", false},
+		{"Valid: upper case level", "WARN", "json", false},
+		{"Invalid: level", "verbose", "json", true},
+		{"Invalid: format", "info", "xml", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := New(&bytes.Buffer{}, test.level, test.format)
+			if (err != nil) != test.shouldErr {
+				t.Errorf(
+					"New(%q, %q), error=%v, shouldErr=%v",
+					test.level, test.format, err, test.shouldErr,
+				)
+			}
+		})
+	}
+}
+
+func TestNewLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "warn", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Info("hidden")
+	logger.Warn("shown")
+
+	if strings.Contains(buf.String(), "hidden") || !strings.Contains(buf.String(), "shown") {
+		t.Errorf("New(%q), got=%s", "warn", buf.String())
+	}
+}
+
+func TestRedact(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "info", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.With("session_id", "sid").WithGroup("request").Info(
+		"message",
+		"password", "pass",
+		"new_password", "pass",
+		"Token", "tok",
+		"username", "user",
+	)
+
+	var entry struct {
+		SessionID string `json:"session_id"`
+		Request   struct {
+			Password    string `json:"password"`
+			NewPassword string `json:"new_password"`
+			Token       string `json:"Token"`
+			Username    string `json:"username"`
+		} `json:"request"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("redact(), invalid JSON %s: %v", buf.String(), err)
+	}
+
+	if entry.SessionID != redacted || entry.Request.Password != redacted ||
+		entry.Request.NewPassword != redacted || entry.Request.Token != redacted {
+		t.Errorf("redact(), secret is not redacted: %s", buf.String())
+	}
+	if entry.Request.Username != "user" {
+		t.Errorf("redact(), username is redacted: %s", buf.String())
+	}
+}
+
+func TestSensitive(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected bool
+	}{
+		{"password", true},
+		{"current_password", true},
+		{"SESSION_ID", true},
+		{"token", true},
+		{"user_id", false},
+		{"passwords_count", false},
+		{"route", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.key, func(t *testing.T) {
+			if got := Sensitive(test.key); got != test.expected {
+				t.Errorf("Sensitive(%q), got=%v, expected=%v", test.key, got, test.expected)
+			}
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+
+	if got := FromContext(context.Background()); got != slog.Default() {
+		t.Errorf("FromContext(), got=%v, expected=slog.Default()", got)
+	}
+	if got := FromContext(WithLogger(context.Background(), logger)); got != logger {
+		t.Errorf("FromContext(WithLogger()), got=%v, expected=%v", got, logger)
+	}
+}
@@ -0,0 +1,62 @@
+// Package mail provides sending of emails to users,
+// with an SMTP implementation and a writer one for development and tests.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+)
+
+var ErrInvalidMessage = errors.New("mail: message is invalid")
+
+// Message represents a plain text email message.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer is implemented by anything that can deliver a [Message].
+type Mailer interface {
+	// Send delivers the message.
+	// It fails if the message is invalid or it can't be delivered.
+	Send(ctx context.Context, message Message) error
+}
+
+// format returns the message encoded as an RFC 5322 message,
+// with CRLF line endings.
+// It fails if any of the header values contain a line break.
+func format(from string, message Message, now time.Time) ([]byte, error) {
+	for _, value := range []string{from, message.To, message.Subject} {
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, ErrInvalidMessage
+		}
+	}
+	if message.To == "" {
+		return nil, ErrInvalidMessage
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", message.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+
+	body := strings.ReplaceAll(message.Body, "\r\n", "\n")
+	for _, line := range strings.Split(body, "\n") {
+		// Lines starting with a dot are escaped by the SMTP client
+		b.WriteString(line)
+		b.WriteString("\r\n")
+	}
+
+	return b.Bytes(), nil
+}
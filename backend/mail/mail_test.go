@@ -0,0 +1,87 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name      string
+		from      string
+		message   Message
+		shouldErr bool
+	}{
+		{
+			"Valid",
+			"kera <no-reply@example.com>",
+			Message{To: "user@example.com", Subject: "Subject", Body: "Body"},
+			false,
+		},
+		{
+			"Valid: utf-8 subject",
+			"no-reply@example.com",
+			Message{To: "user@example.com", Subject: "Zażółć", Body: "Body\nSecond line"},
+			false,
+		},
+		{
+			"Invalid: empty recipient",
+			"no-reply@example.com",
+			Message{To: "", Subject: "Subject", Body: "Body"},
+			true,
+		},
+		{
+			"Invalid: header injection in recipient",
+			"no-reply@example.com",
+			Message{To: "user@example.com\r\nBcc: other@example.com", Subject: "Subject"},
+			true,
+		},
+		{
+			"Invalid: header injection in subject",
+			"no-reply@example.com",
+			Message{To: "user@example.com", Subject: "Subject\nBcc: other@example.com"},
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := format(test.from, test.message, time.Now())
+			if (err != nil) != test.shouldErr {
+				t.Errorf(
+					"format(%q, %v), error=%v, shouldErr=%v",
+					test.from, test.message, err, test.shouldErr,
+				)
+			}
+		})
+	}
+}
+
+func TestWriter(t *testing.T) {
+	var b bytes.Buffer
+	mailer := NewWriter(&b, "no-reply@example.com")
+
+	err := mailer.Send(context.Background(), Message{
+		To:      "user@example.com",
+		Subject: "Password reset",
+		Body:    "Your token: abc\nIt expires in 1 hour.",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := b.String()
+	for _, expected := range []string{
+		"From: no-reply@example.com\r\n",
+		"To: user@example.com\r\n",
+		"Subject: Password reset\r\n",
+		"\r\n\r\nYour token: abc\r\nIt expires in 1 hour.\r\n",
+	} {
+		if !strings.Contains(got, expected) {
+			t.Errorf("Writer.Send(), got=%q, expected to contain %q", got, expected)
+		}
+	}
+}
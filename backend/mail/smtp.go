@@ -0,0 +1,96 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTP represents a [Mailer] that delivers messages to an SMTP server.
+// STARTTLS is used whenever the server supports it,
+// and authentication is attempted only over an encrypted connection.
+type SMTP struct {
+	host     string
+	address  string
+	username string
+	password string
+	from     string
+
+	// tlsConfig is overridden in tests
+	tlsConfig *tls.Config
+}
+
+// NewSMTP returns a new *SMTP.
+// The username and password are optional.
+func NewSMTP(host string, port uint16, username, password, from string) *SMTP {
+	return &SMTP{
+		host:      host,
+		address:   net.JoinHostPort(host, strconv.Itoa(int(port))),
+		username:  username,
+		password:  password,
+		from:      from,
+		tlsConfig: &tls.Config{ServerName: host},
+	}
+}
+
+func (m *SMTP) Send(ctx context.Context, message Message) error {
+	data, err := format(m.from, message, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", m.address)
+	if err != nil {
+		return fmt.Errorf("mail: failed to connect: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	c, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("mail: failed to create client: %w", err)
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(m.tlsConfig); err != nil {
+			return fmt.Errorf("mail: failed to start TLS: %w", err)
+		}
+	}
+
+	if m.username != "" {
+		// smtp.PlainAuth refuses to send credentials over
+		// an unencrypted connection to a remote host
+		auth := smtp.PlainAuth("", m.username, m.password, m.host)
+		if err := c.Auth(auth); err != nil {
+			return fmt.Errorf("mail: failed to authenticate: %w", err)
+		}
+	}
+
+	if err := c.Mail(m.from); err != nil {
+		return fmt.Errorf("mail: failed to set sender: %w", err)
+	}
+	if err := c.Rcpt(message.To); err != nil {
+		return fmt.Errorf("mail: failed to set recipient: %w", err)
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("mail: failed to send data: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("mail: failed to send data: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("mail: failed to send data: %w", err)
+	}
+
+	return c.Quit()
+}
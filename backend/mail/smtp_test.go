@@ -0,0 +1,96 @@
+package mail
+
+import (
+	"context"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTPServer accepts a single connection and records the message data.
+func fakeSMTPServer(t *testing.T) (uint16, <-chan string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan string, 1)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		tp := textproto.NewConn(conn)
+		tp.PrintfLine("220 localhost ESMTP")
+
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+
+			switch command := strings.ToUpper(strings.Fields(line)[0]); command {
+			case "EHLO":
+				tp.PrintfLine("250-localhost")
+				tp.PrintfLine("250 AUTH PLAIN")
+			case "AUTH":
+				tp.PrintfLine("235 Authentication successful")
+			case "MAIL", "RCPT":
+				tp.PrintfLine("250 OK")
+			case "DATA":
+				tp.PrintfLine("354 Start mail input")
+				data, err := tp.ReadDotLines()
+				if err != nil {
+					return
+				}
+				received <- strings.Join(data, "\n")
+				tp.PrintfLine("250 OK")
+			case "QUIT":
+				tp.PrintfLine("221 Bye")
+				return
+			default:
+				tp.PrintfLine("502 Command not implemented")
+			}
+		}
+	}()
+
+	_, rawPort, _ := net.SplitHostPort(listener.Addr().String())
+	port, _ := strconv.ParseUint(rawPort, 10, 16)
+	return uint16(port), received
+}
+
+func TestSMTPSend(t *testing.T) {
+	port, received := fakeSMTPServer(t)
+	mailer := NewSMTP("127.0.0.1", port, "username", "password", "no-reply@example.com")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := mailer.Send(ctx, Message{
+		To:      "user@example.com",
+		Subject: "Subject",
+		Body:    "Body",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case data := <-received:
+		if !strings.Contains(data, "To: user@example.com") ||
+			!strings.HasSuffix(data, "Body") {
+			t.Errorf("SMTP.Send(), got=%q", data)
+		}
+	case <-time.After(time.Second):
+		t.Error("SMTP.Send(), message was not received")
+	}
+}
@@ -0,0 +1,60 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Writer represents a [Mailer] that writes messages to an [io.Writer]
+// instead of delivering them. It's meant for development and tests.
+type Writer struct {
+	mu   sync.Mutex
+	w    io.Writer
+	from string
+}
+
+// NewWriter returns a new *Writer writing messages to w.
+func NewWriter(w io.Writer, from string) *Writer {
+	return &Writer{w: w, from: from}
+}
+
+// NewFile returns a new *Writer appending messages to the file at path.
+// The file is created if it does not exist.
+func NewFile(path, from string) (*Writer, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("mail: failed to open %q: %w", path, err)
+	}
+	return NewWriter(f, from), nil
+}
+
+func (m *Writer) Send(ctx context.Context, message Message) error {
+	data, err := format(m.from, message, time.Now())
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, err := m.w.Write(data); err != nil {
+		return fmt.Errorf("mail: failed to write message: %w", err)
+	}
+	if _, err := io.WriteString(m.w, "\r\n"); err != nil {
+		return fmt.Errorf("mail: failed to write message: %w", err)
+	}
+
+	return nil
+}
+
+// Close closes the underlying writer if it implements [io.Closer].
+func (m *Writer) Close() error {
+	if c, ok := m.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
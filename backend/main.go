@@ -1,20 +1,97 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"os"
 
+	"github.com/zvxte/kera/config"
 	"github.com/zvxte/kera/server"
 )
 
+const usage = `Usage:
+  kera [serve] [flags]         runs the server
+  kera config print [flags]    prints the config with secrets redacted
+  kera user COMMAND [flags]    creates, resets the password of or disables a user, see kera user -h
+  kera session COMMAND [flags] lists, revokes or purges sessions, see kera session -h
+  kera export [flags] [FILE]   exports an account into a kera archive
+  kera import [flags] FILE     imports a kera archive into an account
+  kera migrate COMMAND [flags] migrates the database, see kera migrate -h`
+
 func main() {
-	address := os.Getenv("ADDRESS")
-	if address == "" {
-		panic("ADDRESS is not set")
+	var err error
+	args := os.Args[1:]
+	if len(args) > 0 && args[0] != "" && args[0][0] != '-' {
+		switch {
+		case args[0] == "serve":
+			err = runServer(args[1:])
+		case args[0] == "user":
+			err = runUser(args[1:])
+		case args[0] == "session":
+			err = runSession(args[1:])
+		case args[0] == "export":
+			err = runExport(args[1:])
+		case args[0] == "import":
+			err = runImport(args[1:])
+		case args[0] == "migrate":
+			err = runMigrate(args[1:])
+		case args[0] == "config" && len(args) > 1 && args[1] == "print":
+			err = runConfigPrint(args[2:])
+		default:
+			err = fmt.Errorf("unknown command %q\n%s", args[0], usage)
+		}
+	} else {
+		err = runServer(args)
+	}
+
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// loadConfig returns the config loaded with the flags from the args,
+// defined on the FlagSet, and the environment variables.
+func loadConfig(fs *flag.FlagSet, args []string) (*config.Config, error) {
+	return config.Load(fs, args, os.LookupEnv)
+}
+
+// runServer runs the server on the configured address until it's shut down.
+func runServer(args []string) error {
+	fs := flag.NewFlagSet("kera", flag.ContinueOnError)
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("unexpected arguments %q\n%s", fs.Args(), usage)
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("config is invalid:\n%w", err)
 	}
 
-	server, err := server.NewServer()
+	server, err := server.NewServer(cfg)
 	if err != nil {
-		panic(err)
+		return err
+	}
+	return server.Run(cfg.Server.Address)
+}
+
+// runConfigPrint prints the effective config with secrets redacted,
+// then fails if it's invalid.
+func runConfigPrint(args []string) error {
+	fs := flag.NewFlagSet("config print", flag.ContinueOnError)
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if err := cfg.Print(os.Stdout); err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("config is invalid:\n%w", err)
 	}
-	server.Run(address)
+	return nil
 }
@@ -0,0 +1,416 @@
+// Package metrics implements counters, gauges and histograms
+// exposed in the Prometheus text exposition format.
+//
+// Metrics are registered once at startup on a [Registry],
+// which serves all of them over HTTP.
+// Registering an invalid or duplicate metric is a programming error,
+// so it panics.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the content type of the text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// labelSeparator joins label values into a series key,
+// it can't appear in valid UTF-8 label values.
+const labelSeparator = "\xff"
+
+// DefaultBuckets are the histogram buckets for durations in seconds,
+// from 5ms to 10s.
+var DefaultBuckets = []float64{
+	0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10,
+}
+
+var (
+	nameRegexp  = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// Type represents the type of a metric.
+type Type string
+
+const (
+	CounterType   Type = "counter"
+	GaugeType     Type = "gauge"
+	HistogramType Type = "histogram"
+)
+
+// Sample represents a value of a metric collected on demand,
+// with the values of its labels.
+type Sample struct {
+	LabelValues []string
+	Value       float64
+}
+
+// metric is implemented by every metric of a Registry.
+type metric interface {
+	describe() *header
+	write(w *bufio.Writer)
+}
+
+// header represents what every metric has in common.
+type header struct {
+	name   string
+	help   string
+	typ    Type
+	labels []string
+}
+
+func (h *header) describe() *header {
+	return h
+}
+
+// Registry represents a set of metrics, written in their registration order.
+type Registry struct {
+	mu      sync.Mutex
+	metrics []metric
+}
+
+// NewRegistry returns a new empty *Registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) register(m metric) {
+	h := m.describe()
+	if !nameRegexp.MatchString(h.name) {
+		panic(fmt.Sprintf("metrics: invalid name %q", h.name))
+	}
+	for _, label := range h.labels {
+		if !labelRegexp.MatchString(label) || strings.HasPrefix(label, "__") {
+			panic(fmt.Sprintf("metrics: %s has invalid label %q", h.name, label))
+		}
+		if h.typ == HistogramType && label == "le" {
+			panic(fmt.Sprintf("metrics: %s has reserved label %q", h.name, label))
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, registered := range r.metrics {
+		if registered.describe().name == h.name {
+			panic(fmt.Sprintf("metrics: %s is already registered", h.name))
+		}
+	}
+	r.metrics = append(r.metrics, m)
+}
+
+// Write writes every metric to w in the text exposition format.
+// It fails if w fails.
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	metrics := slices.Clone(r.metrics)
+	r.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, m := range metrics {
+		h := m.describe()
+		fmt.Fprintf(bw, "# HELP %s %s\n", h.name, escapeHelp(h.help))
+		fmt.Fprintf(bw, "# TYPE %s %s\n", h.name, h.typ)
+		m.write(bw)
+	}
+	return bw.Flush()
+}
+
+// ServeHTTP writes every metric as the response.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	r.Write(w)
+}
+
+// series represents the values of a metric with the same label values.
+type series[T any] struct {
+	mu     sync.Mutex
+	values map[string]*T
+}
+
+// get returns the value of the label values, creating it if needed.
+// It panics if the number of label values does not match the labels.
+func (s *series[T]) get(h *header, labelValues []string, create func() *T) *T {
+	if len(labelValues) != len(h.labels) {
+		panic(fmt.Sprintf(
+			"metrics: %s has %d labels, got %d values",
+			h.name, len(h.labels), len(labelValues),
+		))
+	}
+
+	key := strings.Join(labelValues, labelSeparator)
+	v, ok := s.values[key]
+	if !ok {
+		if s.values == nil {
+			s.values = make(map[string]*T)
+		}
+		v = create()
+		s.values[key] = v
+	}
+	return v
+}
+
+// sorted returns the label values and the values of the series,
+// sorted by the label values.
+func (s *series[T]) sorted() ([][]string, []*T) {
+	keys := make([]string, 0, len(s.values))
+	for key := range s.values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	labelValues := make([][]string, len(keys))
+	values := make([]*T, len(keys))
+	for i, key := range keys {
+		labelValues[i] = strings.Split(key, labelSeparator)
+		values[i] = s.values[key]
+	}
+	return labelValues, values
+}
+
+// Counter represents a value that only goes up, for each label values.
+type Counter struct {
+	header
+	series series[float64]
+}
+
+// NewCounter registers and returns a new *Counter.
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{header: header{name, help, CounterType, labels}}
+	r.register(c)
+	return c
+}
+
+// Inc adds 1 to the counter of the label values.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds the delta to the counter of the label values.
+// It panics if the delta is negative.
+func (c *Counter) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		panic(fmt.Sprintf("metrics: %s can't decrease", c.name))
+	}
+
+	c.series.mu.Lock()
+	defer c.series.mu.Unlock()
+	*c.series.get(&c.header, labelValues, newFloat) += delta
+}
+
+func (c *Counter) write(w *bufio.Writer) {
+	c.series.mu.Lock()
+	defer c.series.mu.Unlock()
+
+	labelValues, values := c.series.sorted()
+	for i, v := range values {
+		writeSample(w, c.name, c.labels, labelValues[i], *v)
+	}
+}
+
+// Gauge represents a value that can go up and down, for each label values.
+type Gauge struct {
+	header
+	series series[float64]
+}
+
+// NewGauge registers and returns a new *Gauge.
+func (r *Registry) NewGauge(name, help string, labels ...string) *Gauge {
+	g := &Gauge{header: header{name, help, GaugeType, labels}}
+	r.register(g)
+	return g
+}
+
+// Set sets the gauge of the label values.
+func (g *Gauge) Set(value float64, labelValues ...string) {
+	g.series.mu.Lock()
+	defer g.series.mu.Unlock()
+	*g.series.get(&g.header, labelValues, newFloat) = value
+}
+
+// Add adds the delta to the gauge of the label values,
+// it can be negative.
+func (g *Gauge) Add(delta float64, labelValues ...string) {
+	g.series.mu.Lock()
+	defer g.series.mu.Unlock()
+	*g.series.get(&g.header, labelValues, newFloat) += delta
+}
+
+func (g *Gauge) write(w *bufio.Writer) {
+	g.series.mu.Lock()
+	defer g.series.mu.Unlock()
+
+	labelValues, values := g.series.sorted()
+	for i, v := range values {
+		writeSample(w, g.name, g.labels, labelValues[i], *v)
+	}
+}
+
+func newFloat() *float64 {
+	return new(float64)
+}
+
+// Histogram represents observations counted in buckets,
+// for each label values.
+type Histogram struct {
+	header
+	buckets []float64
+	series  series[histogramValue]
+}
+
+type histogramValue struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewHistogram registers and returns a new *Histogram
+// with the upper bounds of its buckets.
+// It panics if the buckets are not sorted in increasing order.
+func (r *Registry) NewHistogram(
+	name, help string, buckets []float64, labels ...string,
+) *Histogram {
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i] <= buckets[i-1] {
+			panic(fmt.Sprintf("metrics: %s buckets are not increasing", name))
+		}
+	}
+
+	h := &Histogram{
+		header:  header{name, help, HistogramType, labels},
+		buckets: slices.Clone(buckets),
+	}
+	r.register(h)
+	return h
+}
+
+// Observe adds the value to the histogram of the label values.
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	h.series.mu.Lock()
+	defer h.series.mu.Unlock()
+
+	v := h.series.get(&h.header, labelValues, func() *histogramValue {
+		return &histogramValue{counts: make([]uint64, len(h.buckets))}
+	})
+	for i, bound := range h.buckets {
+		if value <= bound {
+			v.counts[i]++
+		}
+	}
+	v.count++
+	v.sum += value
+}
+
+func (h *Histogram) write(w *bufio.Writer) {
+	h.series.mu.Lock()
+	defer h.series.mu.Unlock()
+
+	labels := append(slices.Clone(h.labels), "le")
+	labelValues, values := h.series.sorted()
+	for i, v := range values {
+		bucketValues := append(slices.Clone(labelValues[i]), "")
+		for j, bound := range h.buckets {
+			bucketValues[len(bucketValues)-1] = formatFloat(bound)
+			writeSample(w, h.name+"_bucket", labels, bucketValues, float64(v.counts[j]))
+		}
+		bucketValues[len(bucketValues)-1] = "+Inf"
+		writeSample(w, h.name+"_bucket", labels, bucketValues, float64(v.count))
+		writeSample(w, h.name+"_sum", h.labels, labelValues[i], v.sum)
+		writeSample(w, h.name+"_count", h.labels, labelValues[i], float64(v.count))
+	}
+}
+
+// Func represents a counter or a gauge collected on demand,
+// when the metrics are written.
+type Func struct {
+	header
+	collect func() []Sample
+}
+
+// NewCounterFunc registers and returns a new counter *Func.
+// The collect function must return samples with a value
+// for every label, it's called on every write.
+func (r *Registry) NewCounterFunc(
+	name, help string, labels []string, collect func() []Sample,
+) *Func {
+	f := &Func{header{name, help, CounterType, labels}, collect}
+	r.register(f)
+	return f
+}
+
+// NewGaugeFunc registers and returns a new gauge *Func.
+// The collect function must return samples with a value
+// for every label, it's called on every write.
+func (r *Registry) NewGaugeFunc(
+	name, help string, labels []string, collect func() []Sample,
+) *Func {
+	f := &Func{header{name, help, GaugeType, labels}, collect}
+	r.register(f)
+	return f
+}
+
+func (f *Func) write(w *bufio.Writer) {
+	for _, sample := range f.collect() {
+		if len(sample.LabelValues) != len(f.labels) {
+			continue
+		}
+		writeSample(w, f.name, f.labels, sample.LabelValues, sample.Value)
+	}
+}
+
+// writeSample writes a line of the text exposition format.
+func writeSample(
+	w *bufio.Writer, name string, labels, labelValues []string, value float64,
+) {
+	w.WriteString(name)
+	if len(labels) > 0 {
+		w.WriteByte('{')
+		for i, label := range labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(label)
+			w.WriteString(`="`)
+			w.WriteString(escapeLabelValue(labelValues[i]))
+			w.WriteByte('"')
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(value))
+	w.WriteByte('\n')
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+var (
+	helpReplacer       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelValueReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(help string) string {
+	return helpReplacer.Replace(help)
+}
+
+func escapeLabelValue(value string) string {
+	return labelValueReplacer.Replace(value)
+}
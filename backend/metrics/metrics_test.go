@@ -0,0 +1,134 @@
+package metrics
+
+import (
+	"bytes"
+	"math"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	r := NewRegistry()
+
+	requests := r.NewCounter("requests_total", "Requests.", "route", "status")
+	requests.Inc("GET /b", "200")
+	requests.Inc("GET /a", "404")
+	requests.Add(2, "GET /b", "200")
+
+	sessions := r.NewGauge("sessions", "Active\nsessions.")
+	sessions.Set(3)
+	sessions.Add(-1)
+
+	latency := r.NewHistogram("latency_seconds", "Latency.", []float64{0.1, 1}, "route")
+	latency.Observe(0.05, `say "hi"`)
+	latency.Observe(0.5, `say "hi"`)
+	latency.Observe(5, `say "hi"`)
+
+	r.NewGaugeFunc("pool", `Pool \ connections.`, []string{"state"}, func() []Sample {
+		return []Sample{
+			{[]string{"idle"}, 2},
+			{[]string{"in_use", "extra"}, 1},
+			{[]string{"wait"}, math.Inf(1)},
+		}
+	})
+
+	var buf bytes.Buffer
+	if err := r.Write(&buf); err != nil {
+		t.Fatalf("Write(), error=%v", err)
+	}
+
+	expected := `# HELP requests_total Requests.
+# TYPE requests_total counter
+requests_total{route="GET /a",status="404"} 1
+requests_total{route="GET /b",status="200"} 3
+# HELP sessions Active\nsessions.
+# TYPE sessions gauge
+sessions 2
+# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{route="say \"hi\"",le="0.1"} 1
+latency_seconds_bucket{route="say \"hi\"",le="1"} 2
+latency_seconds_bucket{route="say \"hi\"",le="+Inf"} 3
+latency_seconds_sum{route="say \"hi\""} 5.55
+latency_seconds_count{route="say \"hi\""} 3
+# HELP pool Pool \\ connections.
+# TYPE pool gauge
+pool{state="idle"} 2
+pool{state="wait"} +Inf
+`
+	if buf.String() != expected {
+		t.Errorf("Write(), got=\n%s\nexpected=\n%s", buf.String(), expected)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	tests := []struct {
+		name        string
+		register    func(r *Registry)
+		shouldPanic bool
+	}{
+		{"Valid", func(r *Registry) { r.NewCounter("a_total", "", "b") }, false},
+		{"Invalid: name", func(r *Registry) { r.NewCounter("a-total", "") }, true},
+		{"Invalid: label", func(r *Registry) { r.NewCounter("a_total", "", "b c") }, true},
+		{"Invalid: reserved label", func(r *Registry) { r.NewCounter("a_total", "", "__b") }, true},
+		{"Invalid: le label", func(r *Registry) { r.NewHistogram("a", "", DefaultBuckets, "le") }, true},
+		{"Invalid: buckets", func(r *Registry) { r.NewHistogram("a", "", []float64{1, 1}) }, true},
+		{
+			"Invalid: duplicate",
+			func(r *Registry) { r.NewCounter("a_total", ""); r.NewGauge("a_total", "") },
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			defer func() {
+				recovered := recover()
+				if (recovered != nil) != test.shouldPanic {
+					t.Errorf("register(), panic=%v, shouldPanic=%v", recovered, test.shouldPanic)
+				}
+			}()
+			test.register(NewRegistry())
+		})
+	}
+}
+
+func TestCounter(t *testing.T) {
+	tests := []struct {
+		name        string
+		add         func(c *Counter)
+		shouldPanic bool
+	}{
+		{"Valid", func(c *Counter) { c.Add(1, "a") }, false},
+		{"Invalid: negative", func(c *Counter) { c.Add(-1, "a") }, true},
+		{"Invalid: missing label value", func(c *Counter) { c.Inc() }, true},
+		{"Invalid: extra label value", func(c *Counter) { c.Inc("a", "b") }, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			defer func() {
+				recovered := recover()
+				if (recovered != nil) != test.shouldPanic {
+					t.Errorf("Add(), panic=%v, shouldPanic=%v", recovered, test.shouldPanic)
+				}
+			}()
+			test.add(NewRegistry().NewCounter("a_total", "", "label"))
+		})
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("a_total", "A.").Inc()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	if got := w.Header().Get("Content-Type"); got != ContentType {
+		t.Errorf("ServeHTTP(), Content-Type=%q, expected=%q", got, ContentType)
+	}
+	if got := w.Body.String(); got != "# HELP a_total A.\n# TYPE a_total counter\na_total 1\n" {
+		t.Errorf("ServeHTTP(), got=%q", got)
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/zvxte/kera/database"
+)
+
+const migrateUsage = `Usage:
+  kera migrate up [flags]              applies every pending migration
+  kera migrate down [flags] N          reverts the last N applied migrations
+  kera migrate to [flags] VERSION      migrates up or down to the version
+  kera migrate status [flags]          lists the migrations and their state`
+
+// runMigrate migrates the database read from database.dsn of the config.
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("migrate: command is required\n%s", migrateUsage)
+	}
+	command, args := args[0], args[1:]
+
+	// The argument of the command, if it takes one
+	var argName string
+	switch command {
+	case "up", "status":
+	case "down":
+		argName = "N"
+	case "to":
+		argName = "VERSION"
+	case "-h", "-help", "--help":
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return flag.ErrHelp
+	default:
+		return fmt.Errorf("migrate: unknown command %q\n%s", command, migrateUsage)
+	}
+
+	flags := flag.NewFlagSet("migrate "+command, flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "print the migrations without running them")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), migrateUsage)
+		flags.PrintDefaults()
+	}
+	cfg, err := loadConfig(flags, args)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case argName != "" && flags.NArg() != 1:
+		return fmt.Errorf("migrate %s: %s is required\n%s", command, argName, migrateUsage)
+	case argName == "" && flags.NArg() != 0:
+		return fmt.Errorf("migrate %s: unexpected arguments %q\n%s", command, flags.Args(), migrateUsage)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Database.MigrationTimeout)
+	defer cancel()
+
+	sqlDatabase, err := openDatabase(ctx, cfg, "migrate")
+	if err != nil {
+		return err
+	}
+	defer sqlDatabase.DB.Close()
+
+	migrator, err := database.NewMigrator(sqlDatabase.DB)
+	if err != nil {
+		return fmt.Errorf("migrate: %w", err)
+	}
+
+	var steps []database.MigrationStep
+	switch command {
+	case "up":
+		steps, err = migrator.Up(ctx, *dryRun)
+
+	case "down":
+		n, parseErr := strconv.ParseUint(flags.Arg(0), 10, 0)
+		if parseErr != nil {
+			return fmt.Errorf("migrate down: invalid number %q", flags.Arg(0))
+		}
+		steps, err = migrator.Down(ctx, uint(n), *dryRun)
+
+	case "to":
+		version, parseErr := strconv.ParseUint(flags.Arg(0), 10, 16)
+		if parseErr != nil {
+			return fmt.Errorf("migrate to: invalid version %q", flags.Arg(0))
+		}
+		steps, err = migrator.To(ctx, uint16(version), *dryRun)
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return fmt.Errorf("migrate status: %w", err)
+		}
+		return printMigrationStatuses(statuses)
+	}
+
+	printMigrationSteps(steps, *dryRun)
+	if err != nil {
+		return fmt.Errorf("migrate %s: %w", command, err)
+	}
+	return nil
+}
+
+func printMigrationSteps(steps []database.MigrationStep, dryRun bool) {
+	if dryRun {
+		fmt.Println("dry run, nothing is migrated")
+	}
+	if len(steps) == 0 {
+		fmt.Println("nothing to migrate")
+	}
+
+	for _, step := range steps {
+		verb := "applied"
+		switch {
+		case dryRun && step.Direction == database.MigrationUp:
+			verb = "would apply"
+		case dryRun:
+			verb = "would revert"
+		case step.Direction == database.MigrationDown:
+			verb = "reverted"
+		}
+		fmt.Printf("%s %s\n", verb, step.Name)
+	}
+}
+
+func printMigrationStatuses(statuses []database.MigrationStatus) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATE\tAPPLIED")
+	for _, status := range statuses {
+		state := "pending"
+		switch {
+		case status.Unknown:
+			state = "unknown"
+		case status.Edited:
+			state = "edited"
+		case status.Applied:
+			state = "applied"
+		}
+
+		appliedTime := "-"
+		if !status.AppliedTime.IsZero() {
+			appliedTime = status.AppliedTime.UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(
+			w, "%03d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedTime,
+		)
+	}
+	return w.Flush()
+}
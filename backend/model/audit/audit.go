@@ -0,0 +1,98 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/zvxte/kera/model"
+	"github.com/zvxte/kera/model/uuid"
+)
+
+// Action represents what an admin did.
+type Action uint8
+
+const (
+	InviteCreated Action = iota
+	InviteDeleted
+	UserApproved
+	UserRejected
+	UserDisabled
+	UserEnabled
+	UserPasswordReset
+	UserDeleted
+)
+
+func (a Action) String() string {
+	switch a {
+	case InviteCreated:
+		return "invite_created"
+	case InviteDeleted:
+		return "invite_deleted"
+	case UserApproved:
+		return "user_approved"
+	case UserRejected:
+		return "user_rejected"
+	case UserDisabled:
+		return "user_disabled"
+	case UserEnabled:
+		return "user_enabled"
+	case UserPasswordReset:
+		return "user_password_reset"
+	case UserDeleted:
+		return "user_deleted"
+	default:
+		return ""
+	}
+}
+
+// Entry represents an admin action in the audit trail.
+// Entries outlive both the admin and the target,
+// so the target is described by Details, e.g. by its username.
+// All time fields are in UTC.
+type Entry struct {
+	ID uuid.UUID
+
+	// AdminID is zero if the admin was deleted,
+	// or if the action was done from the command line.
+	AdminID uuid.UUID
+
+	Action       Action
+	TargetID     uuid.UUID
+	Details      string
+	CreationTime time.Time
+}
+
+// New returns a new *Entry.
+// It fails if the system's source of randomness is unavailable.
+// The CreationTime field is set to the current time.
+func New(
+	adminID uuid.UUID, action Action, targetID uuid.UUID, details string,
+) (*Entry, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, model.ErrUnexpected
+	}
+
+	return &Entry{
+		ID:           id,
+		AdminID:      adminID,
+		Action:       action,
+		TargetID:     targetID,
+		Details:      details,
+		CreationTime: time.Now().UTC(),
+	}, nil
+}
+
+// Load returns an *Entry from provided parameters.
+func Load(
+	id, adminID uuid.UUID, action Action, targetID uuid.UUID,
+	details string, creationTime time.Time,
+) *Entry {
+	return &Entry{
+		ID:           id,
+		AdminID:      adminID,
+		Action:       action,
+		TargetID:     targetID,
+		Details:      details,
+		CreationTime: creationTime.UTC(),
+	}
+}
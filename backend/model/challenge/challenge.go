@@ -0,0 +1,68 @@
+package challenge
+
+import (
+	"time"
+
+	"github.com/zvxte/kera/model/uuid"
+	"github.com/zvxte/kera/webauthn"
+)
+
+const HashedIDLen = 32
+
+// HashedID represents a hashed challenge.
+type HashedID [HashedIDLen]byte
+
+// Ceremony represents a WebAuthn ceremony a challenge is issued for.
+type Ceremony uint8
+
+const (
+	Registration Ceremony = iota
+	Authentication
+)
+
+// Challenge represents a pending WebAuthn ceremony.
+// Only the hash of the challenge is ever stored,
+// and a challenge can be used only once.
+// All time fields are in UTC.
+type Challenge struct {
+	HashedID HashedID
+	Ceremony Ceremony
+
+	// UserID is the user registering a passkey,
+	// it's not set for an authentication.
+	UserID uuid.UUID
+
+	ExpirationTime time.Time
+}
+
+// New returns a new challenge and its *Challenge.
+// It fails if the system's source of randomness is unavailable.
+// The ExpirationTime field is set to now + the lifetime.
+func New(
+	ceremony Ceremony, userID uuid.UUID, lifetime time.Duration,
+) (string, *Challenge, error) {
+	id, err := webauthn.NewChallenge()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return id, &Challenge{
+		HashedID:       webauthn.HashChallenge(id),
+		Ceremony:       ceremony,
+		UserID:         userID,
+		ExpirationTime: time.Now().UTC().Add(lifetime),
+	}, nil
+}
+
+// Load returns a *Challenge from provided parameters.
+func Load(
+	hashedID HashedID, ceremony Ceremony, userID uuid.UUID,
+	expirationTime time.Time,
+) *Challenge {
+	return &Challenge{
+		HashedID:       hashedID,
+		Ceremony:       ceremony,
+		UserID:         userID,
+		ExpirationTime: expirationTime.UTC(),
+	}
+}
@@ -0,0 +1,92 @@
+package feed
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+
+	"github.com/zvxte/kera/hash/sha256"
+	"github.com/zvxte/kera/model"
+	"github.com/zvxte/kera/model/uuid"
+)
+
+const (
+	HashedTokenLen = 32
+
+	// tokenBytes represents the number of random bytes of a feed token.
+	tokenBytes = 32
+	// tokenLen represents the length of a base64 (URL, no padding) encoded feed token.
+	tokenLen = 43
+)
+
+// HashedToken represents a hashed feed token.
+type HashedToken [HashedTokenLen]byte
+
+// Feed represents a calendar feed of a user's habits.
+// Calendar clients can't send session cookies,
+// so the feed is authenticated by a secret token in its URL.
+// The feed lives until it's revoked, only the hash of the token is ever stored.
+// All time fields are in UTC.
+type Feed struct {
+	ID          uuid.UUID
+	HashedToken HashedToken
+	UserID      uuid.UUID
+
+	// Completions is true if the feed has an event for every done day.
+	Completions bool
+
+	CreationTime time.Time
+}
+
+// New returns a new feed token and its *Feed.
+// It fails if the system's source of randomness is unavailable.
+// The CreationTime field is set to the current time.
+func New(userID uuid.UUID, completions bool) (string, *Feed, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return "", nil, model.ErrUnexpected
+	}
+
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, model.ErrUnexpected
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	return token, &Feed{
+		ID:           id,
+		HashedToken:  HashToken(token),
+		UserID:       userID,
+		Completions:  completions,
+		CreationTime: time.Now().UTC(),
+	}, nil
+}
+
+// Load returns a *Feed from provided parameters.
+func Load(
+	id uuid.UUID, hashedToken HashedToken, userID uuid.UUID,
+	completions bool, creationTime time.Time,
+) *Feed {
+	return &Feed{
+		ID:           id,
+		HashedToken:  hashedToken,
+		UserID:       userID,
+		Completions:  completions,
+		CreationTime: creationTime.UTC(),
+	}
+}
+
+// HashToken returns the feed token hashed using sha256.
+func HashToken(token string) HashedToken {
+	return sha256.Hash(token)
+}
+
+// ValidateToken returns true if the provided feed token
+// meets the application requirements, else false.
+func ValidateToken(token string) bool {
+	if len(token) != tokenLen {
+		return false
+	}
+	_, err := base64.RawURLEncoding.DecodeString(token)
+	return err == nil
+}
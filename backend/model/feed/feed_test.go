@@ -0,0 +1,59 @@
+package feed
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zvxte/kera/model/uuid"
+)
+
+func TestNew(t *testing.T) {
+	token, feed, err := New(uuid.UUID{}, true)
+	if err != nil {
+		t.Fatalf("New(), error=%v", err)
+	}
+
+	if !ValidateToken(token) {
+		t.Errorf("New(), invalid token=%q", token)
+	}
+	if feed.HashedToken != HashToken(token) {
+		t.Errorf("New(), hashed token does not match")
+	}
+	if !feed.Completions {
+		t.Errorf("New(), completions are not set")
+	}
+
+	other, _, err := New(uuid.UUID{}, true)
+	if err != nil {
+		t.Fatalf("New(), error=%v", err)
+	}
+	if other == token {
+		t.Errorf("New(), tokens are not random")
+	}
+}
+
+func TestValidateToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		token    string
+		shouldBe bool
+	}{
+		{"Valid", strings.Repeat("a", tokenLen), true},
+		{"Valid: URL alphabet", strings.Repeat("-_", tokenLen/2) + "A", true},
+		{"Invalid: too short", strings.Repeat("a", tokenLen-1), false},
+		{"Invalid: too long", strings.Repeat("a", tokenLen+1), false},
+		{"Invalid: standard alphabet", strings.Repeat("+/", tokenLen/2) + "A", false},
+		{"Invalid: empty", "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := ValidateToken(test.token); result != test.shouldBe {
+				t.Errorf(
+					"ValidateToken(%q), got=%v, expected=%v",
+					test.token, result, test.shouldBe,
+				)
+			}
+		})
+	}
+}
@@ -27,6 +27,17 @@ const (
 	Ended
 )
 
+func (s Status) String() string {
+	switch s {
+	case Active:
+		return "active"
+	case Ended:
+		return "ended"
+	default:
+		return ""
+	}
+}
+
 // TrackedWeekDays represents days of the week that are tracked in a bitmap,
 // where each bit (0 - untracked, 1 - tracked) represents a day
 // starting from Monday as the first bit (LSB).
@@ -64,6 +75,27 @@ const (
 	Sunday
 )
 
+func (d WeekDay) String() string {
+	switch d {
+	case Monday:
+		return "monday"
+	case Tuesday:
+		return "tuesday"
+	case Wednesday:
+		return "wednesday"
+	case Thursday:
+		return "thursday"
+	case Friday:
+		return "friday"
+	case Saturday:
+		return "saturday"
+	case Sunday:
+		return "sunday"
+	default:
+		return ""
+	}
+}
+
 // New returns a new *Habit.
 // It fails if the provided parameters do not meet the application requirements.
 // The returned error is safe for client-side message.
@@ -8,7 +8,7 @@ import (
 
 const (
 	// HistoryPatchWindow represents a timeframe,
-	// in which the history can be patched.
+	// in which the history can be patched by default.
 	HistoryPatchWindow = 7 * 24 * time.Hour
 )
 
@@ -36,6 +36,21 @@ const (
 	DayPending
 )
 
+func (s DayStatus) String() string {
+	switch s {
+	case DayUntracked:
+		return "untracked"
+	case DayDone:
+		return "done"
+	case DayMissed:
+		return "missed"
+	case DayPending:
+		return "pending"
+	default:
+		return ""
+	}
+}
+
 func LoadHistoryFromBitmap(
 	historyDate date.Date, days uint,
 	trackedWeekDays TrackedWeekDays,
@@ -0,0 +1,55 @@
+package identity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/zvxte/kera/model/uuid"
+)
+
+// Identity represents a user's account at an external OpenID provider
+// linked to a user in the application.
+// An identity is uniquely identified by its issuer and subject.
+// All time fields are in UTC.
+type Identity struct {
+	ID           uuid.UUID
+	UserID       uuid.UUID
+	Issuer       string
+	Subject      string
+	Email        string
+	CreationTime time.Time
+}
+
+// New returns a new *Identity.
+// It fails if the system's source of randomness is unavailable.
+// The email is the one reported by the provider and can be empty.
+// The CreationTime field is set to the current time.
+func New(userID uuid.UUID, issuer, subject, email string) (*Identity, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create identity: %w", err)
+	}
+
+	return &Identity{
+		ID:           id,
+		UserID:       userID,
+		Issuer:       issuer,
+		Subject:      subject,
+		Email:        email,
+		CreationTime: time.Now().UTC(),
+	}, nil
+}
+
+// Load returns an *Identity from provided parameters.
+func Load(
+	id, userID uuid.UUID, issuer, subject, email string, creationTime time.Time,
+) *Identity {
+	return &Identity{
+		ID:           id,
+		UserID:       userID,
+		Issuer:       issuer,
+		Subject:      subject,
+		Email:        email,
+		CreationTime: creationTime.UTC(),
+	}
+}
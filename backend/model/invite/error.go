@@ -0,0 +1,8 @@
+package invite
+
+import "errors"
+
+var (
+	ErrMaxUsesInvalid  = errors.New("invite max uses must be between 1 and 1000")
+	ErrLifetimeInvalid = errors.New("invite lifetime must be between 1 minute and 30 days")
+)
@@ -0,0 +1,121 @@
+package invite
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+
+	"github.com/zvxte/kera/hash/sha256"
+	"github.com/zvxte/kera/model"
+	"github.com/zvxte/kera/model/uuid"
+)
+
+const (
+	HashedCodeLen = 32
+
+	MaxUsesMin  = 1
+	MaxUsesMax  = 1000
+	LifetimeMin = time.Minute
+	LifetimeMax = 30 * 24 * time.Hour
+
+	// codeBytes represents the number of random bytes of an invite code.
+	codeBytes = 16
+	// codeLen represents the length of a base64 (URL, no padding) encoded invite code.
+	codeLen = 22
+)
+
+// HashedCode represents a hashed invite code.
+type HashedCode [HashedCodeLen]byte
+
+// Invite represents a code allowing registration
+// when the registration is invite only.
+// An invite can be used up to MaxUses times before it expires.
+// Only the hash of the code is ever stored.
+// All time fields are in UTC.
+type Invite struct {
+	ID         uuid.UUID
+	HashedCode HashedCode
+
+	// CreatorID is the admin who created the invite,
+	// it's zero if the admin was deleted.
+	CreatorID uuid.UUID
+
+	MaxUses        uint32
+	Uses           uint32
+	CreationTime   time.Time
+	ExpirationTime time.Time
+}
+
+// New returns a new invite code and its *Invite.
+// It fails if the provided parameters do not meet the application requirements.
+// The returned error is safe for client-side message.
+// The ExpirationTime field is set to now + the lifetime.
+func New(
+	creatorID uuid.UUID, maxUses uint32, lifetime time.Duration,
+) (string, *Invite, error) {
+	if maxUses < MaxUsesMin || maxUses > MaxUsesMax {
+		return "", nil, ErrMaxUsesInvalid
+	}
+
+	if lifetime < LifetimeMin || lifetime > LifetimeMax {
+		return "", nil, ErrLifetimeInvalid
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return "", nil, model.ErrUnexpected
+	}
+
+	b := make([]byte, codeBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, model.ErrUnexpected
+	}
+	code := base64.RawURLEncoding.EncodeToString(b)
+
+	now := time.Now().UTC()
+
+	return code, &Invite{
+		ID:             id,
+		HashedCode:     HashCode(code),
+		CreatorID:      creatorID,
+		MaxUses:        maxUses,
+		CreationTime:   now,
+		ExpirationTime: now.Add(lifetime),
+	}, nil
+}
+
+// Load returns an *Invite from provided parameters.
+func Load(
+	id uuid.UUID, hashedCode HashedCode, creatorID uuid.UUID,
+	maxUses, uses uint32, creationTime, expirationTime time.Time,
+) *Invite {
+	return &Invite{
+		ID:             id,
+		HashedCode:     hashedCode,
+		CreatorID:      creatorID,
+		MaxUses:        maxUses,
+		Uses:           uses,
+		CreationTime:   creationTime.UTC(),
+		ExpirationTime: expirationTime.UTC(),
+	}
+}
+
+// Usable returns true if the invite has uses left and is not expired at now.
+func (i *Invite) Usable(now time.Time) bool {
+	return i.Uses < i.MaxUses && now.Before(i.ExpirationTime)
+}
+
+// HashCode returns the invite code hashed using sha256.
+func HashCode(code string) HashedCode {
+	return sha256.Hash(code)
+}
+
+// ValidateCode returns true if the provided invite code
+// meets the application requirements, else false.
+func ValidateCode(code string) bool {
+	if len(code) != codeLen {
+		return false
+	}
+	_, err := base64.RawURLEncoding.DecodeString(code)
+	return err == nil
+}
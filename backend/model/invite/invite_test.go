@@ -0,0 +1,101 @@
+package invite
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zvxte/kera/model/uuid"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxUses   uint32
+		lifetime  time.Duration
+		shouldErr bool
+	}{
+		{"Valid: single use", 1, 24 * time.Hour, false},
+		{"Valid: multi use", MaxUsesMax, LifetimeMax, false},
+		{"Valid: short lifetime", 1, LifetimeMin, false},
+		{"Invalid: no uses", 0, time.Hour, true},
+		{"Invalid: too many uses", MaxUsesMax + 1, time.Hour, true},
+		{"Invalid: lifetime too short", 1, time.Second, true},
+		{"Invalid: lifetime too long", 1, LifetimeMax + time.Second, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			code, invite, err := New(uuid.UUID{}, test.maxUses, test.lifetime)
+			if (err != nil) != test.shouldErr {
+				t.Fatalf(
+					"New(%v, %v), error=%v, shouldErr=%v",
+					test.maxUses, test.lifetime, err, test.shouldErr,
+				)
+			}
+			if err != nil {
+				return
+			}
+
+			if !ValidateCode(code) {
+				t.Errorf("New(%v, %v), invalid code=%q", test.maxUses, test.lifetime, code)
+			}
+			if invite.HashedCode != HashCode(code) {
+				t.Errorf("New(%v, %v), hashed code does not match", test.maxUses, test.lifetime)
+			}
+			if !invite.Usable(time.Now()) {
+				t.Errorf("New(%v, %v), invite is not usable", test.maxUses, test.lifetime)
+			}
+		})
+	}
+}
+
+func TestUsable(t *testing.T) {
+	now := time.Now().UTC()
+
+	tests := []struct {
+		name     string
+		invite   *Invite
+		shouldBe bool
+	}{
+		{"Usable", Load(uuid.UUID{}, HashedCode{}, uuid.UUID{}, 2, 1, now, now.Add(time.Hour)), true},
+		{"Used up", Load(uuid.UUID{}, HashedCode{}, uuid.UUID{}, 2, 2, now, now.Add(time.Hour)), false},
+		{"Expired", Load(uuid.UUID{}, HashedCode{}, uuid.UUID{}, 2, 0, now, now), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if usable := test.invite.Usable(now); usable != test.shouldBe {
+				t.Errorf(
+					"Usable(%v), got=%v, expected=%v",
+					now, usable, test.shouldBe,
+				)
+			}
+		})
+	}
+}
+
+func TestValidateCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     string
+		shouldBe bool
+	}{
+		{"Valid", "AAAAAAAAAAAAAAAAAAAAAA", true},
+		{"Valid: URL charset", "-_-_-_-_-_-_-_-_-_-_-_", true},
+		{"Invalid: too short", "AAAAAAAAAAAAAAAAAAAAA", false},
+		{"Invalid: too long", strings.Repeat("A", codeLen+1), false},
+		{"Invalid: charset", "AAAAAAAAAAAAAAAAAAAAA+", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if isValid := ValidateCode(test.code); isValid != test.shouldBe {
+				t.Errorf(
+					"ValidateCode(%q), got=%v, expected=%v",
+					test.code, isValid, test.shouldBe,
+				)
+			}
+		})
+	}
+}
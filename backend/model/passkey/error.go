@@ -0,0 +1,9 @@
+package passkey
+
+import "errors"
+
+var (
+	ErrNameTooShort = errors.New("passkey name is too short")
+	ErrNameTooLong  = errors.New("passkey name is too long")
+	ErrNameInvalid  = errors.New("passkey name is invalid")
+)
@@ -0,0 +1,75 @@
+package passkey
+
+import (
+	"time"
+
+	"github.com/zvxte/kera/model"
+	"github.com/zvxte/kera/model/uuid"
+)
+
+// Passkey represents a WebAuthn credential of a user.
+// All time fields are in UTC.
+type Passkey struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+	Name   string
+
+	CredentialID []byte
+
+	// PublicKey is the CBOR encoded COSE_Key of the credential.
+	PublicKey []byte
+
+	// SignCount is the last sign counter reported by the authenticator.
+	SignCount uint32
+
+	CreationTime time.Time
+
+	// LastUsedTime is zero if the passkey was never used to log in.
+	LastUsedTime time.Time
+}
+
+// New returns a new *Passkey.
+// It fails if the provided name does not meet the application requirements.
+// The returned error is safe for client-side message.
+// The CreationTime field is set to the current time.
+func New(
+	userID uuid.UUID, name string,
+	credentialID, publicKey []byte, signCount uint32,
+) (*Passkey, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, model.ErrUnexpected
+	}
+
+	return &Passkey{
+		ID:           id,
+		UserID:       userID,
+		Name:         name,
+		CredentialID: credentialID,
+		PublicKey:    publicKey,
+		SignCount:    signCount,
+		CreationTime: time.Now().UTC(),
+	}, nil
+}
+
+// Load returns a *Passkey from provided parameters.
+func Load(
+	id, userID uuid.UUID, name string,
+	credentialID, publicKey []byte, signCount uint32,
+	creationTime, lastUsedTime time.Time,
+) *Passkey {
+	return &Passkey{
+		ID:           id,
+		UserID:       userID,
+		Name:         name,
+		CredentialID: credentialID,
+		PublicKey:    publicKey,
+		SignCount:    signCount,
+		CreationTime: creationTime.UTC(),
+		LastUsedTime: lastUsedTime.UTC(),
+	}
+}
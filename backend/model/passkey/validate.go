@@ -0,0 +1,46 @@
+package passkey
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	nameMinChars = 1
+	nameMaxChars = 64
+)
+
+// ValidateName fails if the provided passkey name
+// does not meet the application requirements.
+// The returned error is safe for client-side message.
+func ValidateName(name string) error {
+	// Prevents from counting runes on a large string
+	if len(name) > nameMaxChars*4 {
+		return ErrNameTooLong
+	}
+
+	length := utf8.RuneCountInString(name)
+	if length < nameMinChars {
+		return ErrNameTooShort
+	}
+	if length > nameMaxChars {
+		return ErrNameTooLong
+	}
+
+	if !utf8.ValidString(name) {
+		return ErrNameInvalid
+	}
+
+	for _, r := range name {
+		if unicode.IsControl(r) || (unicode.IsSpace(r) && r != ' ') {
+			return ErrNameInvalid
+		}
+	}
+
+	if strings.TrimSpace(name) != name {
+		return ErrNameInvalid
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package passkey
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		shouldErr bool
+	}{
+		{
+			"Valid",
+			"Laptop",
+			false,
+		},
+		{
+			"Valid: unicode",
+			"Téléphone de Zoë",
+			false,
+		},
+		{
+			"Invalid: empty",
+			"",
+			true,
+		},
+		{
+			"Invalid: too long",
+			strings.Repeat("a", nameMaxChars+1),
+			true,
+		},
+		{
+			"Invalid: leading space",
+			" Laptop",
+			true,
+		},
+		{
+			"Invalid: control character",
+			"Lap\ttop",
+			true,
+		},
+		{
+			"Invalid: byte sequence",
+			"Lap\x80top",
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateName(test.input)
+			if (err != nil) != test.shouldErr {
+				t.Errorf(
+					"ValidateName(%q), error=%v, shouldErr=%v",
+					test.input, err, test.shouldErr,
+				)
+			}
+		})
+	}
+}
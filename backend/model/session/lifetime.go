@@ -0,0 +1,51 @@
+package session
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrInvalidLifetime = errors.New(
+	"session lifetime is invalid: idle timeout must be positive " +
+		"and not greater than absolute timeout",
+)
+
+// DefaultLifetime represents the lifetime used if none is configured.
+var DefaultLifetime = Lifetime{
+	AbsoluteTimeout: 30 * 24 * time.Hour,
+	IdleTimeout:     7 * 24 * time.Hour,
+}
+
+// Lifetime represents timeouts after which a session expires.
+type Lifetime struct {
+	// AbsoluteTimeout is the maximum duration of a session since its creation.
+	AbsoluteTimeout time.Duration
+
+	// IdleTimeout is the maximum duration of a session without any activity.
+	IdleTimeout time.Duration
+}
+
+// NewLifetime returns a new Lifetime.
+// It fails if the idle timeout is not positive,
+// or if it is greater than the absolute timeout.
+func NewLifetime(absoluteTimeout, idleTimeout time.Duration) (Lifetime, error) {
+	if idleTimeout <= 0 || idleTimeout > absoluteTimeout {
+		return Lifetime{}, ErrInvalidLifetime
+	}
+
+	return Lifetime{
+		AbsoluteTimeout: absoluteTimeout,
+		IdleTimeout:     idleTimeout,
+	}, nil
+}
+
+// expirationTime returns the expiration time of a session created
+// at creationTime and last active at now.
+func (l Lifetime) expirationTime(creationTime, now time.Time) time.Time {
+	idle := now.Add(l.IdleTimeout)
+	absolute := creationTime.Add(l.AbsoluteTimeout)
+	if idle.After(absolute) {
+		return absolute
+	}
+	return idle
+}
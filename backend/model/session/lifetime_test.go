@@ -0,0 +1,33 @@
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewLifetime(t *testing.T) {
+	tests := []struct {
+		name            string
+		absoluteTimeout time.Duration
+		idleTimeout     time.Duration
+		shouldErr       bool
+	}{
+		{"Valid", 30 * 24 * time.Hour, 7 * 24 * time.Hour, false},
+		{"Valid: equal", 24 * time.Hour, 24 * time.Hour, false},
+		{"Invalid: zero idle timeout", 24 * time.Hour, 0, true},
+		{"Invalid: negative idle timeout", 24 * time.Hour, -time.Hour, true},
+		{"Invalid: idle greater than absolute", time.Hour, 24 * time.Hour, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewLifetime(test.absoluteTimeout, test.idleTimeout)
+			if (err != nil) != test.shouldErr {
+				t.Errorf(
+					"NewLifetime(%v, %v), error=%v, shouldErr=%v",
+					test.absoluteTimeout, test.idleTimeout, err, test.shouldErr,
+				)
+			}
+		})
+	}
+}
@@ -1,55 +1,133 @@
 package session
 
 import (
+	"fmt"
 	"time"
+	"unicode/utf8"
 
 	"github.com/zvxte/kera/hash/sha256"
-	"github.com/zvxte/kera/model/date"
 	"github.com/zvxte/kera/model/uuid"
 )
 
 const (
-	HashedIDLen        = 32
-	expirationDuration = 24 * time.Hour * 30
+	HashedIDLen       = 32
+	UserAgentMaxChars = 256
+
+	// LastSeenPrecision represents how stale the LastSeenTime field
+	// can get before it has to be written again.
+	LastSeenPrecision = 5 * time.Minute
 )
 
 // HashedID represents a hashed session ID.
 type HashedID [HashedIDLen]byte
 
 // Session represents a user's session in the application.
+// All time fields are in UTC.
 type Session struct {
 	HashedID       HashedID
+	PublicID       uuid.UUID
 	UserID         uuid.UUID
-	CreationDate   date.Date
-	ExpirationDate date.Date
+	UserAgent      string
+	IPAddress      string
+	CreationTime   time.Time
+	ExpirationTime time.Time
+	LastSeenTime   time.Time
 }
 
 // New returns a new *Session.
+// It fails if the system's source of randomness is unavailable.
 // The sessionID is hashed using sha256.
-// The CreationDate field is set to the current Date value.
-// The ExpirationDate field is set to the CreationDate + sessionExpirationDuration constant.
-func New(sessionID string, userID uuid.UUID) *Session {
+// The PublicID field is set to a new UUID, that can be safely
+// shown to the client in place of the hashed session ID.
+// The userAgent is truncated to the UserAgentMaxChars constant.
+// The CreationTime and LastSeenTime fields are set to the current time.
+// The ExpirationTime field is set according to the provided lifetime.
+func New(
+	sessionID string, userID uuid.UUID, userAgent, ipAddress string,
+	lifetime Lifetime,
+) (*Session, error) {
 	hashedID := sha256.Hash(sessionID)
 
-	creationDate := date.Now()
-	expirationDate := creationDate.Add(expirationDuration)
+	publicID, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	now := time.Now().UTC()
 
 	return &Session{
 		HashedID:       hashedID,
+		PublicID:       publicID,
 		UserID:         userID,
-		CreationDate:   creationDate,
-		ExpirationDate: expirationDate,
-	}
+		UserAgent:      truncateUserAgent(userAgent),
+		IPAddress:      ipAddress,
+		CreationTime:   now,
+		ExpirationTime: lifetime.expirationTime(now, now),
+		LastSeenTime:   now,
+	}, nil
 }
 
 // Load returns a *Session from provided parameters.
 func Load(
-	hashedID HashedID, userID uuid.UUID, creationDate, expirationDate date.Date,
+	hashedID HashedID, publicID, userID uuid.UUID,
+	userAgent, ipAddress string,
+	creationTime, expirationTime, lastSeenTime time.Time,
 ) *Session {
 	return &Session{
 		HashedID:       hashedID,
+		PublicID:       publicID,
 		UserID:         userID,
-		CreationDate:   creationDate,
-		ExpirationDate: expirationDate,
+		UserAgent:      userAgent,
+		IPAddress:      ipAddress,
+		CreationTime:   creationTime.UTC(),
+		ExpirationTime: expirationTime.UTC(),
+		LastSeenTime:   lastSeenTime.UTC(),
+	}
+}
+
+// Expired returns true if the session is no longer valid at the provided time.
+// The absolute timeout of the lifetime is checked as well,
+// so lowering it also affects already existing sessions.
+func (s *Session) Expired(lifetime Lifetime, now time.Time) bool {
+	return !now.Before(s.ExpirationTime) ||
+		!now.Before(s.CreationTime.Add(lifetime.AbsoluteTimeout))
+}
+
+// NeedsRenewal returns true if the session is past half of its idle window
+// and its expiration time can still be extended.
+func (s *Session) NeedsRenewal(lifetime Lifetime, now time.Time) bool {
+	remaining := s.ExpirationTime.Sub(now)
+	if remaining >= lifetime.IdleTimeout/2 {
+		return false
+	}
+	return s.ExpirationTime.Before(lifetime.expirationTime(s.CreationTime, now))
+}
+
+// Renew extends the ExpirationTime field by the idle timeout of the lifetime,
+// capped at the absolute timeout, and sets the LastSeenTime field to now.
+func (s *Session) Renew(lifetime Lifetime, now time.Time) {
+	s.ExpirationTime = lifetime.expirationTime(s.CreationTime, now)
+	s.LastSeenTime = now
+}
+
+// LastSeenStale returns true if the LastSeenTime field
+// is older than the LastSeenPrecision constant.
+func (s *Session) LastSeenStale(now time.Time) bool {
+	return now.Sub(s.LastSeenTime) > LastSeenPrecision
+}
+
+// truncateUserAgent returns the userAgent cut down to UserAgentMaxChars runes.
+// Invalid UTF-8 sequences are dropped.
+func truncateUserAgent(userAgent string) string {
+	runes := make([]rune, 0, min(len(userAgent), UserAgentMaxChars))
+	for _, r := range userAgent {
+		if len(runes) == UserAgentMaxChars {
+			break
+		}
+		if r == utf8.RuneError {
+			continue
+		}
+		runes = append(runes, r)
 	}
+	return string(runes)
 }
@@ -0,0 +1,218 @@
+package session
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/zvxte/kera/model/uuid"
+)
+
+func TestNew(t *testing.T) {
+	s, err := New(
+		"2IBEnhqxKuWE5dhXF4IaQJrBrSygJnRq", uuid.UUID{},
+		"Mozilla/5.0", "127.0.0.1", DefaultLifetime,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.PublicID == (uuid.UUID{}) {
+		t.Errorf("New(), PublicID is not set")
+	}
+	if !s.LastSeenTime.Equal(s.CreationTime) {
+		t.Errorf(
+			"New(), LastSeenTime=%q, CreationTime=%q",
+			s.LastSeenTime, s.CreationTime,
+		)
+	}
+	expected := s.CreationTime.Add(DefaultLifetime.IdleTimeout)
+	if !s.ExpirationTime.Equal(expected) {
+		t.Errorf(
+			"New(), ExpirationTime=%q, expected=%q",
+			s.ExpirationTime, expected,
+		)
+	}
+}
+
+func TestExpired(t *testing.T) {
+	creationTime := time.Date(2024, 11, 10, 12, 0, 0, 0, time.UTC)
+	lifetime := Lifetime{
+		AbsoluteTimeout: 10 * 24 * time.Hour,
+		IdleTimeout:     24 * time.Hour,
+	}
+
+	tests := []struct {
+		name           string
+		expirationTime time.Time
+		now            time.Time
+		expected       bool
+	}{
+		{
+			"Valid: not expired",
+			creationTime.Add(24 * time.Hour),
+			creationTime.Add(time.Hour),
+			false,
+		},
+		{
+			"Valid: idle timeout passed",
+			creationTime.Add(24 * time.Hour),
+			creationTime.Add(24 * time.Hour),
+			true,
+		},
+		{
+			"Valid: absolute timeout passed",
+			creationTime.Add(20 * 24 * time.Hour),
+			creationTime.Add(11 * 24 * time.Hour),
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := &Session{
+				CreationTime:   creationTime,
+				ExpirationTime: test.expirationTime,
+			}
+			got := s.Expired(lifetime, test.now)
+			if got != test.expected {
+				t.Errorf(
+					"Session.Expired(%v, %q), got=%v, expected=%v",
+					lifetime, test.now, got, test.expected,
+				)
+			}
+		})
+	}
+}
+
+func TestNeedsRenewal(t *testing.T) {
+	creationTime := time.Date(2024, 11, 10, 12, 0, 0, 0, time.UTC)
+	lifetime := Lifetime{
+		AbsoluteTimeout: 10 * 24 * time.Hour,
+		IdleTimeout:     24 * time.Hour,
+	}
+
+	tests := []struct {
+		name           string
+		expirationTime time.Time
+		now            time.Time
+		expected       bool
+	}{
+		{
+			"Valid: within first half of idle window",
+			creationTime.Add(24 * time.Hour),
+			creationTime.Add(6 * time.Hour),
+			false,
+		},
+		{
+			"Valid: past half of idle window",
+			creationTime.Add(24 * time.Hour),
+			creationTime.Add(18 * time.Hour),
+			true,
+		},
+		{
+			"Valid: capped at absolute timeout",
+			creationTime.Add(10 * 24 * time.Hour),
+			creationTime.Add(10*24*time.Hour - time.Hour),
+			false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := &Session{
+				CreationTime:   creationTime,
+				ExpirationTime: test.expirationTime,
+			}
+			got := s.NeedsRenewal(lifetime, test.now)
+			if got != test.expected {
+				t.Errorf(
+					"Session.NeedsRenewal(%v, %q), got=%v, expected=%v",
+					lifetime, test.now, got, test.expected,
+				)
+			}
+		})
+	}
+}
+
+func TestRenew(t *testing.T) {
+	creationTime := time.Date(2024, 11, 10, 12, 0, 0, 0, time.UTC)
+	lifetime := Lifetime{
+		AbsoluteTimeout: 10 * 24 * time.Hour,
+		IdleTimeout:     24 * time.Hour,
+	}
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		expected time.Time
+	}{
+		{
+			"Valid",
+			creationTime.Add(18 * time.Hour),
+			creationTime.Add(42 * time.Hour),
+		},
+		{
+			"Valid: capped at absolute timeout",
+			creationTime.Add(9*24*time.Hour + 18*time.Hour),
+			creationTime.Add(10 * 24 * time.Hour),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := &Session{
+				CreationTime:   creationTime,
+				ExpirationTime: test.now,
+			}
+			s.Renew(lifetime, test.now)
+			if !s.ExpirationTime.Equal(test.expected) || !s.LastSeenTime.Equal(test.now) {
+				t.Errorf(
+					"Session.Renew(%v, %q), got=%q, expected=%q",
+					lifetime, test.now, s.ExpirationTime, test.expected,
+				)
+			}
+		})
+	}
+}
+
+func TestTruncateUserAgent(t *testing.T) {
+	tests := []struct {
+		name      string
+		userAgent string
+		expected  string
+	}{
+		{
+			"Valid",
+			"Mozilla/5.0 (X11; Linux x86_64)",
+			"Mozilla/5.0 (X11; Linux x86_64)",
+		},
+		{
+			"Valid: empty",
+			"",
+			"",
+		},
+		{
+			"Valid: too long",
+			strings.Repeat("a", UserAgentMaxChars+1),
+			strings.Repeat("a", UserAgentMaxChars),
+		},
+		{
+			"Valid: byte sequence",
+			"Mozilla\x80/5.0",
+			"Mozilla/5.0",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := truncateUserAgent(test.userAgent)
+			if got != test.expected || !utf8.ValidString(got) {
+				t.Errorf(
+					"truncateUserAgent(%q), got=%q, expected=%q",
+					test.userAgent, got, test.expected,
+				)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package token
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/zvxte/kera/hash/sha256"
+	"github.com/zvxte/kera/model/uuid"
+)
+
+const (
+	HashedIDLen = 32
+
+	// idBytes represents the number of random bytes of a token ID.
+	idBytes = 32
+	// idLen represents the length of a base64 (URL, no padding) encoded token ID.
+	idLen = 43
+)
+
+// HashedID represents a hashed token ID.
+type HashedID [HashedIDLen]byte
+
+// Purpose represents what a token can be used for.
+type Purpose uint8
+
+const (
+	PasswordReset Purpose = iota
+	EmailVerification
+)
+
+// Lifetime returns how long a token of the purpose is valid.
+func (p Purpose) Lifetime() time.Duration {
+	switch p {
+	case PasswordReset:
+		return time.Hour
+	case EmailVerification:
+		return 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// Token represents a single-use secret sent to a user out of band,
+// for example by email. Only its hash is ever stored.
+// All time fields are in UTC.
+type Token struct {
+	HashedID       HashedID
+	UserID         uuid.UUID
+	Purpose        Purpose
+	Email          string
+	CreationTime   time.Time
+	ExpirationTime time.Time
+}
+
+// New returns a new token ID and its *Token.
+// It fails if the system's source of randomness is unavailable.
+// The email is the address the token is sent to,
+// so an email verification token can't verify a different address.
+// The ExpirationTime field is set to now + the purpose's lifetime.
+func New(userID uuid.UUID, purpose Purpose, email string) (string, *Token, error) {
+	id, err := NewID()
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now().UTC()
+
+	return id, &Token{
+		HashedID:       HashID(id),
+		UserID:         userID,
+		Purpose:        purpose,
+		Email:          email,
+		CreationTime:   now,
+		ExpirationTime: now.Add(purpose.Lifetime()),
+	}, nil
+}
+
+// Load returns a *Token from provided parameters.
+func Load(
+	hashedID HashedID, userID uuid.UUID, purpose Purpose, email string,
+	creationTime, expirationTime time.Time,
+) *Token {
+	return &Token{
+		HashedID:       hashedID,
+		UserID:         userID,
+		Purpose:        purpose,
+		Email:          email,
+		CreationTime:   creationTime.UTC(),
+		ExpirationTime: expirationTime.UTC(),
+	}
+}
+
+// NewID returns a new randomly generated token ID as a string.
+// It fails if the system's source of randomness is unavailable.
+func NewID() (string, error) {
+	b := make([]byte, idBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token ID: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashID returns the token ID hashed using sha256.
+func HashID(id string) HashedID {
+	return sha256.Hash(id)
+}
+
+// ValidateID returns true if the provided token ID
+// meets the application requirements, else false.
+func ValidateID(id string) bool {
+	if len(id) != idLen {
+		return false
+	}
+	_, err := base64.RawURLEncoding.DecodeString(id)
+	return err == nil
+}
@@ -0,0 +1,67 @@
+package token
+
+import (
+	"testing"
+
+	"github.com/zvxte/kera/model/uuid"
+)
+
+func TestNew(t *testing.T) {
+	id, token, err := New(uuid.UUID{}, PasswordReset, "user@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ValidateID(id) {
+		t.Errorf("New(), ValidateID(%q)=false", id)
+	}
+	if token.HashedID != HashID(id) {
+		t.Errorf("New(), HashedID does not match the ID")
+	}
+	if got := token.ExpirationTime.Sub(token.CreationTime); got != PasswordReset.Lifetime() {
+		t.Errorf(
+			"New(), lifetime=%v, expected=%v",
+			got, PasswordReset.Lifetime(),
+		)
+	}
+}
+
+func TestValidateID(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       string
+		expected bool
+	}{
+		{
+			"Valid",
+			"q6pL3QJ0t0I9bDcbb0Q3z9q3nV2u8a1m2kQ5C7x0b8Y",
+			true,
+		},
+		{
+			"Invalid: too short",
+			"q6pL3QJ0t0I9bDcbb0Q3z9q3nV2u8a1m2kQ5C7x0b8",
+			false,
+		},
+		{
+			"Invalid: too long",
+			"q6pL3QJ0t0I9bDcbb0Q3z9q3nV2u8a1m2kQ5C7x0b8YY",
+			false,
+		},
+		{
+			"Invalid: characters",
+			"q6pL3QJ0t0I9bDcbb0Q3z9q3nV2u8a1m2kQ5C7x0b8+",
+			false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := ValidateID(test.id)
+			if got != test.expected {
+				t.Errorf(
+					"ValidateID(%q), got=%v, expected=%v",
+					test.id, got, test.expected,
+				)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package user
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const breachedPrefixLength = 5
+
+var ErrInvalidBreachedList = errors.New("breached password list is invalid")
+
+// BreachedPasswords is a set of SHA-1 hashes of known breached passwords,
+// e.g. a subset of the Pwned Passwords dataset.
+type BreachedPasswords struct {
+	hashes map[[sha1.Size]byte]struct{}
+}
+
+// NewBreachedPasswords returns a new empty *BreachedPasswords.
+func NewBreachedPasswords() *BreachedPasswords {
+	return &BreachedPasswords{hashes: make(map[[sha1.Size]byte]struct{})}
+}
+
+// LoadBreachedPasswords returns a new *BreachedPasswords read from the path.
+// The path is either a file with a full hex SHA-1 hash per line,
+// or a directory of k-anonymity range files named after the 5 character
+// hash prefix (e.g. "21BD1" or "21BD1.txt") with a hash suffix per line.
+// Lines may end with ":COUNT", which is ignored.
+// It fails if the path can't be read or a line is not a valid hash.
+func LoadBreachedPasswords(path string) (*BreachedPasswords, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load breached passwords: %w", err)
+	}
+
+	b := NewBreachedPasswords()
+
+	if !info.IsDir() {
+		if err := b.readFile(path, ""); err != nil {
+			return nil, err
+		}
+		return b, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load breached passwords: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		prefix := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		if len(prefix) != breachedPrefixLength {
+			continue
+		}
+		if _, err := hex.DecodeString(prefix + "0"); err != nil {
+			continue
+		}
+
+		if err := b.readFile(filepath.Join(path, entry.Name()), prefix); err != nil {
+			return nil, err
+		}
+	}
+
+	return b, nil
+}
+
+func (b *BreachedPasswords) readFile(path, prefix string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to load breached passwords: %w", err)
+	}
+	defer file.Close()
+
+	if err := b.Read(file, prefix); err != nil {
+		return fmt.Errorf("failed to load breached passwords from %s: %w", path, err)
+	}
+	return nil
+}
+
+// Read adds the hashes read from r, each line joined with the prefix
+// must be a full hex SHA-1 hash, optionally followed by ":COUNT".
+// Empty lines are skipped.
+func (b *BreachedPasswords) Read(r io.Reader, prefix string) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		line, _, _ = strings.Cut(line, ":")
+		encoded := prefix + line
+		if len(encoded) != hex.EncodedLen(sha1.Size) {
+			return ErrInvalidBreachedList
+		}
+
+		var hash [sha1.Size]byte
+		if _, err := hex.Decode(hash[:], []byte(encoded)); err != nil {
+			return ErrInvalidBreachedList
+		}
+
+		b.hashes[hash] = struct{}{}
+	}
+
+	return scanner.Err()
+}
+
+// Contains returns true if the plain password is a known breached password.
+func (b *BreachedPasswords) Contains(plainPassword string) bool {
+	_, ok := b.hashes[sha1.Sum([]byte(plainPassword))]
+	return ok
+}
+
+// Len returns the number of known breached passwords.
+func (b *BreachedPasswords) Len() int {
+	return len(b.hashes)
+}
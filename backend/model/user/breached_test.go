@@ -0,0 +1,104 @@
+package user
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBreachedPasswordsRead(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		prefix    string
+		shouldLen int
+		shouldErr bool
+	}{
+		{"Valid: full hashes", "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8\n\n7C4A8D09CA3762AF61E59520943DC26494F8941B\n", "", 2, false},
+		{"Valid: counts", "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:9545824\r\n", "", 1, false},
+		{"Valid: range file", "1E4C9B93F3F0682250B6CF8331B7EE68FD8:9545824\n", "5BAA6", 1, false},
+		{"Valid: lowercase", "5baa61e4c9b93f3f0682250b6cf8331b7ee68fd8", "", 1, false},
+		{"Invalid: short hash", "5BAA61E4C9B93F3F0682250B6CF8331B7EE68F", "", 0, true},
+		{"Invalid: long hash", "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8AA", "", 0, true},
+		{"Invalid: not hex", "password", "", 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b := NewBreachedPasswords()
+			err := b.Read(strings.NewReader(test.input), test.prefix)
+			if (err != nil) != test.shouldErr {
+				t.Fatalf(
+					"Read(%q, %q), error=%v, shouldErr=%v",
+					test.input, test.prefix, err, test.shouldErr,
+				)
+			}
+			if err == nil && b.Len() != test.shouldLen {
+				t.Errorf(
+					"Read(%q, %q), len=%v, expected=%v",
+					test.input, test.prefix, b.Len(), test.shouldLen,
+				)
+			}
+		})
+	}
+}
+
+func TestLoadBreachedPasswords(t *testing.T) {
+	dir := t.TempDir()
+	rangeDir := filepath.Join(dir, "range")
+	files := map[string]string{
+		"full.txt":          "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:1\n",
+		"range/5BAA6.txt":   "1E4C9B93F3F0682250B6CF8331B7EE68FD8:9545824\n",
+		"range/7C4A8":       "D09CA3762AF61E59520943DC26494F8941B:2\n",
+		"range/README.md":   "ignored",
+		"invalid/5BAA6.txt": "password\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name          string
+		path          string
+		plainPassword string
+		shouldBe      bool
+		shouldErr     bool
+	}{
+		{"Valid: file", filepath.Join(dir, "full.txt"), "password", true, false},
+		{"Valid: file, not breached", filepath.Join(dir, "full.txt"), "123456", false, false},
+		{"Valid: directory", rangeDir, "password", true, false},
+		{"Valid: directory, no extension", rangeDir, "123456", true, false},
+		{"Valid: directory, not breached", rangeDir, "qwzmxkrt", false, false},
+		{"Invalid: missing", filepath.Join(dir, "missing"), "", false, true},
+		{"Invalid: invalid file", filepath.Join(dir, "invalid"), "", false, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b, err := LoadBreachedPasswords(test.path)
+			if (err != nil) != test.shouldErr {
+				t.Fatalf(
+					"LoadBreachedPasswords(%q), error=%v, shouldErr=%v",
+					test.path, err, test.shouldErr,
+				)
+			}
+			if err != nil {
+				return
+			}
+
+			if contains := b.Contains(test.plainPassword); contains != test.shouldBe {
+				t.Errorf(
+					"Contains(%q), got=%v, expected=%v",
+					test.plainPassword, contains, test.shouldBe,
+				)
+			}
+		})
+	}
+}
@@ -3,12 +3,19 @@ package user
 import "errors"
 
 var (
-	ErrUsernameTooShort    = errors.New("username is too short")
-	ErrUsernameTooLong     = errors.New("username is too long")
-	ErrUsernameInvalid     = errors.New("username is invalid")
-	ErrDisplayNameTooShort = errors.New("display name is too short")
-	ErrDisplayNameTooLong  = errors.New("display name is too long")
-	ErrDisplayNameInvalid  = errors.New("display name is invalid")
-	ErrPasswordTooShort    = errors.New("password is too short")
-	ErrPasswordTooLong     = errors.New("password is too long")
+	ErrUsernameTooShort         = errors.New("username is too short")
+	ErrUsernameTooLong          = errors.New("username is too long")
+	ErrUsernameInvalid          = errors.New("username is invalid")
+	ErrDisplayNameTooShort      = errors.New("display name is too short")
+	ErrDisplayNameTooLong       = errors.New("display name is too long")
+	ErrDisplayNameInvalid       = errors.New("display name is invalid")
+	ErrPasswordTooShort         = errors.New("password is too short")
+	ErrPasswordTooLong          = errors.New("password is too long")
+	ErrPasswordTooWeak          = errors.New("password is too weak, use a longer password with more kinds of characters")
+	ErrPasswordContainsUsername = errors.New("password must not contain the username")
+	ErrPasswordBreached         = errors.New("password is known from data breaches, choose a different one")
+	ErrEmailTooLong             = errors.New("email is too long")
+	ErrEmailInvalid             = errors.New("email is invalid")
+	ErrRoleInvalid              = errors.New("role is invalid")
+	ErrStatusInvalid            = errors.New("status is invalid")
 )
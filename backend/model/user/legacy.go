@@ -0,0 +1,34 @@
+package user
+
+import "github.com/zvxte/kera/hash/bcrypt"
+
+// LegacyHasher is a [PasswordHasher] that also verifies bcrypt hashes
+// imported from other applications, and reports them to be rehashed,
+// so they are upgraded to the wrapped hasher's hashes on the next login.
+// New hashes are created by the wrapped hasher.
+type LegacyHasher struct {
+	hasher PasswordHasher
+}
+
+// NewLegacyHasher returns a new *LegacyHasher wrapping the provided hasher.
+func NewLegacyHasher(hasher PasswordHasher) *LegacyHasher {
+	return &LegacyHasher{hasher: hasher}
+}
+
+func (h *LegacyHasher) Hash(plainPassword string) (string, error) {
+	return h.hasher.Hash(plainPassword)
+}
+
+func (h *LegacyHasher) Verify(plainPassword, hashedPassword string) (bool, error) {
+	if bcrypt.IsHash(hashedPassword) {
+		return bcrypt.VerifyHash(plainPassword, hashedPassword)
+	}
+	return h.hasher.Verify(plainPassword, hashedPassword)
+}
+
+func (h *LegacyHasher) NeedsRehash(hashedPassword string) (bool, error) {
+	if bcrypt.IsHash(hashedPassword) {
+		return true, nil
+	}
+	return h.hasher.NeedsRehash(hashedPassword)
+}
@@ -0,0 +1,68 @@
+package user
+
+import (
+	"testing"
+
+	"github.com/zvxte/kera/hash/argon2id"
+)
+
+func TestLegacyHasher(t *testing.T) {
+	argon2idHasher, err := argon2id.NewHasher(argon2id.DefaultParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hasher := NewLegacyHasher(argon2idHasher)
+
+	argon2idHash, err := hasher.Hash("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bcryptHash := "$2a$04$2qeqSEzx1Lw5ya3n/N6lJ.V7SL2nlBC9wP65ThvvUutDdWcGYw/by"
+
+	tests := []struct {
+		name             string
+		plainPassword    string
+		hashedPassword   string
+		shouldBe         bool
+		shouldNeedRehash bool
+		shouldErr        bool
+	}{
+		{"Valid: argon2id", "password", argon2idHash, true, false, false},
+		{"Valid: wrong argon2id input", "passw0rd", argon2idHash, false, false, false},
+		{"Valid: bcrypt", "password", bcryptHash, true, true, false},
+		{"Valid: wrong bcrypt input", "passw0rd", bcryptHash, false, true, false},
+		{"Invalid: hash", "password", "password", false, false, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			isValid, err := hasher.Verify(test.plainPassword, test.hashedPassword)
+			if (err != nil) != test.shouldErr {
+				t.Fatalf(
+					"Verify(%q, %q), error=%v, shouldErr=%v",
+					test.plainPassword, test.hashedPassword, err, test.shouldErr,
+				)
+			}
+			if isValid != test.shouldBe {
+				t.Errorf(
+					"Verify(%q, %q), got=%v, expected=%v",
+					test.plainPassword, test.hashedPassword, isValid, test.shouldBe,
+				)
+			}
+			if test.shouldErr {
+				return
+			}
+
+			needsRehash, err := hasher.NeedsRehash(test.hashedPassword)
+			if err != nil {
+				t.Fatalf("NeedsRehash(%q), error=%v", test.hashedPassword, err)
+			}
+			if needsRehash != test.shouldNeedRehash {
+				t.Errorf(
+					"NeedsRehash(%q), got=%v, expected=%v",
+					test.hashedPassword, needsRehash, test.shouldNeedRehash,
+				)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package user
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// DefaultMinPasswordEntropy is the minimum estimated password entropy
+// in bits of [DefaultPasswordPolicy].
+const DefaultMinPasswordEntropy = 30
+
+// DefaultPasswordPolicy is a policy with the default minimum entropy,
+// rejecting passwords that contain the username, without a breached list.
+var DefaultPasswordPolicy = &PasswordPolicy{
+	MinEntropy:     DefaultMinPasswordEntropy,
+	RejectUsername: true,
+}
+
+// PasswordPolicy describes requirements for new passwords,
+// on top of [ValidatePlainPassword].
+// Existing passwords are not checked against it, so it can be
+// tightened without locking users out.
+type PasswordPolicy struct {
+	// MinEntropy is the minimum result of [EstimateEntropy], 0 disables the check.
+	MinEntropy float64
+
+	// RejectUsername rejects passwords containing the username, ignoring case.
+	RejectUsername bool
+
+	// Breached rejects known breached passwords, nil disables the check.
+	Breached *BreachedPasswords
+}
+
+// Validate fails if the provided plain password does not meet the policy.
+// An empty username skips the username check.
+// A nil policy validates against [DefaultPasswordPolicy].
+// The returned error is safe for client-side message.
+func (p *PasswordPolicy) Validate(username, plainPassword string) error {
+	if p == nil {
+		p = DefaultPasswordPolicy
+	}
+
+	if err := ValidatePlainPassword(plainPassword); err != nil {
+		return err
+	}
+
+	if p.RejectUsername && username != "" &&
+		strings.Contains(strings.ToLower(plainPassword), strings.ToLower(username)) {
+		return ErrPasswordContainsUsername
+	}
+
+	if EstimateEntropy(plainPassword) < p.MinEntropy {
+		return ErrPasswordTooWeak
+	}
+
+	if p.Breached != nil && p.Breached.Contains(plainPassword) {
+		return ErrPasswordBreached
+	}
+
+	return nil
+}
+
+// EstimateEntropy returns a rough estimate of the password entropy in bits.
+// Every character adds log2 of the pool size, where the pool consists of
+// the character classes used in the password (lowercase, uppercase,
+// digits, symbols, other). Characters repeating or continuing a sequence
+// from the previous one add 1 bit, characters used before add half.
+func EstimateEntropy(plainPassword string) float64 {
+	var hasLower, hasUpper, hasDigit, hasSymbol, hasOther bool
+	for _, r := range plainPassword {
+		switch {
+		case 'a' <= r && r <= 'z':
+			hasLower = true
+		case 'A' <= r && r <= 'Z':
+			hasUpper = true
+		case '0' <= r && r <= '9':
+			hasDigit = true
+		case r < unicode.MaxASCII:
+			hasSymbol = true
+		default:
+			hasOther = true
+		}
+	}
+
+	pool := 0
+	if hasLower {
+		pool += 26
+	}
+	if hasUpper {
+		pool += 26
+	}
+	if hasDigit {
+		pool += 10
+	}
+	if hasSymbol {
+		pool += 33
+	}
+	if hasOther {
+		pool += 100
+	}
+	if pool == 0 {
+		return 0
+	}
+	bitsPerChar := math.Log2(float64(pool))
+
+	var entropy float64
+	seen := make(map[rune]bool)
+	previous := rune(-1)
+	for _, r := range plainPassword {
+		switch {
+		case r == previous || r == previous+1 || r == previous-1:
+			entropy += 1
+		case seen[r]:
+			entropy += bitsPerChar / 2
+		default:
+			entropy += bitsPerChar
+		}
+		seen[r] = true
+		previous = r
+	}
+
+	return entropy
+}
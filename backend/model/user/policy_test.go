@@ -0,0 +1,84 @@
+package user
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	breached := NewBreachedPasswords()
+	err := breached.Read(strings.NewReader(
+		"5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:9545824\n", // password
+	), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policy := &PasswordPolicy{
+		MinEntropy:     DefaultMinPasswordEntropy,
+		RejectUsername: true,
+		Breached:       breached,
+	}
+
+	tests := []struct {
+		name          string
+		policy        *PasswordPolicy
+		username      string
+		plainPassword string
+		shouldErr     bool
+	}{
+		{"Valid", policy, "username", "correct horse battery", false},
+		{"Valid: no username", policy, "", "my_username_1", false},
+		{"Valid: username allowed", &PasswordPolicy{}, "username", "my_username", false},
+		{"Valid: empty policy", &PasswordPolicy{}, "username", "aaaaaaaa", false},
+		{"Valid: nil policy", nil, "username", "correct horse battery", false},
+		{"Invalid: too short", policy, "username", "aB3$", true},
+		{"Invalid: contains username", policy, "username", "my_username_1", true},
+		{"Invalid: contains username, case", policy, "username", "my_UserName_1", true},
+		{"Invalid: repeated", policy, "username", "aaaaaaaaaaaa", true},
+		{"Invalid: sequence", policy, "username", "123456789", true},
+		{"Invalid: breached", policy, "username", "password", true},
+		{"Invalid: nil policy, contains username", nil, "username", "my_username_1", true},
+		{"Invalid: nil policy, repeated", nil, "username", "aaaaaaaaaaaa", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.policy.Validate(test.username, test.plainPassword)
+			if (err != nil) != test.shouldErr {
+				t.Errorf(
+					"Validate(%q, %q), error=%v, shouldErr=%v",
+					test.username, test.plainPassword, err, test.shouldErr,
+				)
+			}
+		})
+	}
+}
+
+func TestEstimateEntropy(t *testing.T) {
+	tests := []struct {
+		name          string
+		plainPassword string
+		min           float64
+		max           float64
+	}{
+		{"Empty", "", 0, 0},
+		{"Repeated", "aaaaaaaa", 0, 15},
+		{"Sequence", "abcdefgh", 0, 15},
+		{"Lowercase", "qwzmxkrt", 35, 40},
+		{"Mixed", "Tr0ub4dor&3", 65, 75},
+		{"Phrase", "correct horse battery", 70, 90},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			entropy := EstimateEntropy(test.plainPassword)
+			if entropy < test.min || entropy > test.max {
+				t.Errorf(
+					"EstimateEntropy(%q), got=%v, expected between %v and %v",
+					test.plainPassword, entropy, test.min, test.max,
+				)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package user
+
+// Role represents what a user is allowed to do.
+type Role uint8
+
+const (
+	RoleUser Role = iota
+	RoleAdmin
+)
+
+func (r Role) String() string {
+	switch r {
+	case RoleUser:
+		return "user"
+	case RoleAdmin:
+		return "admin"
+	default:
+		return ""
+	}
+}
+
+// Valid returns true if the role is known.
+func (r Role) Valid() bool {
+	return r <= RoleAdmin
+}
+
+// Status represents whether a user can log in.
+type Status uint8
+
+const (
+	StatusActive Status = iota
+
+	// StatusPending is a registered user awaiting an admin approval.
+	StatusPending
+
+	// StatusDisabled is a user disabled by an admin.
+	StatusDisabled
+)
+
+func (s Status) String() string {
+	switch s {
+	case StatusActive:
+		return "active"
+	case StatusPending:
+		return "pending"
+	case StatusDisabled:
+		return "disabled"
+	default:
+		return ""
+	}
+}
+
+// Valid returns true if the status is known.
+func (s Status) Valid() bool {
+	return s <= StatusDisabled
+}
@@ -1,7 +1,6 @@
 package user
 
 import (
-	"github.com/zvxte/kera/hash/argon2id"
 	"github.com/zvxte/kera/model"
 	"github.com/zvxte/kera/model/date"
 	"github.com/zvxte/kera/model/uuid"
@@ -13,20 +12,42 @@ type User struct {
 	Username       string
 	DisplayName    string
 	HashedPassword string
+	Email          string
+	EmailVerified  bool
+	Role           Role
+	Status         Status
 	CreationDate   date.Date
 }
 
+// PasswordHasher hashes and verifies passwords.
+type PasswordHasher interface {
+	// Hash returns the hashed plain password.
+	Hash(plainPassword string) (string, error)
+
+	// Verify returns true if the plain password matches the hashed password.
+	Verify(plainPassword, hashedPassword string) (bool, error)
+
+	// NeedsRehash returns true if the hashed password should be replaced
+	// with a new hash, once the plain password is known.
+	NeedsRehash(hashedPassword string) (bool, error)
+}
+
 // New returns a new *User.
 // It fails if the provided parameters do not meet the application requirements.
 // The returned error is safe for client-side message.
-// The plain password is hashed using Argon2ID.
+// The plain password must meet the provided policy, or [DefaultPasswordPolicy]
+// if it's nil, and it is hashed using the provided hasher.
 // The Username and DisplayName fields are set to the given username,
-func New(username, plainPassword string) (*User, error) {
+// the user is an active regular user.
+func New(
+	username, plainPassword string,
+	policy *PasswordPolicy, hasher PasswordHasher,
+) (*User, error) {
 	if err := ValidateUsername(username); err != nil {
 		return nil, err
 	}
 
-	if err := ValidatePlainPassword(plainPassword); err != nil {
+	if err := policy.Validate(username, plainPassword); err != nil {
 		return nil, err
 	}
 
@@ -35,7 +56,7 @@ func New(username, plainPassword string) (*User, error) {
 		return nil, model.ErrUnexpected
 	}
 
-	hashedPassword, err := argon2id.Hash(plainPassword, argon2id.DefaultParams)
+	hashedPassword, err := hasher.Hash(plainPassword)
 	if err != nil {
 		return nil, model.ErrUnexpected
 	}
@@ -49,12 +70,44 @@ func New(username, plainPassword string) (*User, error) {
 	}, nil
 }
 
+// NewExternal returns a new *User authenticated by an external provider.
+// It fails if the provided parameters do not meet the application requirements.
+// The returned error is safe for client-side message.
+// The HashedPassword field is left empty, such user can't log in
+// with a password until it sets one through a password reset.
+// The DisplayName field falls back to the username if it's invalid.
+// The user is an active regular user.
+func NewExternal(username, displayName string) (*User, error) {
+	if err := ValidateUsername(username); err != nil {
+		return nil, err
+	}
+
+	if err := ValidateDisplayName(displayName); err != nil {
+		displayName = username
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, model.ErrUnexpected
+	}
+
+	return &User{
+		ID:           id,
+		Username:     username,
+		DisplayName:  displayName,
+		CreationDate: date.Now(),
+	}, nil
+}
+
 // Load returns a *User.
 // It fails if the provided parameters do not meet the application requirements.
 // The returned error is safe for client-side message.
+// The email is optional and can be empty.
 func Load(
 	id uuid.UUID,
 	username, displayName, hashedPassword string,
+	email string, emailVerified bool,
+	role Role, status Status,
 	creationDate date.Date,
 ) (*User, error) {
 	if err := ValidateUsername(username); err != nil {
@@ -65,11 +118,44 @@ func Load(
 		return nil, err
 	}
 
+	if email != "" {
+		if err := ValidateEmail(email); err != nil {
+			return nil, err
+		}
+	}
+
+	if !role.Valid() {
+		return nil, ErrRoleInvalid
+	}
+
+	if !status.Valid() {
+		return nil, ErrStatusInvalid
+	}
+
 	return &User{
 		ID:             id,
 		Username:       username,
 		DisplayName:    displayName,
 		HashedPassword: hashedPassword,
+		Email:          email,
+		EmailVerified:  emailVerified,
+		Role:           role,
+		Status:         status,
 		CreationDate:   creationDate,
 	}, nil
 }
+
+// IsAdmin returns true if the user has the admin role.
+func (u *User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
+// IsActive returns true if the user is allowed to log in.
+func (u *User) IsActive() bool {
+	return u.Status == StatusActive
+}
+
+// HasPassword returns true if the user can log in with a password.
+func (u *User) HasPassword() bool {
+	return u.HashedPassword != ""
+}
@@ -3,6 +3,7 @@ package user
 import (
 	"testing"
 
+	"github.com/zvxte/kera/hash/argon2id"
 	"github.com/zvxte/kera/model/date"
 	"github.com/zvxte/kera/model/uuid"
 )
@@ -12,31 +13,63 @@ func TestNew(t *testing.T) {
 		name          string
 		username      string
 		plainPassword string
+		policy        *PasswordPolicy
 		shouldErr     bool
 	}{
 		{
 			"Valid",
 			"username",
 			"password",
+			DefaultPasswordPolicy,
+			false,
+		},
+		{
+			"Valid: nil policy",
+			"username",
+			"password",
+			nil,
 			false,
 		},
 		{
 			"Invalid: username",
 			"aaa",
 			"password",
+			DefaultPasswordPolicy,
 			true,
 		},
 		{
 			"Invalid: password",
 			"username",
 			"aaa",
+			DefaultPasswordPolicy,
+			true,
+		},
+		{
+			"Invalid: password policy",
+			"username",
+			"my_username",
+			DefaultPasswordPolicy,
+			true,
+		},
+		{
+			"Invalid: nil policy",
+			"username",
+			"my_username",
+			nil,
 			true,
 		},
 	}
 
+	hasher, err := argon2id.NewHasher(argon2id.DefaultParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			_, err := New(test.username, test.plainPassword)
+			_, err := New(
+				test.username, test.plainPassword, test.policy, hasher,
+			)
 			if (err != nil) != test.shouldErr {
 				t.Errorf(
 					"New(%q, %q), error=%v, shouldErr=%v",
@@ -47,6 +80,60 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestNewExternal(t *testing.T) {
+	tests := []struct {
+		name                string
+		username            string
+		displayName         string
+		expectedDisplayName string
+		shouldErr           bool
+	}{
+		{
+			"Valid",
+			"username",
+			"Jane Doe",
+			"Jane Doe",
+			false,
+		},
+		{
+			"Valid: invalid display name",
+			"username",
+			"",
+			"username",
+			false,
+		},
+		{
+			"Invalid: username",
+			"aaa",
+			"Jane Doe",
+			"",
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			user, err := NewExternal(test.username, test.displayName)
+			if (err != nil) != test.shouldErr {
+				t.Errorf(
+					"NewExternal(%q, %q), error=%v, shouldErr=%v",
+					test.username, test.displayName, err, test.shouldErr,
+				)
+			}
+			if err != nil {
+				return
+			}
+			if user.DisplayName != test.expectedDisplayName || user.HasPassword() {
+				t.Errorf(
+					"NewExternal(%q, %q), DisplayName=%q, HasPassword=%v",
+					test.username, test.displayName,
+					user.DisplayName, user.HasPassword(),
+				)
+			}
+		})
+	}
+}
+
 func TestLoad(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -54,6 +141,10 @@ func TestLoad(t *testing.T) {
 		username       string
 		displayName    string
 		hashedPassword string
+		email          string
+		emailVerified  bool
+		role           Role
+		status         Status
 		creationDate   date.Date
 		shouldErr      bool
 	}{
@@ -63,6 +154,49 @@ func TestLoad(t *testing.T) {
 			"username",
 			"display name",
 			"hashed password",
+			"",
+			false,
+			RoleUser,
+			StatusActive,
+			date.Now(),
+			false,
+		},
+		{
+			"Valid: email",
+			uuid.UUID{},
+			"username",
+			"display name",
+			"hashed password",
+			"user@example.com",
+			true,
+			RoleUser,
+			StatusActive,
+			date.Now(),
+			false,
+		},
+		{
+			"Valid: pending admin",
+			uuid.UUID{},
+			"username",
+			"display name",
+			"hashed password",
+			"",
+			false,
+			RoleAdmin,
+			StatusPending,
+			date.Now(),
+			false,
+		},
+		{
+			"Valid: disabled user",
+			uuid.UUID{},
+			"username",
+			"display name",
+			"hashed password",
+			"",
+			false,
+			RoleUser,
+			StatusDisabled,
 			date.Now(),
 			false,
 		},
@@ -72,6 +206,10 @@ func TestLoad(t *testing.T) {
 			"aaa",
 			"display name",
 			"hashed password",
+			"",
+			false,
+			RoleUser,
+			StatusActive,
 			date.Now(),
 			true,
 		},
@@ -81,6 +219,49 @@ func TestLoad(t *testing.T) {
 			"username",
 			"  display name  ",
 			"hashed password",
+			"",
+			false,
+			RoleUser,
+			StatusActive,
+			date.Now(),
+			true,
+		},
+		{
+			"Invalid: email",
+			uuid.UUID{},
+			"username",
+			"display name",
+			"hashed password",
+			"user at example.com",
+			false,
+			RoleUser,
+			StatusActive,
+			date.Now(),
+			true,
+		},
+		{
+			"Invalid: role",
+			uuid.UUID{},
+			"username",
+			"display name",
+			"hashed password",
+			"",
+			false,
+			Role(255),
+			StatusActive,
+			date.Now(),
+			true,
+		},
+		{
+			"Invalid: status",
+			uuid.UUID{},
+			"username",
+			"display name",
+			"hashed password",
+			"",
+			false,
+			RoleUser,
+			Status(255),
 			date.Now(),
 			true,
 		},
@@ -90,13 +271,15 @@ func TestLoad(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			_, err := Load(
 				test.id, test.username, test.displayName,
-				test.hashedPassword, test.creationDate,
+				test.hashedPassword, test.email, test.emailVerified,
+				test.role, test.status, test.creationDate,
 			)
 			if (err != nil) != test.shouldErr {
 				t.Errorf(
-					"Load(%q, %q, %q, %q, %q), error=%v, shouldErr=%v",
+					"Load(%q, %q, %q, %q, %q, %v, %v, %v, %q), error=%v, shouldErr=%v",
 					test.id, test.username, test.displayName,
-					test.hashedPassword, test.creationDate, err, test.shouldErr,
+					test.hashedPassword, test.email, test.emailVerified,
+					test.role, test.status, test.creationDate, err, test.shouldErr,
 				)
 			}
 		})
@@ -1,6 +1,7 @@
 package user
 
 import (
+	"net/mail"
 	"strings"
 	"unicode"
 	"unicode/utf8"
@@ -17,6 +18,8 @@ const (
 
 	plainPasswordMinChars = 8
 	plainPasswordMaxChars = 128
+
+	emailMaxChars = 254
 )
 
 var usernameCharsetSet = func() map[rune]bool {
@@ -59,6 +62,36 @@ func ValidateUsername(username string) error {
 	return nil
 }
 
+// UsernameFromHint returns a username derived from the hint,
+// for example a username or an email local part reported by an external provider.
+// Characters outside of the username charset are dropped,
+// and the result is cut down to the maximum length.
+// It returns an empty string if no valid username can be derived.
+func UsernameFromHint(hint string) string {
+	if i := strings.IndexByte(hint, '@'); i >= 0 {
+		hint = hint[:i]
+	}
+
+	b := make([]byte, 0, usernameMaxChars)
+	for _, r := range hint {
+		if len(b) == usernameMaxChars {
+			break
+		}
+		if r == '.' || r == '-' {
+			r = '_'
+		}
+		if usernameCharsetSet[r] {
+			b = append(b, byte(r))
+		}
+	}
+
+	username := string(b)
+	if ValidateUsername(username) != nil {
+		return ""
+	}
+	return username
+}
+
 // ValidateDisplayName fails if the provided display name
 // does not meet the application requirements.
 // The returned error is safe for client-side message.
@@ -123,3 +156,20 @@ func ValidatePlainPassword(plainPassword string) error {
 
 	return nil
 }
+
+// ValidateEmail fails if the provided email
+// does not meet the application requirements.
+// Only a bare address is accepted, e.g. "user@example.com".
+// The returned error is safe for client-side message.
+func ValidateEmail(email string) error {
+	if len(email) > emailMaxChars {
+		return ErrEmailTooLong
+	}
+
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Name != "" || address.Address != email {
+		return ErrEmailInvalid
+	}
+
+	return nil
+}
@@ -187,3 +187,110 @@ func TestValidatePlainPassword(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateEmail(t *testing.T) {
+	tests := []struct {
+		name      string
+		email     string
+		shouldErr bool
+	}{
+		{
+			"Valid",
+			"user@example.com",
+			false,
+		},
+		{
+			"Valid: subaddress",
+			"user+kera@mail.example.com",
+			false,
+		},
+		{
+			"Invalid: empty",
+			"",
+			true,
+		},
+		{
+			"Invalid: missing domain",
+			"user@",
+			true,
+		},
+		{
+			"Invalid: display name",
+			"User <user@example.com>",
+			true,
+		},
+		{
+			"Invalid: spaces around",
+			" user@example.com ",
+			true,
+		},
+		{
+			"Invalid: too long",
+			strings.Repeat("a", emailMaxChars) + "@example.com",
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateEmail(test.email)
+			if (err != nil) != test.shouldErr {
+				t.Errorf(
+					"ValidateEmail(%q), error=%v, shouldErr=%v",
+					test.email, err, test.shouldErr,
+				)
+			}
+		})
+	}
+}
+
+func TestUsernameFromHint(t *testing.T) {
+	tests := []struct {
+		name     string
+		hint     string
+		expected string
+	}{
+		{
+			"Valid",
+			"jane_doe",
+			"jane_doe",
+		},
+		{
+			"Valid: email",
+			"jane.doe@example.com",
+			"jane_doe",
+		},
+		{
+			"Valid: dropped characters",
+			"jané dœ!",
+			"jand",
+		},
+		{
+			"Valid: too long",
+			"aaaaAAAAaaaaAAAAa",
+			"aaaaAAAAaaaaAAAA",
+		},
+		{
+			"Invalid: too short",
+			"ab@example.com",
+			"",
+		},
+		{
+			"Invalid: empty",
+			"",
+			"",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := UsernameFromHint(test.hint)
+			if got != test.expected {
+				t.Errorf(
+					"UsernameFromHint(%q), got=%q, expected=%q",
+					test.hint, got, test.expected,
+				)
+			}
+		})
+	}
+}
@@ -34,3 +34,9 @@ func Parse(value string) (UUID, error) {
 func (id UUID) String() string {
 	return uuid.UUID(id).String()
 }
+
+// NewV5 returns the version 5 UUID of the name within the namespace,
+// the same namespace and name always result in the same UUID.
+func NewV5(namespace UUID, name []byte) UUID {
+	return UUID(uuid.NewSHA1(uuid.UUID(namespace), name))
+}
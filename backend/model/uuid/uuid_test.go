@@ -67,3 +67,21 @@ func TestString(t *testing.T) {
 		})
 	}
 }
+
+func TestNewV5(t *testing.T) {
+	namespace, err := Parse("0192a14e-0605-726d-89ed-6b39dae2ea91")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := NewV5(namespace, []byte("name"))
+	if id != NewV5(namespace, []byte("name")) {
+		t.Errorf("NewV5(%q, %q), result is not deterministic", namespace, "name")
+	}
+	if id == NewV5(namespace, []byte("other name")) {
+		t.Errorf("NewV5(%q, %q), result does not depend on the name", namespace, "other name")
+	}
+	if id == NewV5(UUID{}, []byte("name")) {
+		t.Errorf("NewV5(%q, %q), result does not depend on the namespace", UUID{}, "name")
+	}
+}
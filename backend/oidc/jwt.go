@@ -0,0 +1,184 @@
+package oidc
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+// clockSkew represents the tolerated clock difference to the provider.
+const clockSkew = time.Minute
+
+var (
+	ErrMalformedToken   = errors.New("oidc: ID token is malformed")
+	ErrUnsupportedAlg   = errors.New("oidc: ID token signing algorithm is unsupported")
+	ErrInvalidSignature = errors.New("oidc: ID token signature is invalid")
+	ErrInvalidIssuer    = errors.New("oidc: ID token issuer is invalid")
+	ErrInvalidAudience  = errors.New("oidc: ID token audience is invalid")
+	ErrTokenExpired     = errors.New("oidc: ID token is expired")
+	ErrInvalidIssuedAt  = errors.New("oidc: ID token issue time is invalid")
+	ErrInvalidNonce     = errors.New("oidc: ID token nonce is invalid")
+	ErrMissingSubject   = errors.New("oidc: ID token subject is missing")
+)
+
+// Claims represents the verified claims of an ID token.
+type Claims struct {
+	Issuer            string   `json:"iss"`
+	Subject           string   `json:"sub"`
+	Audience          audience `json:"aud"`
+	AuthorizedParty   string   `json:"azp"`
+	Expiry            int64    `json:"exp"`
+	IssuedAt          int64    `json:"iat"`
+	Nonce             string   `json:"nonce"`
+	Email             string   `json:"email"`
+	EmailVerified     bool     `json:"email_verified"`
+	Name              string   `json:"name"`
+	PreferredUsername string   `json:"preferred_username"`
+}
+
+// audience represents the "aud" claim, which is either a string or an array.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(data, []byte(`"`)) {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*a = audience{s}
+		return nil
+	}
+	var s []string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*a = s
+	return nil
+}
+
+// VerifyIDToken verifies the raw ID token and returns its claims.
+// The signature is checked against the provider's key set,
+// then the issuer, audience, expiry, issue time and nonce are validated.
+func (p *Provider) VerifyIDToken(
+	ctx context.Context, rawIDToken, nonce string, now time.Time,
+) (*Claims, error) {
+	parts := strings.Split(rawIDToken, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformedToken
+	}
+
+	rawHeader, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := json.Unmarshal(rawHeader, &header); err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	key, err := p.keys.key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	if err := verifySignature(header.Alg, key, signed, signature); err != nil {
+		return nil, err
+	}
+
+	rawClaims, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+	var claims Claims
+	if err := json.Unmarshal(rawClaims, &claims); err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	if err := p.validateClaims(&claims, nonce, now); err != nil {
+		return nil, err
+	}
+
+	return &claims, nil
+}
+
+func (p *Provider) validateClaims(claims *Claims, nonce string, now time.Time) error {
+	if claims.Issuer != p.metadata.Issuer {
+		return ErrInvalidIssuer
+	}
+
+	if !slices.Contains(claims.Audience, p.config.ClientID) {
+		return ErrInvalidAudience
+	}
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != p.config.ClientID {
+		return ErrInvalidAudience
+	}
+
+	if !now.Before(time.Unix(claims.Expiry, 0).Add(clockSkew)) {
+		return ErrTokenExpired
+	}
+	if time.Unix(claims.IssuedAt, 0).After(now.Add(clockSkew)) {
+		return ErrInvalidIssuedAt
+	}
+
+	if nonce == "" || claims.Nonce != nonce {
+		return ErrInvalidNonce
+	}
+
+	if claims.Subject == "" {
+		return ErrMissingSubject
+	}
+
+	return nil
+}
+
+func verifySignature(alg string, key crypto.PublicKey, signed, signature []byte) error {
+	digest := sha256.Sum256(signed)
+
+	switch alg {
+	case "RS256":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return ErrInvalidSignature
+		}
+		err := rsa.VerifyPKCS1v15(rsaKey, crypto.SHA256, digest[:], signature)
+		if err != nil {
+			return ErrInvalidSignature
+		}
+		return nil
+
+	case "ES256":
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok || len(signature) != 64 {
+			return ErrInvalidSignature
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(ecKey, digest[:], r, s) {
+			return ErrInvalidSignature
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedAlg, alg)
+	}
+}
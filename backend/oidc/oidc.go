@@ -0,0 +1,212 @@
+// Package oidc provides an OpenID Connect relying party
+// using the authorization code flow with PKCE.
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	discoveryPath = "/.well-known/openid-configuration"
+
+	// maxResponseBytes limits the size of responses read from the provider.
+	maxResponseBytes = 1 << 20
+)
+
+var (
+	ErrInvalidConfig   = errors.New("oidc: config is invalid")
+	ErrInvalidMetadata = errors.New("oidc: provider metadata is invalid")
+	ErrExchange        = errors.New("oidc: failed to exchange authorization code")
+)
+
+// Config represents a configuration of a client registered at the provider.
+type Config struct {
+	// IssuerURL is the issuer identifier of the provider,
+	// provider metadata is discovered from it.
+	IssuerURL string
+
+	ClientID string
+
+	// ClientSecret is optional, public clients rely on PKCE alone.
+	ClientSecret string
+
+	// RedirectURL is the callback URL registered at the provider.
+	RedirectURL string
+
+	// Scopes are requested in addition to "openid".
+	Scopes []string
+
+	// HTTPClient is used for all requests to the provider.
+	// [http.DefaultClient] is used if nil.
+	HTTPClient *http.Client
+}
+
+// Metadata represents the subset of the provider metadata used by the client.
+type Metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Provider represents a discovered OpenID provider.
+type Provider struct {
+	config   Config
+	client   *http.Client
+	metadata Metadata
+	keys     *keySet
+}
+
+// NewProvider returns a new *Provider.
+// It fetches the provider metadata from the issuer's discovery document.
+// It fails if the config is invalid, the provider is unreachable,
+// or the discovered issuer does not match the configured one.
+func NewProvider(ctx context.Context, config Config) (*Provider, error) {
+	if config.IssuerURL == "" || config.ClientID == "" || config.RedirectURL == "" {
+		return nil, ErrInvalidConfig
+	}
+
+	client := config.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	discoveryURL := strings.TrimSuffix(config.IssuerURL, "/") + discoveryPath
+
+	var metadata Metadata
+	if err := getJSON(ctx, client, discoveryURL, &metadata); err != nil {
+		return nil, fmt.Errorf("oidc: failed to discover provider: %w", err)
+	}
+
+	if metadata.Issuer != config.IssuerURL ||
+		metadata.AuthorizationEndpoint == "" ||
+		metadata.TokenEndpoint == "" ||
+		metadata.JWKSURI == "" {
+		return nil, ErrInvalidMetadata
+	}
+
+	return &Provider{
+		config:   config,
+		client:   client,
+		metadata: metadata,
+		keys:     newKeySet(client, metadata.JWKSURI),
+	}, nil
+}
+
+// Issuer returns the issuer identifier of the provider.
+func (p *Provider) Issuer() string {
+	return p.metadata.Issuer
+}
+
+// AuthCodeURL returns the URL of the provider's authorization endpoint
+// the user agent should be redirected to.
+// The codeChallenge is the S256 challenge of the PKCE code verifier.
+func (p *Provider) AuthCodeURL(state, nonce, codeChallenge string) string {
+	scopes := append([]string{"openid"}, p.config.Scopes...)
+
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.config.ClientID},
+		"redirect_uri":          {p.config.RedirectURL},
+		"scope":                 {strings.Join(scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(p.metadata.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return p.metadata.AuthorizationEndpoint + separator + query.Encode()
+}
+
+// Exchange exchanges the authorization code for tokens at the token endpoint,
+// and returns the verified claims of the ID token.
+// The nonce must be the one sent in the authorization request.
+func (p *Provider) Exchange(
+	ctx context.Context, code, codeVerifier, nonce string,
+) (*Claims, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"code_verifier": {codeVerifier},
+	}
+	if p.config.ClientSecret == "" {
+		form.Set("client_id", p.config.ClientID)
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, p.metadata.TokenEndpoint,
+		strings.NewReader(form.Encode()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrExchange, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		req.SetBasicAuth(
+			url.QueryEscape(p.config.ClientID),
+			url.QueryEscape(p.config.ClientSecret),
+		)
+	}
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrExchange, err)
+	}
+	defer res.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	err = json.NewDecoder(io.LimitReader(res.Body, maxResponseBytes)).Decode(&body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrExchange, err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(
+			"%w: %s: %s %s",
+			ErrExchange, res.Status, body.Error, body.ErrorDescription,
+		)
+	}
+	if body.IDToken == "" {
+		return nil, fmt.Errorf("%w: missing id_token", ErrExchange)
+	}
+
+	return p.VerifyIDToken(ctx, body.IDToken, nonce, time.Now())
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status: %s", res.Status)
+	}
+
+	return json.NewDecoder(io.LimitReader(res.Body, maxResponseBytes)).Decode(v)
+}
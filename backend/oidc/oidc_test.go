@@ -0,0 +1,238 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zvxte/kera/oidc/oidctest"
+)
+
+const (
+	testClientID     = "kera"
+	testClientSecret = "secret"
+	testRedirectURL  = "http://localhost/auth/oidc/callback"
+)
+
+func newTestProvider(t *testing.T) (*oidctest.Provider, *Provider) {
+	t.Helper()
+
+	fake := oidctest.NewProvider(testClientID, testClientSecret, oidctest.User{
+		Subject:       "248289761001",
+		Email:         "jane@example.com",
+		EmailVerified: true,
+	})
+	t.Cleanup(fake.Close)
+
+	provider, err := NewProvider(context.Background(), Config{
+		IssuerURL:    fake.Issuer(),
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		RedirectURL:  testRedirectURL,
+		Scopes:       []string{"email"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return fake, provider
+}
+
+// authorize follows the authorization request to the fake provider
+// and returns the authorization code it redirects back with.
+func authorize(t *testing.T, provider *Provider, state, nonce, verifier string) string {
+	t.Helper()
+
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	res, err := client.Get(provider.AuthCodeURL(state, nonce, CodeChallenge(verifier)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	location, err := url.Parse(res.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := location.Query().Get("state"); got != state {
+		t.Fatalf("AuthCodeURL(), state=%q, expected=%q", got, state)
+	}
+
+	return location.Query().Get("code")
+}
+
+func TestFlow(t *testing.T) {
+	_, provider := newTestProvider(t)
+
+	state, _ := NewRandom()
+	nonce, _ := NewRandom()
+	verifier, _ := NewRandom()
+
+	code := authorize(t, provider, state, nonce, verifier)
+	claims, err := provider.Exchange(context.Background(), code, verifier, nonce)
+	if err != nil {
+		t.Fatalf("Exchange(), error=%v", err)
+	}
+	if claims.Subject != "248289761001" || claims.Email != "jane@example.com" ||
+		!claims.EmailVerified {
+		t.Errorf("Exchange(), claims=%+v", claims)
+	}
+
+	tests := []struct {
+		name      string
+		code      string
+		verifier  string
+		nonce     string
+		shouldErr bool
+	}{
+		{
+			"Invalid: reused code",
+			code,
+			verifier,
+			nonce,
+			true,
+		},
+		{
+			"Invalid: wrong code verifier",
+			authorize(t, provider, state, nonce, verifier),
+			"wrong",
+			nonce,
+			true,
+		},
+		{
+			"Invalid: wrong nonce",
+			authorize(t, provider, state, nonce, verifier),
+			verifier,
+			"wrong",
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := provider.Exchange(
+				context.Background(), test.code, test.verifier, test.nonce,
+			)
+			if (err != nil) != test.shouldErr {
+				t.Errorf(
+					"Exchange(%q, %q, %q), error=%v, shouldErr=%v",
+					test.code, test.verifier, test.nonce, err, test.shouldErr,
+				)
+			}
+		})
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	fake, _ := newTestProvider(t)
+
+	tests := []struct {
+		name      string
+		config    Config
+		shouldErr bool
+	}{
+		{
+			"Valid",
+			Config{IssuerURL: fake.Issuer(), ClientID: testClientID, RedirectURL: testRedirectURL},
+			false,
+		},
+		{
+			"Invalid: missing client ID",
+			Config{IssuerURL: fake.Issuer(), RedirectURL: testRedirectURL},
+			true,
+		},
+		{
+			"Invalid: issuer mismatch",
+			Config{IssuerURL: fake.Issuer() + "/", ClientID: testClientID, RedirectURL: testRedirectURL},
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewProvider(context.Background(), test.config)
+			if (err != nil) != test.shouldErr {
+				t.Errorf(
+					"NewProvider(%+v), error=%v, shouldErr=%v",
+					test.config, err, test.shouldErr,
+				)
+			}
+		})
+	}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	fake, provider := newTestProvider(t)
+
+	now := time.Now()
+	valid := func() map[string]any {
+		return map[string]any{
+			"iss":   fake.Issuer(),
+			"sub":   "248289761001",
+			"aud":   testClientID,
+			"exp":   now.Add(time.Hour).Unix(),
+			"iat":   now.Unix(),
+			"nonce": "n-0S6_WzA2Mj",
+		}
+	}
+	with := func(key string, value any) map[string]any {
+		claims := valid()
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+		return claims
+	}
+
+	// A token with a signature made by a different key
+	other, _ := newTestProvider(t)
+	signed := fake.SignIDToken(valid())
+	otherSigned := other.SignIDToken(valid())
+	forged := signed[:strings.LastIndex(signed, ".")] +
+		otherSigned[strings.LastIndex(otherSigned, "."):]
+
+	tests := []struct {
+		name     string
+		rawToken string
+		expected error
+	}{
+		{"Valid", fake.SignIDToken(valid()), nil},
+		{
+			"Invalid: audience array without azp",
+			fake.SignIDToken(with("aud", []string{testClientID, "other"})),
+			ErrInvalidAudience,
+		},
+		{"Invalid: malformed", "not.a-token", ErrMalformedToken},
+		{"Invalid: signature", forged, ErrInvalidSignature},
+		{"Invalid: wrong issuer", fake.SignIDToken(with("iss", "https://evil.example")), ErrInvalidIssuer},
+		{"Invalid: wrong audience", fake.SignIDToken(with("aud", "other")), ErrInvalidAudience},
+		{"Invalid: expired", fake.SignIDToken(with("exp", now.Add(-time.Hour).Unix())), ErrTokenExpired},
+		{"Invalid: issued in future", fake.SignIDToken(with("iat", now.Add(time.Hour).Unix())), ErrInvalidIssuedAt},
+		{"Invalid: wrong nonce", fake.SignIDToken(with("nonce", "other")), ErrInvalidNonce},
+		{"Invalid: missing nonce", fake.SignIDToken(with("nonce", nil)), ErrInvalidNonce},
+		{"Invalid: missing subject", fake.SignIDToken(with("sub", nil)), ErrMissingSubject},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := provider.VerifyIDToken(
+				context.Background(), test.rawToken, "n-0S6_WzA2Mj", now,
+			)
+			if !errors.Is(err, test.expected) {
+				t.Errorf(
+					"VerifyIDToken(), error=%v, expected=%v",
+					err, test.expected,
+				)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+)
+
+// randomBytes represents the number of random bytes
+// of states, nonces and code verifiers.
+const randomBytes = 32
+
+// NewRandom returns a new random URL-safe string suitable
+// for a state, a nonce or a PKCE code verifier.
+// It fails if the system's source of randomness is unavailable.
+func NewRandom() (string, error) {
+	b := make([]byte, randomBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("oidc: failed to generate random value: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CodeChallenge returns the S256 PKCE code challenge of the code verifier.
+func CodeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,618 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/zvxte/kera/mail"
+	"github.com/zvxte/kera/model/audit"
+	"github.com/zvxte/kera/model/invite"
+	"github.com/zvxte/kera/model/token"
+	"github.com/zvxte/kera/model/user"
+	"github.com/zvxte/kera/model/uuid"
+	"github.com/zvxte/kera/store/auditstore"
+	"github.com/zvxte/kera/store/invitestore"
+	"github.com/zvxte/kera/store/sessionstore"
+	"github.com/zvxte/kera/store/tokenstore"
+	"github.com/zvxte/kera/store/userstore"
+)
+
+const (
+	defaultInviteMaxUses  = 1
+	defaultInviteLifetime = 7 * 24 * time.Hour
+
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+)
+
+// AdminMiddleware allows the request only if the user is an active admin.
+// It must be layered inside [SessionMiddleware], which authenticates the user.
+// The role is checked on every request, so a demoted admin loses
+// access immediately.
+func AdminMiddleware(next http.Handler, userStore userstore.Store) http.Handler {
+	f := func(w http.ResponseWriter, r *http.Request) response {
+		userID, ok := r.Context().Value(userIDContextKey).(uuid.UUID)
+		if !ok {
+			return internalServerErrorResponse
+		}
+
+		user, err := userStore.Get(r.Context(), userstore.IDColumn, userID)
+		if err != nil {
+			logError(r, err)
+			return internalServerErrorResponse
+		}
+		if user == nil {
+			unsetSessionIDCookie(w)
+			return unauthorizedResponse
+		}
+
+		if !user.IsAdmin() || !user.IsActive() {
+			return forbiddenResponse
+		}
+
+		next.ServeHTTP(w, r)
+		return nil
+	}
+
+	return makeHandlerFunc(f)
+}
+
+// NewAdminMux returns a mux of the admin API,
+// it must be guarded by [AdminMiddleware].
+// Every change made through it is recorded in the audit trail.
+func NewAdminMux(
+	userStore userstore.Store,
+	sessionStore sessionstore.Store,
+	tokenStore tokenstore.Store,
+	inviteStore invitestore.Store,
+	auditStore auditstore.Store,
+	mailer mail.Mailer,
+) *http.ServeMux {
+	h := &adminHandler{
+		userStore:    userStore,
+		sessionStore: sessionStore,
+		tokenStore:   tokenStore,
+		inviteStore:  inviteStore,
+		auditStore:   auditStore,
+		mailer:       mailer,
+	}
+
+	m := http.NewServeMux()
+	m.HandleFunc("GET /invites", makeHandlerFunc(h.getInvites))
+	m.HandleFunc("POST /invites", makeHandlerFunc(h.postInvite))
+	m.HandleFunc("DELETE /invites/{id}", makeHandlerFunc(h.deleteInvite))
+	m.HandleFunc("GET /users", makeHandlerFunc(h.getUsers))
+	m.HandleFunc("GET /users/pending", makeHandlerFunc(h.getPendingUsers))
+	m.HandleFunc("DELETE /users/{id}", makeHandlerFunc(h.deleteUser))
+	m.HandleFunc("POST /users/{id}/approve", makeHandlerFunc(h.approveUser))
+	m.HandleFunc("POST /users/{id}/reject", makeHandlerFunc(h.rejectUser))
+	m.HandleFunc("POST /users/{id}/disable", makeHandlerFunc(h.disableUser))
+	m.HandleFunc("POST /users/{id}/enable", makeHandlerFunc(h.enableUser))
+	m.HandleFunc("POST /users/{id}/password-reset", makeHandlerFunc(h.resetUserPassword))
+	m.HandleFunc("GET /audit", makeHandlerFunc(h.getAudit))
+	return m
+}
+
+type adminHandler struct {
+	userStore    userstore.Store
+	sessionStore sessionstore.Store
+	tokenStore   tokenstore.Store
+	inviteStore  invitestore.Store
+	auditStore   auditstore.Store
+	mailer       mail.Mailer
+}
+
+func (h *adminHandler) getInvites(w http.ResponseWriter, r *http.Request) response {
+	ctx := r.Context()
+
+	invites, err := h.inviteStore.GetAll(ctx)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	type out struct {
+		ID             string    `json:"id"`
+		CreatorID      *string   `json:"creator_id"`
+		MaxUses        uint32    `json:"max_uses"`
+		Uses           uint32    `json:"uses"`
+		CreationTime   time.Time `json:"creation_time"`
+		ExpirationTime time.Time `json:"expiration_time"`
+	}
+
+	outs := make([]out, len(invites))
+	for i, invite := range invites {
+		outs[i] = out{
+			ID:             invite.ID.String(),
+			MaxUses:        invite.MaxUses,
+			Uses:           invite.Uses,
+			CreationTime:   invite.CreationTime,
+			ExpirationTime: invite.ExpirationTime,
+		}
+		if invite.CreatorID != (uuid.UUID{}) {
+			creatorID := invite.CreatorID.String()
+			outs[i].CreatorID = &creatorID
+		}
+	}
+
+	return newJsonResponse(http.StatusOK, outs)
+}
+
+// postInvite creates an invite and returns its code.
+// The code is returned only once, just its hash is stored.
+func (h *adminHandler) postInvite(w http.ResponseWriter, r *http.Request) response {
+	if r.Header.Get("Content-Type") != "application/json" {
+		return unsupportedMediaTypeResponse
+	}
+
+	userID, ok := r.Context().Value(userIDContextKey).(uuid.UUID)
+	if !ok {
+		return internalServerErrorResponse
+	}
+
+	var in struct {
+		MaxUses uint32 `json:"max_uses"`
+
+		// ExpiresIn is the lifetime in seconds
+		ExpiresIn uint32 `json:"expires_in"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		return badRequestResponse
+	}
+
+	maxUses := in.MaxUses
+	if maxUses == 0 {
+		maxUses = defaultInviteMaxUses
+	}
+
+	lifetime := time.Duration(in.ExpiresIn) * time.Second
+	if lifetime == 0 {
+		lifetime = defaultInviteLifetime
+	}
+
+	code, invite, err := invite.New(userID, maxUses, lifetime)
+	if err != nil {
+		return newJsonResponse(
+			http.StatusBadRequest,
+			newHandlerError(http.StatusBadRequest, err.Error()),
+		)
+	}
+
+	ctx := r.Context()
+
+	if err := h.inviteStore.Create(ctx, invite); err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	h.record(
+		ctx, r, audit.InviteCreated, invite.ID,
+		fmt.Sprintf("max_uses=%d expiration_time=%s",
+			invite.MaxUses, invite.ExpirationTime.Format(time.RFC3339)),
+	)
+
+	return newJsonResponse(
+		http.StatusCreated,
+		struct {
+			ID             string    `json:"id"`
+			Code           string    `json:"code"`
+			MaxUses        uint32    `json:"max_uses"`
+			ExpirationTime time.Time `json:"expiration_time"`
+		}{
+			ID:             invite.ID.String(),
+			Code:           code,
+			MaxUses:        invite.MaxUses,
+			ExpirationTime: invite.ExpirationTime,
+		},
+	)
+}
+
+func (h *adminHandler) deleteInvite(w http.ResponseWriter, r *http.Request) response {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		return notFoundResponse
+	}
+
+	ctx := r.Context()
+
+	deleted, err := h.inviteStore.Delete(ctx, id)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+	if !deleted {
+		return notFoundResponse
+	}
+
+	h.record(ctx, r, audit.InviteDeleted, id, "")
+
+	return noContentResponse{}
+}
+
+func (h *adminHandler) getPendingUsers(w http.ResponseWriter, r *http.Request) response {
+	ctx := r.Context()
+
+	users, err := h.userStore.GetAllByStatus(ctx, user.StatusPending)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	type out struct {
+		ID           string    `json:"id"`
+		Username     string    `json:"username"`
+		DisplayName  string    `json:"display_name"`
+		Email        string    `json:"email"`
+		CreationDate time.Time `json:"creation_date"`
+	}
+
+	outs := make([]out, len(users))
+	for i, user := range users {
+		outs[i] = out{
+			ID:           user.ID.String(),
+			Username:     user.Username,
+			DisplayName:  user.DisplayName,
+			Email:        user.Email,
+			CreationDate: time.Time(user.CreationDate),
+		}
+	}
+
+	return newJsonResponse(http.StatusOK, outs)
+}
+
+// approveUser activates a pending user, so it can log in.
+func (h *adminHandler) approveUser(w http.ResponseWriter, r *http.Request) response {
+	ctx := r.Context()
+
+	pendingUser, resp := h.pendingUser(ctx, r)
+	if resp != nil {
+		return resp
+	}
+
+	err := h.userStore.Update(
+		ctx, pendingUser.ID, userstore.StatusColumn, user.StatusActive,
+	)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	h.record(ctx, r, audit.UserApproved, pendingUser.ID, pendingUser.Username)
+
+	return noContentResponse{}
+}
+
+// rejectUser deletes a pending user, so the username can be registered again.
+func (h *adminHandler) rejectUser(w http.ResponseWriter, r *http.Request) response {
+	ctx := r.Context()
+
+	pendingUser, resp := h.pendingUser(ctx, r)
+	if resp != nil {
+		return resp
+	}
+
+	if err := h.userStore.Delete(ctx, pendingUser.ID); err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	h.record(ctx, r, audit.UserRejected, pendingUser.ID, pendingUser.Username)
+
+	return noContentResponse{}
+}
+
+func (h *adminHandler) getUsers(w http.ResponseWriter, r *http.Request) response {
+	limit, offset, ok := pagination(r)
+	if !ok {
+		return badRequestResponse
+	}
+
+	ctx := r.Context()
+
+	summaries, err := h.userStore.List(ctx, limit, offset)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	type out struct {
+		ID            string     `json:"id"`
+		Username      string     `json:"username"`
+		DisplayName   string     `json:"display_name"`
+		Email         string     `json:"email"`
+		EmailVerified bool       `json:"email_verified"`
+		Role          string     `json:"role"`
+		Status        string     `json:"status"`
+		CreationDate  time.Time  `json:"creation_date"`
+		HabitCount    uint       `json:"habit_count"`
+		LastLoginTime *time.Time `json:"last_login_time"`
+	}
+
+	outs := make([]out, len(summaries))
+	for i, summary := range summaries {
+		outs[i] = out{
+			ID:            summary.User.ID.String(),
+			Username:      summary.User.Username,
+			DisplayName:   summary.User.DisplayName,
+			Email:         summary.User.Email,
+			EmailVerified: summary.User.EmailVerified,
+			Role:          summary.User.Role.String(),
+			Status:        summary.User.Status.String(),
+			CreationDate:  time.Time(summary.User.CreationDate),
+			HabitCount:    summary.HabitCount,
+		}
+		if !summary.LastLoginTime.IsZero() {
+			outs[i].LastLoginTime = &summary.LastLoginTime
+		}
+	}
+
+	return newJsonResponse(http.StatusOK, outs)
+}
+
+// disableUser prevents a user from logging in and ends all its sessions.
+func (h *adminHandler) disableUser(w http.ResponseWriter, r *http.Request) response {
+	ctx := r.Context()
+
+	target, resp := h.targetUser(ctx, r)
+	if resp != nil {
+		return resp
+	}
+	if target.Status == user.StatusDisabled {
+		return userAlreadyDisabledResponse
+	}
+
+	err := h.userStore.Update(
+		ctx, target.ID, userstore.StatusColumn, user.StatusDisabled,
+	)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	err = h.sessionStore.Delete(ctx, sessionstore.UserIDColumn, target.ID)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	h.record(ctx, r, audit.UserDisabled, target.ID, target.Username)
+
+	return noContentResponse{}
+}
+
+func (h *adminHandler) enableUser(w http.ResponseWriter, r *http.Request) response {
+	ctx := r.Context()
+
+	target, resp := h.targetUser(ctx, r)
+	if resp != nil {
+		return resp
+	}
+	if target.Status != user.StatusDisabled {
+		return userNotDisabledResponse
+	}
+
+	err := h.userStore.Update(
+		ctx, target.ID, userstore.StatusColumn, user.StatusActive,
+	)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	h.record(ctx, r, audit.UserEnabled, target.ID, target.Username)
+
+	return noContentResponse{}
+}
+
+// resetUserPassword removes the password of a user, ends all its sessions
+// and issues a password reset token. The token is sent to the user's
+// verified email, or returned to the admin if there is none,
+// so it can be handed over out of band.
+func (h *adminHandler) resetUserPassword(w http.ResponseWriter, r *http.Request) response {
+	ctx := r.Context()
+
+	target, resp := h.targetUser(ctx, r)
+	if resp != nil {
+		return resp
+	}
+
+	err := h.userStore.Update(ctx, target.ID, userstore.HashedPasswordColumn, "")
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	err = h.sessionStore.Delete(ctx, sessionstore.UserIDColumn, target.ID)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	var email string
+	if target.EmailVerified {
+		email = target.Email
+	}
+
+	tokenID, token, err := token.New(target.ID, token.PasswordReset, email)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	if err := h.tokenStore.Create(ctx, token); err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	h.record(ctx, r, audit.UserPasswordReset, target.ID, target.Username)
+
+	var out struct {
+		EmailSent bool   `json:"email_sent"`
+		Token     string `json:"token,omitempty"`
+	}
+
+	if email != "" {
+		err := h.mailer.Send(ctx, newPasswordResetMessage(email, tokenID))
+		if err != nil {
+			logError(r, err)
+		} else {
+			out.EmailSent = true
+		}
+	}
+	if !out.EmailSent {
+		out.Token = tokenID
+	}
+
+	return newJsonResponse(http.StatusOK, out)
+}
+
+func (h *adminHandler) deleteUser(w http.ResponseWriter, r *http.Request) response {
+	ctx := r.Context()
+
+	target, resp := h.targetUser(ctx, r)
+	if resp != nil {
+		return resp
+	}
+
+	if err := h.userStore.Delete(ctx, target.ID); err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	h.record(ctx, r, audit.UserDeleted, target.ID, target.Username)
+
+	return noContentResponse{}
+}
+
+func (h *adminHandler) getAudit(w http.ResponseWriter, r *http.Request) response {
+	limit, offset, ok := pagination(r)
+	if !ok {
+		return badRequestResponse
+	}
+
+	ctx := r.Context()
+
+	entries, err := h.auditStore.GetAll(ctx, limit, offset)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	type out struct {
+		ID           string    `json:"id"`
+		AdminID      *string   `json:"admin_id"`
+		Action       string    `json:"action"`
+		TargetID     string    `json:"target_id"`
+		Details      string    `json:"details"`
+		CreationTime time.Time `json:"creation_time"`
+	}
+
+	outs := make([]out, len(entries))
+	for i, entry := range entries {
+		outs[i] = out{
+			ID:           entry.ID.String(),
+			Action:       entry.Action.String(),
+			TargetID:     entry.TargetID.String(),
+			Details:      entry.Details,
+			CreationTime: entry.CreationTime,
+		}
+		if entry.AdminID != (uuid.UUID{}) {
+			adminID := entry.AdminID.String()
+			outs[i].AdminID = &adminID
+		}
+	}
+
+	return newJsonResponse(http.StatusOK, outs)
+}
+
+// record adds the action of the request's admin to the audit trail.
+// The action is already done, so a failure is only logged.
+func (h *adminHandler) record(
+	ctx context.Context, r *http.Request,
+	action audit.Action, targetID uuid.UUID, details string,
+) {
+	adminID, _ := r.Context().Value(userIDContextKey).(uuid.UUID)
+
+	entry, err := audit.New(adminID, action, targetID, details)
+	if err == nil {
+		err = h.auditStore.Create(ctx, entry)
+	}
+	if err != nil {
+		requestLogger(r).Error(
+			"failed to record audit entry",
+			"action", action, "target_id", targetID.String(), "error", err,
+		)
+	}
+}
+
+// targetUser returns the user of the request's path ID.
+// Admins can't target themselves, so they can't lock themselves out.
+func (h *adminHandler) targetUser(
+	ctx context.Context, r *http.Request,
+) (*user.User, response) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		return nil, notFoundResponse
+	}
+
+	if adminID, _ := r.Context().Value(userIDContextKey).(uuid.UUID); adminID == id {
+		return nil, selfAdminActionResponse
+	}
+
+	target, err := h.userStore.Get(ctx, userstore.IDColumn, id)
+	if err != nil {
+		logError(r, err)
+		return nil, internalServerErrorResponse
+	}
+	if target == nil {
+		return nil, notFoundResponse
+	}
+
+	return target, nil
+}
+
+// pendingUser returns the pending user of the request's path ID.
+func (h *adminHandler) pendingUser(
+	ctx context.Context, r *http.Request,
+) (*user.User, response) {
+	pendingUser, resp := h.targetUser(ctx, r)
+	if resp != nil {
+		return nil, resp
+	}
+	if pendingUser.Status != user.StatusPending {
+		return nil, userNotPendingResponse
+	}
+
+	return pendingUser, nil
+}
+
+// pagination returns the limit and offset query parameters.
+// The limit defaults to 50 and is capped at 200, the offset defaults to 0.
+// It returns false if any of them is not a number.
+func pagination(r *http.Request) (uint, uint, bool) {
+	limit, offset := uint64(defaultPageLimit), uint64(0)
+
+	query := r.URL.Query()
+	if value := query.Get("limit"); value != "" {
+		var err error
+		limit, err = strconv.ParseUint(value, 10, 32)
+		if err != nil || limit == 0 {
+			return 0, 0, false
+		}
+		limit = min(limit, maxPageLimit)
+	}
+	if value := query.Get("offset"); value != "" {
+		var err error
+		offset, err = strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return 0, 0, false
+		}
+	}
+
+	return uint(limit), uint(offset), true
+}
@@ -3,15 +3,19 @@ package handler
 import (
 	"context"
 	"encoding/json"
-	"log"
 	"net/http"
 	"time"
 
-	"github.com/zvxte/kera/hash/argon2id"
+	"github.com/zvxte/kera/mail"
+	"github.com/zvxte/kera/model/invite"
 	"github.com/zvxte/kera/model/session"
+	"github.com/zvxte/kera/model/token"
 	"github.com/zvxte/kera/model/user"
+	"github.com/zvxte/kera/model/uuid"
 	"github.com/zvxte/kera/store/sessionstore"
+	"github.com/zvxte/kera/store/tokenstore"
 	"github.com/zvxte/kera/store/userstore"
+	"github.com/zvxte/kera/tracing"
 )
 
 type userIn struct {
@@ -19,27 +23,56 @@ type userIn struct {
 	PlainPassword string `json:"password"`
 }
 
+type registerIn struct {
+	userIn
+	InviteCode string `json:"invite_code"`
+}
+
 func NewAuthMux(
 	userStore userstore.Store,
 	sessionStore sessionstore.Store,
-	logger *log.Logger,
+	tokenStore tokenstore.Store,
+	mailer mail.Mailer,
+	sessionLifetime session.Lifetime,
+	registrationMode RegistrationMode,
+	passwordPolicy *user.PasswordPolicy,
+	passwordHasher user.PasswordHasher,
+	background *Background,
+	metrics *Metrics,
 ) *http.ServeMux {
 	h := &authHandler{
-		userStore:    userStore,
-		sessionStore: sessionStore,
-		logger:       logger,
+		userStore:        userStore,
+		sessionStore:     sessionStore,
+		tokenStore:       tokenStore,
+		mailer:           mailer,
+		sessionLifetime:  sessionLifetime,
+		registrationMode: registrationMode,
+		passwordPolicy:   passwordPolicy,
+		passwordHasher:   passwordHasher,
+		background:       background,
 	}
 
 	m := http.NewServeMux()
-	m.HandleFunc("POST /login", makeHandlerFunc(h.Login))
+	m.HandleFunc("POST /login", metrics.countLogins(
+		passwordLoginMethod, makeHandlerFunc(h.Login),
+	))
 	m.HandleFunc("POST /register", makeHandlerFunc(h.Register))
+	m.HandleFunc("POST /password-reset", makeHandlerFunc(h.RequestPasswordReset))
+	m.HandleFunc("POST /password-reset/confirm", makeHandlerFunc(h.ConfirmPasswordReset))
+	m.HandleFunc("POST /email-verification/confirm", makeHandlerFunc(h.ConfirmEmailVerification))
 	return m
 }
 
 type authHandler struct {
-	userStore    userstore.Store
-	sessionStore sessionstore.Store
-	logger       *log.Logger
+	userStore        userstore.Store
+	sessionStore     sessionstore.Store
+	tokenStore       tokenstore.Store
+	mailer           mail.Mailer
+	sessionLifetime  session.Lifetime
+	registrationMode RegistrationMode
+	passwordPolicy   *user.PasswordPolicy
+	passwordHasher   user.PasswordHasher
+	background       *Background
 }
 
 func (h *authHandler) Login(w http.ResponseWriter, r *http.Request) response {
@@ -60,59 +93,156 @@ func (h *authHandler) Login(w http.ResponseWriter, r *http.Request) response {
 		return invalidCredentialsResponse
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	ctx := r.Context()
 
 	user, err := h.userStore.Get(
 		ctx, userstore.UsernameColumn, in.Username,
 	)
 	if err != nil {
-		h.logger.Println(err)
+		logError(r, err)
 		return internalServerErrorResponse
 	}
 
-	if user == nil {
+	if user == nil || !user.HasPassword() {
 		return invalidCredentialsResponse
 	}
 
-	isValid, err := argon2id.VerifyHash(in.PlainPassword, user.HashedPassword)
+	hasher := tracing.Hasher(r.Context(), h.passwordHasher)
+	isValid, err := hasher.Verify(in.PlainPassword, user.HashedPassword)
 	if err != nil {
-		h.logger.Println(err)
+		logError(r, err)
 		return internalServerErrorResponse
 	}
 	if !isValid {
 		return invalidCredentialsResponse
 	}
 
-	sessionID, err := session.NewID()
-	if err != nil {
-		return internalServerErrorResponse
+	if resp := inactiveUserResponse(user); resp != nil {
+		return resp
 	}
 
-	session := session.New(sessionID, user.ID)
+	h.rehashPassword(ctx, r, user, in.PlainPassword)
 
-	err = h.sessionStore.Create(ctx, session)
+	err = startSession(
+		ctx, w, r, h.sessionStore, h.userStore, h.sessionLifetime, user.ID,
+	)
 	if err != nil {
-		h.logger.Println(err)
+		logError(r, err)
 		return internalServerErrorResponse
 	}
 
-	setSessionIDCookie(w, sessionID, time.Time(session.ExpirationDate))
 	return noContentResponse{}
 }
 
+// rehashPassword hashes the verified plain password again
+// if the user's hash is outdated, e.g. created with other argon2id params,
+// an old pepper or imported from another application with bcrypt.
+// Failures are only logged, they must not prevent the login.
+func (h *authHandler) rehashPassword(
+	ctx context.Context, r *http.Request, user *user.User, plainPassword string,
+) {
+	hasher := tracing.Hasher(r.Context(), h.passwordHasher)
+	needsRehash, err := hasher.NeedsRehash(user.HashedPassword)
+	if err != nil {
+		logError(r, err)
+		return
+	}
+	if !needsRehash {
+		return
+	}
+
+	hashedPassword, err := hasher.Hash(plainPassword)
+	if err != nil {
+		logError(r, err)
+		return
+	}
+
+	err = h.userStore.Update(
+		ctx, user.ID, userstore.HashedPasswordColumn, hashedPassword,
+	)
+	if err != nil {
+		logError(r, err)
+	}
+}
+
+// inactiveUserResponse returns a response refusing to start a session
+// for a user that is not active, or nil if the user is active.
+// It must be called only after the user is authenticated,
+// so the status is not revealed to anyone else.
+func inactiveUserResponse(u *user.User) response {
+	switch u.Status {
+	case user.StatusActive:
+		return nil
+	case user.StatusPending:
+		return accountPendingResponse
+	case user.StatusDisabled:
+		return accountDisabledResponse
+	default:
+		return forbiddenResponse
+	}
+}
+
+// startSession creates a new session of the user,
+// records the login time and sets the session ID cookie.
+func startSession(
+	ctx context.Context, w http.ResponseWriter, r *http.Request,
+	sessionStore sessionstore.Store, userStore userstore.Store,
+	lifetime session.Lifetime, userID uuid.UUID,
+) error {
+	sessionID, err := session.NewID()
+	if err != nil {
+		return err
+	}
+
+	session, err := session.New(
+		sessionID, userID, r.UserAgent(), clientIP(r), lifetime,
+	)
+	if err != nil {
+		return err
+	}
+
+	err = sessionStore.Create(ctx, session)
+	if err != nil {
+		return err
+	}
+
+	err = userStore.Update(
+		ctx, userID, userstore.LastLoginTimeColumn, session.CreationTime,
+	)
+	if err != nil {
+		return err
+	}
+
+	setSessionIDCookie(w, sessionID, session.ExpirationTime)
+	return nil
+}
+
+// Register creates a new user according to the registration mode.
+// In the invite mode the invite code is used up only if the user is created.
+// In the approval mode the user is created pending and 202 is returned.
 func (h *authHandler) Register(w http.ResponseWriter, r *http.Request) response {
 	if r.Header.Get("Content-Type") != "application/json" {
 		return unsupportedMediaTypeResponse
 	}
 
-	var in userIn
+	if h.registrationMode == RegistrationClosed {
+		return registrationClosedResponse
+	}
+
+	var in registerIn
 	err := json.NewDecoder(r.Body).Decode(&in)
 	if err != nil {
 		return badRequestResponse
 	}
 
-	user, err := user.New(in.Username, in.PlainPassword)
+	if h.registrationMode == RegistrationInvite && !invite.ValidateCode(in.InviteCode) {
+		return invalidInviteResponse
+	}
+
+	newUser, err := user.New(
+		in.Username, in.PlainPassword, h.passwordPolicy,
+		tracing.Hasher(r.Context(), h.passwordHasher),
+	)
 	if err != nil {
 		return newJsonResponse(
 			http.StatusBadRequest,
@@ -120,17 +250,230 @@ func (h *authHandler) Register(w http.ResponseWriter, r *http.Request) response
 		)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	if h.registrationMode == RegistrationApproval {
+		newUser.Status = user.StatusPending
+	}
+
+	ctx := r.Context()
 
-	err = h.userStore.Create(ctx, user)
+	// In the invite mode the invite is checked before the username,
+	// so a caller without a valid invite can't probe for taken usernames
+	if h.registrationMode == RegistrationInvite {
+		err = h.userStore.CreateInvited(
+			ctx, newUser, invite.HashCode(in.InviteCode), time.Now().UTC(),
+		)
+	} else {
+		err = h.userStore.Create(ctx, newUser)
+	}
+	if err == userstore.ErrInvalidInvite {
+		return invalidInviteResponse
+	}
 	if err == userstore.ErrUsernameAlreadyTaken {
 		return usernameAlreadyTakenResponse
 	}
 	if err != nil {
-		h.logger.Println(err)
+		logError(r, err)
 		return internalServerErrorResponse
 	}
 
+	if newUser.Status == user.StatusPending {
+		return acceptedResponse{}
+	}
+
 	return createdResponse{}
 }
+
+func (h *authHandler) RequestPasswordReset(w http.ResponseWriter, r *http.Request) response {
+	if r.Header.Get("Content-Type") != "application/json" {
+		return unsupportedMediaTypeResponse
+	}
+
+	var in struct {
+		Email string `json:"email"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		return badRequestResponse
+	}
+
+	if err := user.ValidateEmail(in.Email); err != nil {
+		return newJsonResponse(
+			http.StatusBadRequest,
+			newHandlerError(http.StatusBadRequest, err.Error()),
+		)
+	}
+
+	// The response does not depend on whether the email belongs to a user,
+	// so the lookup and the delivery happen in the background.
+	h.background.Go(func() {
+		ctx, cancel := context.WithTimeout(requestContext(r), 30*time.Second)
+		defer cancel()
+
+		user, err := h.userStore.Get(ctx, userstore.EmailColumn, in.Email)
+		if err != nil {
+			logError(r, err)
+			return
+		}
+		if user == nil {
+			return
+		}
+
+		tokenID, token, err := token.New(user.ID, token.PasswordReset, user.Email)
+		if err != nil {
+			logError(r, err)
+			return
+		}
+
+		err = h.tokenStore.Create(ctx, token)
+		if err != nil {
+			logError(r, err)
+			return
+		}
+
+		err = h.mailer.Send(ctx, newPasswordResetMessage(user.Email, tokenID))
+		if err != nil {
+			logError(r, err)
+		}
+	})
+
+	return acceptedResponse{}
+}
+
+func (h *authHandler) ConfirmPasswordReset(w http.ResponseWriter, r *http.Request) response {
+	if r.Header.Get("Content-Type") != "application/json" {
+		return unsupportedMediaTypeResponse
+	}
+
+	var in struct {
+		Token            string `json:"token"`
+		NewPlainPassword string `json:"new_password"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		return badRequestResponse
+	}
+
+	if !token.ValidateID(in.Token) {
+		return invalidTokenResponse
+	}
+
+	// The password is validated first, so an invalid one does not use up the token.
+	// The username is unknown until then, it's checked once the token is consumed.
+	if err := h.passwordPolicy.Validate("", in.NewPlainPassword); err != nil {
+		return newJsonResponse(
+			http.StatusBadRequest,
+			newHandlerError(http.StatusBadRequest, err.Error()),
+		)
+	}
+
+	ctx := r.Context()
+
+	token, err := h.tokenStore.Consume(
+		ctx, token.HashID(in.Token), token.PasswordReset,
+	)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+	if token == nil {
+		return invalidTokenResponse
+	}
+
+	user, err := h.userStore.Get(ctx, userstore.IDColumn, token.UserID)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+	if user == nil {
+		return invalidTokenResponse
+	}
+
+	if err := h.passwordPolicy.Validate(user.Username, in.NewPlainPassword); err != nil {
+		return newJsonResponse(
+			http.StatusBadRequest,
+			newHandlerError(http.StatusBadRequest, err.Error()),
+		)
+	}
+
+	hasher := tracing.Hasher(r.Context(), h.passwordHasher)
+	newHashedPassword, err := hasher.Hash(in.NewPlainPassword)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	err = h.userStore.Update(
+		ctx, token.UserID, userstore.HashedPasswordColumn, newHashedPassword,
+	)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	err = h.sessionStore.Delete(ctx, sessionstore.UserIDColumn, token.UserID)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	err = h.tokenStore.DeleteAll(ctx, token.UserID, token.Purpose)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	unsetSessionIDCookie(w)
+	return noContentResponse{}
+}
+
+func (h *authHandler) ConfirmEmailVerification(w http.ResponseWriter, r *http.Request) response {
+	if r.Header.Get("Content-Type") != "application/json" {
+		return unsupportedMediaTypeResponse
+	}
+
+	var in struct {
+		Token string `json:"token"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		return badRequestResponse
+	}
+
+	if !token.ValidateID(in.Token) {
+		return invalidTokenResponse
+	}
+
+	ctx := r.Context()
+
+	token, err := h.tokenStore.Consume(
+		ctx, token.HashID(in.Token), token.EmailVerification,
+	)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+	if token == nil {
+		return invalidTokenResponse
+	}
+
+	owner, err := h.userStore.Get(ctx, userstore.EmailColumn, token.Email)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+	if owner != nil && owner.ID != token.UserID {
+		return emailAlreadyTakenResponse
+	}
+
+	isVerified, err := h.userStore.VerifyEmail(ctx, token.UserID, token.Email)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+	if !isVerified {
+		// The email was changed after the token was sent
+		return invalidTokenResponse
+	}
+
+	return noContentResponse{}
+}
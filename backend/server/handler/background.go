@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"context"
+	"sync"
+)
+
+// Background runs work that outlives the request it was started by,
+// like sending mail or deleting a session on logout,
+// so the server can wait for it to finish before it shuts down.
+type Background struct {
+	wg sync.WaitGroup
+}
+
+// NewBackground returns a new *Background.
+func NewBackground() *Background {
+	return &Background{}
+}
+
+// Go runs f in a new goroutine.
+func (b *Background) Go(f func()) {
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		f()
+	}()
+}
+
+// Wait waits for all goroutines started by [Background.Go] to return.
+// It fails with the ctx error if the ctx is done first.
+func (b *Background) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/zvxte/kera/ical"
+	"github.com/zvxte/kera/model/date"
+	"github.com/zvxte/kera/model/feed"
+	"github.com/zvxte/kera/model/habit"
+	"github.com/zvxte/kera/model/uuid"
+	"github.com/zvxte/kera/store/feedstore"
+	"github.com/zvxte/kera/store/habitstore"
+	"github.com/zvxte/kera/store/userstore"
+)
+
+const (
+	calendarPrefix = "/calendar/"
+	calendarSuffix = ".ics"
+
+	// completionMonths is the number of months before the current one,
+	// whose done days are in a feed with completions.
+	completionMonths = 12
+)
+
+// NewCalendarMux returns the mux serving calendar feeds.
+// Calendar clients can't send session cookies,
+// so it's authenticated by the feed token in the path instead.
+func NewCalendarMux(
+	feedStore feedstore.Store,
+	userStore userstore.Store,
+	habitStore habitstore.Store,
+) *http.ServeMux {
+	h := &calendarHandler{
+		feedStore:  feedStore,
+		userStore:  userStore,
+		habitStore: habitStore,
+	}
+
+	m := http.NewServeMux()
+	m.HandleFunc("GET /{file}", makeHandlerFunc(h.get))
+	return m
+}
+
+type calendarHandler struct {
+	feedStore  feedstore.Store
+	userStore  userstore.Store
+	habitStore habitstore.Store
+}
+
+// calendarPath returns the path of the calendar feed with the token.
+func calendarPath(token string) string {
+	return calendarPrefix + token + calendarSuffix
+}
+
+// get returns the iCalendar feed of the user's habits.
+// Unknown and revoked tokens, and feeds of inactive users,
+// are not found.
+func (h *calendarHandler) get(w http.ResponseWriter, r *http.Request) response {
+	token, ok := strings.CutSuffix(r.PathValue("file"), calendarSuffix)
+	if !ok || !feed.ValidateToken(token) {
+		return notFoundResponse
+	}
+
+	ctx := r.Context()
+
+	f, err := h.feedStore.Get(ctx, feed.HashToken(token))
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+	if f == nil {
+		return notFoundResponse
+	}
+
+	u, err := h.userStore.Get(ctx, userstore.IDColumn, f.UserID)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+	if u == nil || !u.IsActive() {
+		return notFoundResponse
+	}
+
+	habits, err := h.habitStore.GetAll(ctx, f.UserID)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	calendar := &ical.Calendar{Name: "kera"}
+	for _, habit := range habits {
+		if event, ok := ical.NewHabitEvent(habit); ok {
+			calendar.Events = append(calendar.Events, event)
+		}
+
+		if !f.Completions {
+			continue
+		}
+
+		dates, err := doneDates(ctx, h.habitStore, habit, f.UserID)
+		if err != nil {
+			logError(r, err)
+			return internalServerErrorResponse
+		}
+		for _, d := range dates {
+			calendar.Events = append(calendar.Events, ical.NewDoneEvent(habit, d))
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Cache-Control", "private, no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	if err := calendar.Write(w, time.Now()); err != nil {
+		logError(r, err)
+	}
+	return nil
+}
+
+// doneDates returns the done days of the habit
+// in the last [completionMonths] months.
+func doneDates(
+	ctx context.Context, habitStore habitstore.Store,
+	h *habit.Habit, userID uuid.UUID,
+) ([]date.Date, error) {
+	last := date.Now()
+	if !h.EndDate.IsZero() && h.EndDate.Before(last) {
+		last = h.EndDate
+	}
+
+	month := date.Date(time.Time(last.FirstOfMonth()).AddDate(0, -completionMonths, 0))
+	if month.Before(h.StartDate.FirstOfMonth()) {
+		month = h.StartDate.FirstOfMonth()
+	}
+
+	var dates []date.Date
+	for ; !month.After(last); month = date.Date(time.Time(month).AddDate(0, 1, 0)) {
+		history, err := habitStore.GetMonthHistory(ctx, h.ID, month, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, day := range history {
+			if day.Status == habit.DayDone {
+				dates = append(dates, day.Date)
+			}
+		}
+	}
+
+	return dates, nil
+}
@@ -33,3 +33,30 @@ func setSessionIDCookie(w http.ResponseWriter, sessionID string, expirationDate
 		SameSite: http.SameSiteStrictMode,
 	})
 }
+
+// setOIDCFlowCookie sets a cookie with the state of a pending login
+// at an external provider. It uses the lax mode, so it's sent along
+// with the top-level redirect back from the provider.
+func setOIDCFlowCookie(w http.ResponseWriter, value string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcFlowCookieName,
+		Value:    value,
+		Path:     oidcFlowCookiePath,
+		Secure:   true,
+		HttpOnly: true,
+		MaxAge:   int(oidcFlowLifetime.Seconds()),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func unsetOIDCFlowCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcFlowCookieName,
+		Value:    "",
+		Path:     oidcFlowCookiePath,
+		Secure:   true,
+		HttpOnly: true,
+		MaxAge:   -1,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
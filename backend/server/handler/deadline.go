@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Deadlines represents the deadlines of requests by route pattern,
+// requests matching no pattern get the default deadline.
+type Deadlines struct {
+	mux       *http.ServeMux
+	deadlines map[string]time.Duration
+	fallback  time.Duration
+}
+
+// NewDeadlines returns a new *Deadlines with the default deadline.
+func NewDeadlines(fallback time.Duration) *Deadlines {
+	return &Deadlines{
+		mux:       http.NewServeMux(),
+		deadlines: make(map[string]time.Duration),
+		fallback:  fallback,
+	}
+}
+
+// Set sets the deadline of the requests matching the pattern,
+// like "GET /me/export", patterns follow the rules of [http.ServeMux]
+// and are matched against the full path of the request.
+// It panics if the pattern is invalid or already set.
+func (d *Deadlines) Set(pattern string, deadline time.Duration) {
+	d.mux.Handle(pattern, http.NotFoundHandler())
+	d.deadlines[pattern] = deadline
+}
+
+// of returns the deadline of the request.
+func (d *Deadlines) of(r *http.Request) time.Duration {
+	_, pattern := d.mux.Handler(r)
+	if deadline, ok := d.deadlines[pattern]; ok {
+		return deadline
+	}
+	return d.fallback
+}
+
+// DeadlineMiddleware sets the deadline of the request's context.
+// Store calls and other work of the handlers derived from it
+// are canceled once it's exceeded or the client disconnects.
+func DeadlineMiddleware(next http.Handler, deadlines *Deadlines) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), deadlines.of(r))
+		defer cancel()
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
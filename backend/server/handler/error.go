@@ -3,12 +3,34 @@ package handler
 import "errors"
 
 var (
-	ErrInternalServer       = errors.New("internal server error")
-	ErrUnauthorized         = errors.New("unauthorized")
-	ErrBadRequest           = errors.New("bad request")
-	ErrUnsupportedMediaType = errors.New("unsupported media type")
-	ErrInvalidCredentials   = errors.New("invalid credentials")
-	ErrUsernameAlreadyTaken = errors.New("username is already taken")
+	ErrInternalServer           = errors.New("internal server error")
+	ErrUnauthorized             = errors.New("unauthorized")
+	ErrBadRequest               = errors.New("bad request")
+	ErrNotFound                 = errors.New("not found")
+	ErrUnsupportedMediaType     = errors.New("unsupported media type")
+	ErrInvalidCredentials       = errors.New("invalid credentials")
+	ErrUsernameAlreadyTaken     = errors.New("username is already taken")
+	ErrEmailAlreadyTaken        = errors.New("email is already taken")
+	ErrInvalidToken             = errors.New("token is invalid or has expired")
+	ErrExternalAuthFailed       = errors.New("external authentication failed")
+	ErrIdentityNotLinked        = errors.New("no account is linked to this identity")
+	ErrLastSignInMethod         = errors.New("cannot remove the last sign-in method")
+	ErrInvalidPasskey           = errors.New("passkey is invalid")
+	ErrPasskeyAlreadyRegistered = errors.New("passkey is already registered")
+	ErrForbidden                = errors.New("forbidden")
+	ErrRegistrationClosed       = errors.New("registration is closed")
+	ErrInvalidInvite            = errors.New("invite code is invalid, used up or has expired")
+	ErrAccountPending           = errors.New("account is awaiting approval")
+	ErrUserNotPending           = errors.New("user is not awaiting approval")
+	ErrAccountDisabled          = errors.New("account is disabled")
+	ErrUserAlreadyDisabled      = errors.New("user is already disabled")
+	ErrUserNotDisabled          = errors.New("user is not disabled")
+	ErrSelfAdminAction          = errors.New("admins cannot perform this action on themselves")
+	ErrRequestTooLarge          = errors.New("request is too large")
+	ErrImportConflict           = errors.New("imported habits already exist, see a dry run for conflicts")
+	ErrTooManyFeeds             = errors.New("too many calendar feeds, revoke one first")
+	ErrRequestCanceled          = errors.New("request was canceled")
+	ErrRequestTimeout           = errors.New("request timed out")
 )
 
 type handlerError struct {
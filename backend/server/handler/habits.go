@@ -1,9 +1,7 @@
 package handler
 
 import (
-	"context"
 	"encoding/json"
-	"log"
 	"net/http"
 	"strconv"
 	"time"
@@ -15,13 +13,17 @@ import (
 	"github.com/zvxte/kera/store/userstore"
 )
 
+// NewHabitsMux returns the mux of habits.
+// The history can be changed up to patchWindow back,
+// usually [habit.HistoryPatchWindow].
 func NewHabitsMux(
-	habitStore habitstore.Store, userStore userstore.Store, logger *log.Logger,
+	habitStore habitstore.Store, userStore userstore.Store,
+	patchWindow time.Duration,
 ) *http.ServeMux {
 	h := &habitHandler{
-		habitStore: habitStore,
-		userStore:  userStore,
-		logger:     logger,
+		habitStore:  habitStore,
+		userStore:   userStore,
+		patchWindow: patchWindow,
 	}
 
 	m := http.NewServeMux()
@@ -37,9 +39,9 @@ func NewHabitsMux(
 }
 
 type habitHandler struct {
-	habitStore habitstore.Store
-	userStore  userstore.Store
-	logger     *log.Logger
+	habitStore  habitstore.Store
+	userStore   userstore.Store
+	patchWindow time.Duration
 }
 
 func (h *habitHandler) create(w http.ResponseWriter, r *http.Request) response {
@@ -68,12 +70,11 @@ func (h *habitHandler) create(w http.ResponseWriter, r *http.Request) response {
 		)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	ctx := r.Context()
 
 	err = h.habitStore.Create(ctx, habit, userID)
 	if err != nil {
-		h.logger.Println(err)
+		logError(r, err)
 		return internalServerErrorResponse
 	}
 
@@ -86,12 +87,11 @@ func (h *habitHandler) getAll(w http.ResponseWriter, r *http.Request) response {
 		return internalServerErrorResponse
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	ctx := r.Context()
 
 	habits, err := h.habitStore.GetAll(ctx, userID)
 	if err != nil {
-		h.logger.Println(err)
+		logError(r, err)
 		return internalServerErrorResponse
 	}
 
@@ -147,12 +147,11 @@ func (h *habitHandler) delete(w http.ResponseWriter, r *http.Request) response {
 		return badRequestResponse
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	ctx := r.Context()
 
 	err = h.habitStore.Delete(ctx, habitID, userID)
 	if err != nil {
-		h.logger.Println(err)
+		logError(r, err)
 		return internalServerErrorResponse
 	}
 
@@ -187,14 +186,13 @@ func (h *habitHandler) patchTitle(w http.ResponseWriter, r *http.Request) respon
 		)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	ctx := r.Context()
 
 	err = h.habitStore.Update(
 		ctx, habitID, habitstore.TitleColumn, in.Title, userID,
 	)
 	if err != nil {
-		h.logger.Println(err)
+		logError(r, err)
 		return internalServerErrorResponse
 	}
 
@@ -229,14 +227,13 @@ func (h *habitHandler) patchDescription(w http.ResponseWriter, r *http.Request)
 		)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	ctx := r.Context()
 
 	err = h.habitStore.Update(
 		ctx, id, habitstore.DescriptionColumn, in.Description, userID,
 	)
 	if err != nil {
-		h.logger.Println(err)
+		logError(r, err)
 		return internalServerErrorResponse
 	}
 
@@ -256,12 +253,11 @@ func (h *habitHandler) end(w http.ResponseWriter, r *http.Request) response {
 		return badRequestResponse
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	ctx := r.Context()
 
 	err = h.habitStore.End(ctx, id, userID)
 	if err != nil {
-		h.logger.Println(err)
+		logError(r, err)
 		return internalServerErrorResponse
 	}
 
@@ -286,13 +282,13 @@ func (h *habitHandler) patchHistory(w http.ResponseWriter, r *http.Request) resp
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
-		h.logger.Println(err)
+		logError(r, err)
 		return badRequestResponse
 	}
 
 	patchTime, err := time.Parse("2006-01-02", in.Date)
 	if err != nil {
-		h.logger.Println(err)
+		logError(r, err)
 		return badRequestResponse
 	}
 
@@ -300,16 +296,15 @@ func (h *habitHandler) patchHistory(w http.ResponseWriter, r *http.Request) resp
 	now := date.Now()
 
 	diff := now.Sub(patchDate)
-	if diff < 0 || diff > habit.HistoryPatchWindow {
+	if diff < 0 || diff > h.patchWindow {
 		return badRequestResponse
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	ctx := r.Context()
 
 	err = h.habitStore.UpdateHistory(ctx, id, patchDate, userID)
 	if err != nil {
-		h.logger.Println(err)
+		logError(r, err)
 		return internalServerErrorResponse
 	}
 
@@ -358,12 +353,11 @@ func (h *habitHandler) getHistory(w http.ResponseWriter, r *http.Request) respon
 
 	historyDate := date.New(year, time.Month(month), 1)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	ctx := r.Context()
 
 	history, err := h.habitStore.GetMonthHistory(ctx, id, historyDate, userID)
 	if err != nil {
-		h.logger.Println(err)
+		logError(r, err)
 		return internalServerErrorResponse
 	}
 
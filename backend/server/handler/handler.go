@@ -1,21 +1,49 @@
 package handler
 
 import (
+	"context"
+	"net"
 	"net/http"
 )
 
 const (
-	sessionIDHeaderName = "session_id"
-	userIDContextKey    = "user_id"
+	sessionIDHeaderName       = "session_id"
+	userIDContextKey          = "user_id"
+	hashedSessionIDContextKey = "hashed_session_id"
+	requestInfoContextKey     = "request_info"
 )
 
 type handlerFuncWithResponse func(http.ResponseWriter, *http.Request) response
 
 func makeHandlerFunc(f handlerFuncWithResponse) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		setRoute(r)
 		response := f(w, r)
+		// Failures of requests whose context is done are not server errors
+		if response == internalServerErrorResponse {
+			if canceled := canceledResponse(r); canceled != nil {
+				response = canceled
+			}
+		}
 		if response != nil {
 			response.write(w)
 		}
 	}
 }
+
+// requestContext returns the context of the request without its
+// cancellation and deadline, it carries the request's values,
+// like its span and logger, to background work outliving the request.
+func requestContext(r *http.Request) context.Context {
+	return context.WithoutCancel(r.Context())
+}
+
+// clientIP returns the IP address of the client that sent the request,
+// or an empty string if it can't be determined.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return ""
+	}
+	return host
+}
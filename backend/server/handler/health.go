@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// healthCheckTimeout bounds every readiness check.
+const healthCheckTimeout = 2 * time.Second
+
+// HealthCheck returns nil if the checked dependency is healthy.
+// Its error is shown in the readiness response,
+// so it must not reveal anything sensitive.
+type HealthCheck func(ctx context.Context) error
+
+type healthCheck struct {
+	name  string
+	check HealthCheck
+}
+
+// Health represents the state reported by the liveness
+// and readiness endpoints.
+type Health struct {
+	checks       []healthCheck
+	shuttingDown atomic.Bool
+}
+
+// NewHealth returns a new *Health without checks.
+func NewHealth() *Health {
+	return &Health{}
+}
+
+// AddCheck adds a readiness check with the name.
+// It must be called before the health is served.
+func (h *Health) AddCheck(name string, check HealthCheck) {
+	h.checks = append(h.checks, healthCheck{name, check})
+}
+
+// ShutDown marks the server as shutting down,
+// readiness is reported as failed from now on.
+func (h *Health) ShutDown() {
+	h.shuttingDown.Store(true)
+}
+
+// NewHealthMux returns a mux serving GET /healthz, for liveness,
+// and GET /readyz, for readiness.
+func NewHealthMux(health *Health) *http.ServeMux {
+	m := http.NewServeMux()
+	m.HandleFunc("GET /healthz", makeHandlerFunc(health.Live))
+	m.HandleFunc("GET /readyz", makeHandlerFunc(health.Ready))
+	return m
+}
+
+type healthOut struct {
+	Status string           `json:"status"`
+	Checks []healthCheckOut `json:"checks,omitempty"`
+}
+
+type healthCheckOut struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Live reports that the server is running, it does not check anything
+// else, so a failing dependency does not restart the server.
+func (h *Health) Live(w http.ResponseWriter, r *http.Request) response {
+	return newJsonResponse(http.StatusOK, healthOut{Status: "ok"})
+}
+
+// Ready reports whether the server can handle requests,
+// running every check concurrently.
+// It responds with 503 if a check fails or the server is shutting down.
+func (h *Health) Ready(w http.ResponseWriter, r *http.Request) response {
+	if h.shuttingDown.Load() {
+		return newJsonResponse(
+			http.StatusServiceUnavailable, healthOut{Status: "shutting_down"},
+		)
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+	defer cancel()
+
+	outs := make([]healthCheckOut, len(h.checks))
+	var wg sync.WaitGroup
+	for i, c := range h.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			outs[i] = healthCheckOut{Name: c.name, Status: "ok"}
+			if err := c.check(ctx); err != nil {
+				outs[i].Status = "failed"
+				outs[i].Error = err.Error()
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, out := range outs {
+		if out.Status != "ok" {
+			return newJsonResponse(
+				http.StatusServiceUnavailable,
+				healthOut{Status: "not_ready", Checks: outs},
+			)
+		}
+	}
+	return newJsonResponse(http.StatusOK, healthOut{Status: "ready", Checks: outs})
+}
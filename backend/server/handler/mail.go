@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/zvxte/kera/mail"
+	"github.com/zvxte/kera/model/token"
+)
+
+func newPasswordResetMessage(to, tokenID string) mail.Message {
+	return mail.Message{
+		To:      to,
+		Subject: "kera password reset",
+		Body: fmt.Sprintf(
+			"A password reset was requested for your kera account.\n\n"+
+				"Your password reset token: %s\n\n"+
+				"It can be used once and expires in %s.\n"+
+				"If you did not request it, you can ignore this message.",
+			tokenID, token.PasswordReset.Lifetime(),
+		),
+	}
+}
+
+func newEmailVerificationMessage(to, tokenID string) mail.Message {
+	return mail.Message{
+		To:      to,
+		Subject: "kera email verification",
+		Body: fmt.Sprintf(
+			"This email was added to your kera account.\n\n"+
+				"Your email verification token: %s\n\n"+
+				"It can be used once and expires in %s.\n"+
+				"If you did not add it, you can ignore this message.",
+			tokenID, token.EmailVerification.Lifetime(),
+		),
+	}
+}
@@ -1,45 +1,122 @@
 package handler
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
-	"log"
+	"errors"
+	"io"
+	"mime"
 	"net/http"
+	"strconv"
 	"time"
 
-	"github.com/zvxte/kera/hash/argon2id"
+	"github.com/zvxte/kera/export"
 	"github.com/zvxte/kera/hash/sha256"
+	"github.com/zvxte/kera/importer"
+	"github.com/zvxte/kera/mail"
+	"github.com/zvxte/kera/model/challenge"
+	"github.com/zvxte/kera/model/feed"
+	"github.com/zvxte/kera/model/passkey"
 	"github.com/zvxte/kera/model/session"
+	"github.com/zvxte/kera/model/token"
 	"github.com/zvxte/kera/model/user"
 	"github.com/zvxte/kera/model/uuid"
+	"github.com/zvxte/kera/store/challengestore"
+	"github.com/zvxte/kera/store/feedstore"
+	"github.com/zvxte/kera/store/identitystore"
+	"github.com/zvxte/kera/store/passkeystore"
 	"github.com/zvxte/kera/store/sessionstore"
+	"github.com/zvxte/kera/store/tokenstore"
 	"github.com/zvxte/kera/store/userstore"
+	"github.com/zvxte/kera/tracing"
+	"github.com/zvxte/kera/webauthn"
+)
+
+const (
+	// importMaxBytes is the maximum size of an imported file.
+	importMaxBytes = 10 << 20
+
+	// maxFeeds is the maximum number of calendar feeds of a user.
+	maxFeeds = 10
 )
 
 func NewMeMux(
-	userStore userstore.Store, sessionStore sessionstore.Store, logger *log.Logger,
+	userStore userstore.Store,
+	sessionStore sessionstore.Store,
+	tokenStore tokenstore.Store,
+	identityStore identitystore.Store,
+	passkeyStore passkeystore.Store,
+	challengeStore challengestore.Store,
+	feedStore feedstore.Store,
+	relyingParty *webauthn.RelyingParty,
+	mailer mail.Mailer,
+	exporter *export.Exporter,
+	habitImporter *importer.Importer,
+	passwordPolicy *user.PasswordPolicy,
+	passwordHasher user.PasswordHasher,
+	background *Background,
 ) *http.ServeMux {
 	h := &meHandler{
-		userStore:    userStore,
-		sessionStore: sessionStore,
-		logger:       logger,
+		userStore:      userStore,
+		sessionStore:   sessionStore,
+		tokenStore:     tokenStore,
+		identityStore:  identityStore,
+		passkeyStore:   passkeyStore,
+		challengeStore: challengeStore,
+		feedStore:      feedStore,
+		relyingParty:   relyingParty,
+		mailer:         mailer,
+		exporter:       exporter,
+		importer:       habitImporter,
+		passwordPolicy: passwordPolicy,
+		passwordHasher: passwordHasher,
+		background:     background,
 	}
 
 	m := http.NewServeMux()
 	m.HandleFunc("GET /{$}", makeHandlerFunc(h.get))
 	m.HandleFunc("DELETE /{$}", makeHandlerFunc(h.delete))
+	m.HandleFunc("GET /export", makeHandlerFunc(h.export))
+	m.HandleFunc("POST /import", makeHandlerFunc(h.importHabits))
 	m.HandleFunc("PATCH /display-name", makeHandlerFunc(h.patchDisplayName))
 	m.HandleFunc("PATCH /password", makeHandlerFunc(h.patchPassword))
+	m.HandleFunc("PATCH /email", makeHandlerFunc(h.patchEmail))
 	m.HandleFunc("POST /logout", makeHandlerFunc(h.logout))
-	m.HandleFunc("GET /sessions", makeHandlerFunc(h.getSessionsCount))
+	m.HandleFunc("GET /sessions", makeHandlerFunc(h.getSessions))
 	m.HandleFunc("DELETE /sessions", makeHandlerFunc(h.deleteSessions))
+	m.HandleFunc("DELETE /sessions/others", makeHandlerFunc(h.deleteOtherSessions))
+	m.HandleFunc("DELETE /sessions/{id}", makeHandlerFunc(h.deleteSession))
+	m.HandleFunc("GET /identities", makeHandlerFunc(h.getIdentities))
+	m.HandleFunc("DELETE /identities/{id}", makeHandlerFunc(h.deleteIdentity))
+	m.HandleFunc("GET /passkeys", makeHandlerFunc(h.getPasskeys))
+	m.HandleFunc("PATCH /passkeys/{id}", makeHandlerFunc(h.patchPasskey))
+	m.HandleFunc("DELETE /passkeys/{id}", makeHandlerFunc(h.deletePasskey))
+	m.HandleFunc("GET /feeds", makeHandlerFunc(h.getFeeds))
+	m.HandleFunc("POST /feeds", makeHandlerFunc(h.createFeed))
+	m.HandleFunc("DELETE /feeds/{id}", makeHandlerFunc(h.deleteFeed))
+	if relyingParty != nil {
+		m.HandleFunc("POST /passkeys/options", makeHandlerFunc(h.passkeyOptions))
+		m.HandleFunc("POST /passkeys", makeHandlerFunc(h.createPasskey))
+	}
 	return m
 }
 
 type meHandler struct {
-	userStore    userstore.Store
-	sessionStore sessionstore.Store
-	logger       *log.Logger
+	userStore      userstore.Store
+	sessionStore   sessionstore.Store
+	tokenStore     tokenstore.Store
+	identityStore  identitystore.Store
+	passkeyStore   passkeystore.Store
+	challengeStore challengestore.Store
+	feedStore      feedstore.Store
+	relyingParty   *webauthn.RelyingParty
+	mailer         mail.Mailer
+	exporter       *export.Exporter
+	importer       *importer.Importer
+	passwordPolicy *user.PasswordPolicy
+	passwordHasher user.PasswordHasher
+	background     *Background
 }
 
 func (h *meHandler) get(w http.ResponseWriter, r *http.Request) response {
@@ -48,12 +125,11 @@ func (h *meHandler) get(w http.ResponseWriter, r *http.Request) response {
 		return internalServerErrorResponse
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	ctx := r.Context()
 
 	user, err := h.userStore.Get(ctx, userstore.IDColumn, userID)
 	if err != nil {
-		h.logger.Println(err)
+		logError(r, err)
 		return internalServerErrorResponse
 	}
 
@@ -65,13 +141,19 @@ func (h *meHandler) get(w http.ResponseWriter, r *http.Request) response {
 	return newJsonResponse(
 		http.StatusOK,
 		struct {
-			Username     string    `json:"username"`
-			DisplayName  string    `json:"display_name"`
-			CreationDate time.Time `json:"creation_date"`
+			Username      string    `json:"username"`
+			DisplayName   string    `json:"display_name"`
+			Email         string    `json:"email"`
+			EmailVerified bool      `json:"email_verified"`
+			Role          string    `json:"role"`
+			CreationDate  time.Time `json:"creation_date"`
 		}{
-			Username:     user.Username,
-			DisplayName:  user.DisplayName,
-			CreationDate: time.Time(user.CreationDate),
+			Username:      user.Username,
+			DisplayName:   user.DisplayName,
+			Email:         user.Email,
+			EmailVerified: user.EmailVerified,
+			Role:          user.Role.String(),
+			CreationDate:  time.Time(user.CreationDate),
 		},
 	)
 }
@@ -82,12 +164,11 @@ func (h *meHandler) delete(w http.ResponseWriter, r *http.Request) response {
 		return internalServerErrorResponse
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	ctx := r.Context()
 
 	err := h.userStore.Delete(ctx, userID)
 	if err != nil {
-		h.logger.Println(err)
+		logError(r, err)
 		return internalServerErrorResponse
 	}
 
@@ -95,6 +176,190 @@ func (h *meHandler) delete(w http.ResponseWriter, r *http.Request) response {
 	return noContentResponse{}
 }
 
+// export streams a ZIP archive of all the user's data.
+// The data is collected before anything is written,
+// so a store failure still results in an error response.
+func (h *meHandler) export(w http.ResponseWriter, r *http.Request) response {
+	userID, ok := r.Context().Value(userIDContextKey).(uuid.UUID)
+	if !ok {
+		return internalServerErrorResponse
+	}
+
+	ctx := r.Context()
+
+	takeout, err := h.exporter.Collect(ctx, userID)
+	if errors.Is(err, export.ErrUserNotFound) {
+		unsetSessionIDCookie(w)
+		return unauthorizedResponse
+	}
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	filename := "kera-export-" + takeout.CreationTime.Format(time.DateOnly) + ".zip"
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+
+	if err := takeout.WriteZip(w); err != nil {
+		logError(r, err)
+	}
+	return nil
+}
+
+// importHabits imports habits with their history from a file
+// of the format query parameter:
+//   - kera: a kera archive, see [export.ReadZip], restored into the account
+//     along with the display name, see [importer.NewRestorePlan]
+//   - loop: a Loop Habit Tracker CSV export, either the ZIP archive
+//     or its Checkmarks.csv file
+//   - csv: a generic CSV file, see [importer.ReadCsv]
+//
+// The on_conflict query parameter decides what happens to habits
+// that already exist, see [importer.ParseConflictMode].
+// With the dry_run query parameter, nothing is imported,
+// only the report of the import is returned.
+// The history is imported regardless of [habit.HistoryPatchWindow].
+func (h *meHandler) importHabits(w http.ResponseWriter, r *http.Request) response {
+	userID, ok := r.Context().Value(userIDContextKey).(uuid.UUID)
+	if !ok {
+		return internalServerErrorResponse
+	}
+
+	query := r.URL.Query()
+
+	mode, err := importer.ParseConflictMode(query.Get("on_conflict"))
+	if err != nil {
+		return newJsonResponse(
+			http.StatusBadRequest,
+			newHandlerError(http.StatusBadRequest, err.Error()),
+		)
+	}
+
+	var dryRun bool
+	if value := query.Get("dry_run"); value != "" {
+		dryRun, err = strconv.ParseBool(value)
+		if err != nil {
+			return badRequestResponse
+		}
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	format := query.Get("format")
+	switch format {
+	case "kera":
+		if mediaType != "application/zip" {
+			return unsupportedMediaTypeResponse
+		}
+	case "loop":
+		if mediaType != "application/zip" && mediaType != "text/csv" {
+			return unsupportedMediaTypeResponse
+		}
+	case "csv":
+		if mediaType != "text/csv" {
+			return unsupportedMediaTypeResponse
+		}
+	default:
+		return badRequestResponse
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, importMaxBytes))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return requestTooLargeResponse
+		}
+		return badRequestResponse
+	}
+
+	var archive *export.Export
+	var habits []*importer.Habit
+	switch {
+	case format == "kera":
+		archive, err = export.ReadZip(bytes.NewReader(body), int64(len(body)))
+	case format == "csv":
+		habits, err = importer.ReadCsv(bytes.NewReader(body))
+	case mediaType == "application/zip":
+		habits, err = importer.ReadLoopZip(bytes.NewReader(body), int64(len(body)))
+	default:
+		habits, err = importer.ReadLoop(bytes.NewReader(body))
+	}
+	if err != nil {
+		return newJsonResponse(
+			http.StatusBadRequest,
+			newHandlerError(http.StatusBadRequest, err.Error()),
+		)
+	}
+
+	ctx := r.Context()
+
+	var plan *importer.Plan
+	if archive != nil {
+		plan, err = h.importer.Restore(ctx, userID, archive, mode, dryRun)
+	} else {
+		plan, err = h.importer.Import(ctx, userID, habits, mode, dryRun)
+	}
+
+	var habitErr *importer.HabitError
+	switch {
+	case errors.As(err, &habitErr):
+		return newJsonResponse(
+			http.StatusBadRequest,
+			newHandlerError(http.StatusBadRequest, err.Error()),
+		)
+	case errors.Is(err, importer.ErrBlocked):
+		return importConflictResponse
+	case errors.Is(err, importer.ErrUserNotFound):
+		unsetSessionIDCookie(w)
+		return unauthorizedResponse
+	case err != nil:
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	type conflictOut struct {
+		Habit  string     `json:"habit"`
+		Date   *time.Time `json:"date"`
+		Reason string     `json:"reason"`
+	}
+
+	out := struct {
+		DryRun        bool          `json:"dry_run"`
+		HabitsCreated int           `json:"habits_created"`
+		HabitsMerged  int           `json:"habits_merged"`
+		HabitsSkipped int           `json:"habits_skipped"`
+		DaysImported  int           `json:"days_imported"`
+		DisplayName   *string       `json:"display_name"`
+		Conflicts     []conflictOut `json:"conflicts"`
+	}{
+		DryRun:        dryRun,
+		HabitsCreated: plan.HabitsCreated,
+		HabitsMerged:  plan.HabitsMerged,
+		HabitsSkipped: plan.HabitsSkipped,
+		DaysImported:  plan.DaysImported,
+		Conflicts:     make([]conflictOut, len(plan.Conflicts)),
+	}
+	if plan.DisplayName != "" {
+		out.DisplayName = &plan.DisplayName
+	}
+
+	for i, conflict := range plan.Conflicts {
+		out.Conflicts[i] = conflictOut{
+			Habit:  conflict.Title,
+			Reason: conflict.Reason.String(),
+		}
+		if !conflict.Date.IsZero() {
+			conflictDate := time.Time(conflict.Date)
+			out.Conflicts[i].Date = &conflictDate
+		}
+	}
+
+	return newJsonResponse(http.StatusOK, out)
+}
+
 func (h *meHandler) patchDisplayName(w http.ResponseWriter, r *http.Request) response {
 	if r.Header.Get("Content-Type") != "application/json" {
 		return unsupportedMediaTypeResponse
@@ -121,14 +386,13 @@ func (h *meHandler) patchDisplayName(w http.ResponseWriter, r *http.Request) res
 		)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	ctx := r.Context()
 
 	err = h.userStore.Update(
 		ctx, userID, userstore.DisplayNameColumn, in.DisplayName,
 	)
 	if err != nil {
-		h.logger.Println(err)
+		logError(r, err)
 		return internalServerErrorResponse
 	}
 
@@ -154,7 +418,7 @@ func (h *meHandler) patchPassword(w http.ResponseWriter, r *http.Request) respon
 		return badRequestResponse
 	}
 
-	err := user.ValidatePlainPassword(in.NewPlainPassword)
+	err := h.passwordPolicy.Validate("", in.NewPlainPassword)
 	if err != nil {
 		return newJsonResponse(
 			http.StatusBadRequest,
@@ -167,31 +431,43 @@ func (h *meHandler) patchPassword(w http.ResponseWriter, r *http.Request) respon
 		return invalidCredentialsResponse
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	ctx := r.Context()
 
 	user, err := h.userStore.Get(ctx, userstore.IDColumn, userID)
 	if err != nil {
-		h.logger.Println(err)
+		logError(r, err)
 		return internalServerErrorResponse
 	}
 
-	isValid, err := argon2id.VerifyHash(
+	if !user.HasPassword() {
+		return invalidCredentialsResponse
+	}
+
+	hasher := tracing.Hasher(r.Context(), h.passwordHasher)
+	isValid, err := hasher.Verify(
 		in.PlainPassword, user.HashedPassword,
 	)
 	if err != nil {
-		h.logger.Println(err)
+		logError(r, err)
 		return internalServerErrorResponse
 	}
 	if !isValid {
 		return invalidCredentialsResponse
 	}
 
-	newHashedPassword, err := argon2id.Hash(
-		in.NewPlainPassword, argon2id.DefaultParams,
+	err = h.passwordPolicy.Validate(user.Username, in.NewPlainPassword)
+	if err != nil {
+		return newJsonResponse(
+			http.StatusBadRequest,
+			newHandlerError(http.StatusBadRequest, err.Error()),
+		)
+	}
+
+	newHashedPassword, err := hasher.Hash(
+		in.NewPlainPassword,
 	)
 	if err != nil {
-		h.logger.Println(err)
+		logError(r, err)
 		return internalServerErrorResponse
 	}
 
@@ -199,21 +475,107 @@ func (h *meHandler) patchPassword(w http.ResponseWriter, r *http.Request) respon
 		ctx, user.ID, userstore.HashedPasswordColumn, newHashedPassword,
 	)
 	if err != nil {
-		h.logger.Println(err)
+		logError(r, err)
 		return internalServerErrorResponse
 	}
 
 	return noContentResponse{}
 }
 
+func (h *meHandler) patchEmail(w http.ResponseWriter, r *http.Request) response {
+	if r.Header.Get("Content-Type") != "application/json" {
+		return unsupportedMediaTypeResponse
+	}
+
+	userID, ok := r.Context().Value(userIDContextKey).(uuid.UUID)
+	if !ok {
+		return internalServerErrorResponse
+	}
+
+	var in struct {
+		Email string `json:"email"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		return badRequestResponse
+	}
+
+	// An empty email removes it from the account
+	if in.Email != "" {
+		if err := user.ValidateEmail(in.Email); err != nil {
+			return newJsonResponse(
+				http.StatusBadRequest,
+				newHandlerError(http.StatusBadRequest, err.Error()),
+			)
+		}
+	}
+
+	ctx := r.Context()
+
+	if in.Email != "" {
+		owner, err := h.userStore.Get(ctx, userstore.EmailColumn, in.Email)
+		if err != nil {
+			logError(r, err)
+			return internalServerErrorResponse
+		}
+		if owner != nil && owner.ID != userID {
+			return emailAlreadyTakenResponse
+		}
+	}
+
+	err := h.userStore.Update(ctx, userID, userstore.EmailColumn, in.Email)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	err = h.tokenStore.DeleteAll(ctx, userID, token.EmailVerification)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	if in.Email == "" {
+		return noContentResponse{}
+	}
+
+	tokenID, token, err := token.New(userID, token.EmailVerification, in.Email)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	err = h.tokenStore.Create(ctx, token)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	h.background.Go(func() {
+		ctx, cancel := context.WithTimeout(requestContext(r), 30*time.Second)
+		defer cancel()
+
+		err := h.mailer.Send(ctx, newEmailVerificationMessage(in.Email, tokenID))
+		if err != nil {
+			logError(r, err)
+		}
+	})
+
+	return noContentResponse{}
+}
+
 func (h *meHandler) logout(w http.ResponseWriter, r *http.Request) response {
-	go func() {
-		sessionID := r.Header.Get(sessionIDHeaderName)
+	// The request must not be used once the handler returns
+	sessionID := r.Header.Get(sessionIDHeaderName)
+	requestCtx := requestContext(r)
+	logger := requestLogger(r)
+
+	h.background.Go(func() {
 		if sessionID == "" {
 			return
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		ctx, cancel := context.WithTimeout(requestCtx, 5*time.Second)
 		defer cancel()
 
 		hashedSessionID := session.HashedID(sha256.Hash(sessionID))
@@ -222,35 +584,57 @@ func (h *meHandler) logout(w http.ResponseWriter, r *http.Request) response {
 			ctx, sessionstore.HashedIDColumn, hashedSessionID,
 		)
 		if err != nil {
-			h.logger.Println(err)
+			logger.Error("failed to end session", "error", err)
 		}
-	}()
+	})
 
 	unsetSessionIDCookie(w)
 	return noContentResponse{}
 }
 
-func (h *meHandler) getSessionsCount(w http.ResponseWriter, r *http.Request) response {
+func (h *meHandler) getSessions(w http.ResponseWriter, r *http.Request) response {
 	userID, ok := r.Context().Value(userIDContextKey).(uuid.UUID)
 	if !ok {
 		return internalServerErrorResponse
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	hashedSessionID, ok := r.Context().Value(hashedSessionIDContextKey).(session.HashedID)
+	if !ok {
+		return internalServerErrorResponse
+	}
+
+	ctx := r.Context()
 
-	count, err := h.sessionStore.Count(ctx, userID)
+	sessions, err := h.sessionStore.GetAll(ctx, userID)
 	if err != nil {
-		h.logger.Println(err)
+		logError(r, err)
 		return internalServerErrorResponse
 	}
 
-	return newJsonResponse(
-		http.StatusOK,
-		struct {
-			Count uint `json:"count"`
-		}{Count: count},
-	)
+	type out struct {
+		ID             string    `json:"id"`
+		UserAgent      string    `json:"user_agent"`
+		IPAddress      string    `json:"ip_address"`
+		CreationTime   time.Time `json:"creation_time"`
+		LastSeenTime   time.Time `json:"last_seen_time"`
+		ExpirationTime time.Time `json:"expiration_time"`
+		Current        bool      `json:"current"`
+	}
+
+	outs := make([]out, len(sessions))
+	for i, session := range sessions {
+		outs[i] = out{
+			ID:             session.PublicID.String(),
+			UserAgent:      session.UserAgent,
+			IPAddress:      session.IPAddress,
+			CreationTime:   session.CreationTime,
+			LastSeenTime:   session.LastSeenTime,
+			ExpirationTime: session.ExpirationTime,
+			Current:        session.HashedID == hashedSessionID,
+		}
+	}
+
+	return newJsonResponse(http.StatusOK, outs)
 }
 
 func (h *meHandler) deleteSessions(w http.ResponseWriter, r *http.Request) response {
@@ -259,15 +643,535 @@ func (h *meHandler) deleteSessions(w http.ResponseWriter, r *http.Request) respo
 		return internalServerErrorResponse
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	ctx := r.Context()
 
 	err := h.sessionStore.Delete(ctx, sessionstore.UserIDColumn, userID)
 	if err != nil {
-		h.logger.Println(err)
+		logError(r, err)
 		return internalServerErrorResponse
 	}
 
 	unsetSessionIDCookie(w)
 	return noContentResponse{}
 }
+
+func (h *meHandler) deleteSession(w http.ResponseWriter, r *http.Request) response {
+	userID, ok := r.Context().Value(userIDContextKey).(uuid.UUID)
+	if !ok {
+		return internalServerErrorResponse
+	}
+
+	publicID, err := uuid.Parse(
+		r.PathValue("id"),
+	)
+	if err != nil {
+		return badRequestResponse
+	}
+
+	ctx := r.Context()
+
+	deleted, err := h.sessionStore.DeleteByPublicID(ctx, publicID, userID)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+	if !deleted {
+		return notFoundResponse
+	}
+
+	return noContentResponse{}
+}
+
+func (h *meHandler) deleteOtherSessions(w http.ResponseWriter, r *http.Request) response {
+	userID, ok := r.Context().Value(userIDContextKey).(uuid.UUID)
+	if !ok {
+		return internalServerErrorResponse
+	}
+
+	hashedSessionID, ok := r.Context().Value(hashedSessionIDContextKey).(session.HashedID)
+	if !ok {
+		return internalServerErrorResponse
+	}
+
+	ctx := r.Context()
+
+	err := h.sessionStore.DeleteAllExcept(ctx, userID, hashedSessionID)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	return noContentResponse{}
+}
+
+func (h *meHandler) getIdentities(w http.ResponseWriter, r *http.Request) response {
+	userID, ok := r.Context().Value(userIDContextKey).(uuid.UUID)
+	if !ok {
+		return internalServerErrorResponse
+	}
+
+	ctx := r.Context()
+
+	identities, err := h.identityStore.GetAll(ctx, userID)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	type out struct {
+		ID           string    `json:"id"`
+		Issuer       string    `json:"issuer"`
+		Email        string    `json:"email"`
+		CreationTime time.Time `json:"creation_time"`
+	}
+
+	outs := make([]out, len(identities))
+	for i, identity := range identities {
+		outs[i] = out{
+			ID:           identity.ID.String(),
+			Issuer:       identity.Issuer,
+			Email:        identity.Email,
+			CreationTime: identity.CreationTime,
+		}
+	}
+
+	return newJsonResponse(http.StatusOK, outs)
+}
+
+func (h *meHandler) deleteIdentity(w http.ResponseWriter, r *http.Request) response {
+	userID, ok := r.Context().Value(userIDContextKey).(uuid.UUID)
+	if !ok {
+		return internalServerErrorResponse
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		return badRequestResponse
+	}
+
+	ctx := r.Context()
+
+	user, err := h.userStore.Get(ctx, userstore.IDColumn, userID)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+	if user == nil {
+		unsetSessionIDCookie(w)
+		return unauthorizedResponse
+	}
+
+	methods, err := h.signInMethods(ctx, user)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+	if methods <= 1 {
+		return lastSignInMethodResponse
+	}
+
+	deleted, err := h.identityStore.Delete(ctx, id, userID)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+	if !deleted {
+		return notFoundResponse
+	}
+
+	return noContentResponse{}
+}
+
+func (h *meHandler) getPasskeys(w http.ResponseWriter, r *http.Request) response {
+	userID, ok := r.Context().Value(userIDContextKey).(uuid.UUID)
+	if !ok {
+		return internalServerErrorResponse
+	}
+
+	ctx := r.Context()
+
+	passkeys, err := h.passkeyStore.GetAll(ctx, userID)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	type out struct {
+		ID           string     `json:"id"`
+		Name         string     `json:"name"`
+		CreationTime time.Time  `json:"creation_time"`
+		LastUsedTime *time.Time `json:"last_used_time"`
+	}
+
+	outs := make([]out, len(passkeys))
+	for i, passkey := range passkeys {
+		outs[i] = out{
+			ID:           passkey.ID.String(),
+			Name:         passkey.Name,
+			CreationTime: passkey.CreationTime,
+		}
+		if !passkey.LastUsedTime.IsZero() {
+			outs[i].LastUsedTime = &passkey.LastUsedTime
+		}
+	}
+
+	return newJsonResponse(http.StatusOK, outs)
+}
+
+// passkeyOptions starts a registration ceremony and returns its options
+// to be passed to navigator.credentials.create().
+func (h *meHandler) passkeyOptions(w http.ResponseWriter, r *http.Request) response {
+	userID, ok := r.Context().Value(userIDContextKey).(uuid.UUID)
+	if !ok {
+		return internalServerErrorResponse
+	}
+
+	ctx := r.Context()
+
+	user, err := h.userStore.Get(ctx, userstore.IDColumn, userID)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+	if user == nil {
+		unsetSessionIDCookie(w)
+		return unauthorizedResponse
+	}
+
+	passkeys, err := h.passkeyStore.GetAll(ctx, userID)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	excludeCredentialIDs := make([][]byte, len(passkeys))
+	for i, passkey := range passkeys {
+		excludeCredentialIDs[i] = passkey.CredentialID
+	}
+
+	challengeID, challenge, err := challenge.New(
+		challenge.Registration, userID, h.relyingParty.Timeout(),
+	)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	err = h.challengeStore.Create(ctx, challenge)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	options := h.relyingParty.CreationOptions(
+		challengeID,
+		webauthn.UserEntity{
+			ID:          userID[:],
+			Name:        user.Username,
+			DisplayName: user.DisplayName,
+		},
+		excludeCredentialIDs,
+	)
+
+	return newJsonResponse(http.StatusOK, struct {
+		PublicKey webauthn.CreationOptions `json:"publicKey"`
+	}{options})
+}
+
+// createPasskey finishes a registration ceremony and stores the passkey.
+func (h *meHandler) createPasskey(w http.ResponseWriter, r *http.Request) response {
+	if r.Header.Get("Content-Type") != "application/json" {
+		return unsupportedMediaTypeResponse
+	}
+
+	userID, ok := r.Context().Value(userIDContextKey).(uuid.UUID)
+	if !ok {
+		return internalServerErrorResponse
+	}
+
+	var in struct {
+		Name       string                       `json:"name"`
+		Credential webauthn.AttestationResponse `json:"credential"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		return badRequestResponse
+	}
+
+	if err := passkey.ValidateName(in.Name); err != nil {
+		return newJsonResponse(
+			http.StatusBadRequest,
+			newHandlerError(http.StatusBadRequest, err.Error()),
+		)
+	}
+
+	challengeID, err := webauthn.Challenge(in.Credential.Response.ClientDataJSON)
+	if err != nil {
+		return badRequestResponse
+	}
+
+	ctx := r.Context()
+
+	challenge, err := h.challengeStore.Consume(
+		ctx, webauthn.HashChallenge(challengeID), challenge.Registration,
+	)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+	if challenge == nil || challenge.UserID != userID {
+		return invalidPasskeyResponse
+	}
+
+	credential, err := h.relyingParty.VerifyRegistration(challengeID, &in.Credential)
+	if err != nil {
+		return invalidPasskeyResponse
+	}
+
+	passkey, err := passkey.New(
+		userID, in.Name,
+		credential.ID, credential.PublicKey, credential.SignCount,
+	)
+	if err != nil {
+		return newJsonResponse(
+			http.StatusBadRequest,
+			newHandlerError(http.StatusBadRequest, err.Error()),
+		)
+	}
+
+	err = h.passkeyStore.Create(ctx, passkey)
+	if err == passkeystore.ErrCredentialAlreadyRegistered {
+		return passkeyAlreadyRegisteredResponse
+	}
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	return createdResponse{}
+}
+
+func (h *meHandler) patchPasskey(w http.ResponseWriter, r *http.Request) response {
+	if r.Header.Get("Content-Type") != "application/json" {
+		return unsupportedMediaTypeResponse
+	}
+
+	userID, ok := r.Context().Value(userIDContextKey).(uuid.UUID)
+	if !ok {
+		return internalServerErrorResponse
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		return badRequestResponse
+	}
+
+	var in struct {
+		Name string `json:"name"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		return badRequestResponse
+	}
+
+	if err := passkey.ValidateName(in.Name); err != nil {
+		return newJsonResponse(
+			http.StatusBadRequest,
+			newHandlerError(http.StatusBadRequest, err.Error()),
+		)
+	}
+
+	ctx := r.Context()
+
+	updated, err := h.passkeyStore.UpdateName(ctx, id, userID, in.Name)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+	if !updated {
+		return notFoundResponse
+	}
+
+	return noContentResponse{}
+}
+
+func (h *meHandler) deletePasskey(w http.ResponseWriter, r *http.Request) response {
+	userID, ok := r.Context().Value(userIDContextKey).(uuid.UUID)
+	if !ok {
+		return internalServerErrorResponse
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		return badRequestResponse
+	}
+
+	ctx := r.Context()
+
+	user, err := h.userStore.Get(ctx, userstore.IDColumn, userID)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+	if user == nil {
+		unsetSessionIDCookie(w)
+		return unauthorizedResponse
+	}
+
+	methods, err := h.signInMethods(ctx, user)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+	if methods <= 1 {
+		return lastSignInMethodResponse
+	}
+
+	deleted, err := h.passkeyStore.Delete(ctx, id, userID)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+	if !deleted {
+		return notFoundResponse
+	}
+
+	return noContentResponse{}
+}
+
+func (h *meHandler) getFeeds(w http.ResponseWriter, r *http.Request) response {
+	userID, ok := r.Context().Value(userIDContextKey).(uuid.UUID)
+	if !ok {
+		return internalServerErrorResponse
+	}
+
+	ctx := r.Context()
+
+	feeds, err := h.feedStore.GetAll(ctx, userID)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	type out struct {
+		ID           string    `json:"id"`
+		Completions  bool      `json:"completions"`
+		CreationTime time.Time `json:"creation_time"`
+	}
+
+	outs := make([]out, len(feeds))
+	for i, feed := range feeds {
+		outs[i] = out{
+			ID:           feed.ID.String(),
+			Completions:  feed.Completions,
+			CreationTime: feed.CreationTime,
+		}
+	}
+
+	return newJsonResponse(http.StatusOK, outs)
+}
+
+// createFeed creates a calendar feed and returns its token and path.
+// The token is only returned here, it can't be recovered later.
+func (h *meHandler) createFeed(w http.ResponseWriter, r *http.Request) response {
+	if r.Header.Get("Content-Type") != "application/json" {
+		return unsupportedMediaTypeResponse
+	}
+
+	userID, ok := r.Context().Value(userIDContextKey).(uuid.UUID)
+	if !ok {
+		return internalServerErrorResponse
+	}
+
+	var in struct {
+		Completions bool `json:"completions"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		return badRequestResponse
+	}
+
+	ctx := r.Context()
+
+	feeds, err := h.feedStore.GetAll(ctx, userID)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+	if len(feeds) >= maxFeeds {
+		return tooManyFeedsResponse
+	}
+
+	token, f, err := feed.New(userID, in.Completions)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	if err := h.feedStore.Create(ctx, f); err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	return newJsonResponse(
+		http.StatusCreated,
+		struct {
+			ID          string `json:"id"`
+			Token       string `json:"token"`
+			Path        string `json:"path"`
+			Completions bool   `json:"completions"`
+		}{
+			ID:          f.ID.String(),
+			Token:       token,
+			Path:        calendarPath(token),
+			Completions: f.Completions,
+		},
+	)
+}
+
+func (h *meHandler) deleteFeed(w http.ResponseWriter, r *http.Request) response {
+	userID, ok := r.Context().Value(userIDContextKey).(uuid.UUID)
+	if !ok {
+		return internalServerErrorResponse
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		return badRequestResponse
+	}
+
+	ctx := r.Context()
+
+	deleted, err := h.feedStore.Delete(ctx, id, userID)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+	if !deleted {
+		return notFoundResponse
+	}
+
+	return noContentResponse{}
+}
+
+// signInMethods returns the number of ways the user can log in:
+// a password, linked identities and passkeys.
+// Removing the last one would lock the user out.
+func (h *meHandler) signInMethods(ctx context.Context, user *user.User) (int, error) {
+	methods := 0
+	if user.HasPassword() {
+		methods++
+	}
+
+	identities, err := h.identityStore.GetAll(ctx, user.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	passkeys, err := h.passkeyStore.GetAll(ctx, user.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	return methods + len(identities) + len(passkeys), nil
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/zvxte/kera/metrics"
+)
+
+// Login methods, the method label of the logins metric.
+const (
+	passwordLoginMethod = "password"
+	passkeyLoginMethod  = "passkey"
+	oidcLoginMethod     = "oidc"
+)
+
+// Metrics represents the metrics recorded by the handlers.
+type Metrics struct {
+	requests *metrics.Counter
+	duration *metrics.Histogram
+	canceled *metrics.Counter
+	logins   *metrics.Counter
+}
+
+// NewMetrics registers the metrics of the handlers
+// and returns a new *Metrics recording them.
+func NewMetrics(registry *metrics.Registry) *Metrics {
+	return &Metrics{
+		requests: registry.NewCounter(
+			"kera_http_requests_total",
+			"HTTP requests by route pattern and status.",
+			"route", "status",
+		),
+		duration: registry.NewHistogram(
+			"kera_http_request_duration_seconds",
+			"Latency of HTTP requests by route pattern and status.",
+			metrics.DefaultBuckets, "route", "status",
+		),
+		canceled: registry.NewCounter(
+			"kera_http_requests_canceled_total",
+			"HTTP requests failed by their context by route pattern and reason: client or deadline.",
+			"route", "reason",
+		),
+		logins: registry.NewCounter(
+			"kera_logins_total",
+			"Logins by method and result: success, failure or error.",
+			"method", "result",
+		),
+	}
+}
+
+func (m *Metrics) observeRequest(route string, status int, latency time.Duration) {
+	code := strconv.Itoa(status)
+	m.requests.Inc(route, code)
+	m.duration.Observe(latency.Seconds(), route, code)
+}
+
+func (m *Metrics) observeCanceled(route, reason string) {
+	m.canceled.Inc(route, reason)
+}
+
+// countLogins counts the logins of the method by their result,
+// told by the status of the response:
+// a failure is a client error, like invalid credentials,
+// an error is a server error.
+func (m *Metrics) countLogins(method string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		recorder := &statusRecorder{ResponseWriter: w}
+		next(recorder, r)
+
+		switch {
+		case recorder.status >= http.StatusInternalServerError:
+			m.logins.Inc(method, "error")
+		case recorder.status >= http.StatusBadRequest:
+			m.logins.Inc(method, "failure")
+		default:
+			m.logins.Inc(method, "success")
+		}
+	}
+}
@@ -6,13 +6,23 @@ import (
 	"time"
 
 	"github.com/zvxte/kera/hash/sha256"
-	"github.com/zvxte/kera/model/date"
 	"github.com/zvxte/kera/model/session"
 	"github.com/zvxte/kera/store/sessionstore"
+	"github.com/zvxte/kera/tracing"
 )
 
-func SessionMiddleware(next http.Handler, store sessionstore.Store) http.Handler {
+// SessionMiddleware authenticates the request by its session ID.
+// Sessions past half of their idle window are renewed,
+// and the session ID cookie is re-issued with the new expiration time.
+// The authentication is traced with its own span, ended before
+// the next handler is called.
+func SessionMiddleware(
+	next http.Handler, store sessionstore.Store, lifetime session.Lifetime,
+) http.Handler {
 	f := func(w http.ResponseWriter, r *http.Request) response {
+		spanCtx, span := tracing.Start(r.Context(), "SessionMiddleware")
+		defer span.End()
+
 		sessionID := r.Header.Get(sessionIDHeaderName)
 		if sessionID == "" {
 			return unauthorizedResponse
@@ -25,23 +35,51 @@ func SessionMiddleware(next http.Handler, store sessionstore.Store) http.Handler
 
 		hashedSessionID := session.HashedID(sha256.Hash(sessionID))
 
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-
 		session, err := store.Get(
-			ctx, sessionstore.HashedIDColumn, hashedSessionID,
+			spanCtx, sessionstore.HashedIDColumn, hashedSessionID,
 		)
 		if err != nil {
+			logError(r, err)
 			return internalServerErrorResponse
 		}
 
-		if session == nil || session.ExpirationDate.Before(date.Now()) {
+		now := time.Now().UTC()
+
+		if session == nil || session.Expired(lifetime, now) {
 			unsetSessionIDCookie(w)
 			return unauthorizedResponse
 		}
 
-		ctx = context.WithValue(r.Context(), userIDContextKey, session.UserID)
+		switch {
+		case session.NeedsRenewal(lifetime, now):
+			session.Renew(lifetime, now)
+
+			err = store.Renew(
+				spanCtx, hashedSessionID,
+				session.ExpirationTime, session.LastSeenTime,
+			)
+			if err != nil {
+				logError(r, err)
+				return internalServerErrorResponse
+			}
+
+			setSessionIDCookie(w, sessionID, session.ExpirationTime)
+
+		case session.LastSeenStale(now):
+			err = store.Update(
+				spanCtx, hashedSessionID, sessionstore.LastSeenTimeColumn, now,
+			)
+			if err != nil {
+				logError(r, err)
+				return internalServerErrorResponse
+			}
+		}
+
+		ctx := context.WithValue(r.Context(), userIDContextKey, session.UserID)
+		ctx = context.WithValue(ctx, hashedSessionIDContextKey, hashedSessionID)
+		ctx = withUserID(ctx, session.UserID)
 		r = r.WithContext(ctx)
+		span.End()
 		next.ServeHTTP(w, r)
 
 		return nil
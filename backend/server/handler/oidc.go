@@ -0,0 +1,307 @@
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/zvxte/kera/model/identity"
+	"github.com/zvxte/kera/model/session"
+	"github.com/zvxte/kera/model/user"
+	"github.com/zvxte/kera/oidc"
+	"github.com/zvxte/kera/store/identitystore"
+	"github.com/zvxte/kera/store/sessionstore"
+	"github.com/zvxte/kera/store/userstore"
+)
+
+const (
+	oidcFlowCookieName = "oidc_flow"
+	oidcFlowCookiePath = "/auth/oidc/"
+	oidcFlowLifetime   = 10 * time.Minute
+
+	// provisionAttempts represents the number of generated usernames tried
+	// when none of the usernames derived from the claims is available.
+	provisionAttempts = 5
+)
+
+// NewOIDCMux returns a mux handling login with an external OpenID provider.
+// A user is found by the identity linked to the ID token's issuer and subject.
+// An identity is linked to an existing user automatically only if both
+// the provider and the application consider the same email verified.
+// A new user is created for an unknown identity only if the registration mode
+// is open or approval, in the latter case the user is pending.
+// After a successful login the user agent is redirected to postLoginURL.
+func NewOIDCMux(
+	provider *oidc.Provider,
+	userStore userstore.Store,
+	sessionStore sessionstore.Store,
+	identityStore identitystore.Store,
+	sessionLifetime session.Lifetime,
+	registrationMode RegistrationMode,
+	postLoginURL string,
+	metrics *Metrics,
+) *http.ServeMux {
+	h := &oidcHandler{
+		provider:         provider,
+		userStore:        userStore,
+		sessionStore:     sessionStore,
+		identityStore:    identityStore,
+		sessionLifetime:  sessionLifetime,
+		registrationMode: registrationMode,
+		postLoginURL:     postLoginURL,
+	}
+
+	m := http.NewServeMux()
+	m.HandleFunc("GET /login", makeHandlerFunc(h.Login))
+	m.HandleFunc("GET /callback", metrics.countLogins(
+		oidcLoginMethod, makeHandlerFunc(h.Callback),
+	))
+	return m
+}
+
+type oidcHandler struct {
+	provider         *oidc.Provider
+	userStore        userstore.Store
+	sessionStore     sessionstore.Store
+	identityStore    identitystore.Store
+	sessionLifetime  session.Lifetime
+	registrationMode RegistrationMode
+	postLoginURL     string
+}
+
+// Login redirects the user agent to the provider's authorization endpoint.
+// The state, nonce and PKCE code verifier are kept in a short-lived cookie
+// until the provider redirects back to the callback.
+func (h *oidcHandler) Login(w http.ResponseWriter, r *http.Request) response {
+	state, err := oidc.NewRandom()
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	nonce, err := oidc.NewRandom()
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	codeVerifier, err := oidc.NewRandom()
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	setOIDCFlowCookie(w, state+"."+nonce+"."+codeVerifier)
+
+	return redirectResponse{
+		statusCode: http.StatusFound,
+		location: h.provider.AuthCodeURL(
+			state, nonce, oidc.CodeChallenge(codeVerifier),
+		),
+	}
+}
+
+func (h *oidcHandler) Callback(w http.ResponseWriter, r *http.Request) response {
+	cookie, err := r.Cookie(oidcFlowCookieName)
+	if err != nil {
+		return badRequestResponse
+	}
+	unsetOIDCFlowCookie(w)
+
+	flow := strings.Split(cookie.Value, ".")
+	if len(flow) != 3 {
+		return badRequestResponse
+	}
+	state, nonce, codeVerifier := flow[0], flow[1], flow[2]
+
+	query := r.URL.Query()
+	if query.Get("error") != "" {
+		return externalAuthFailedResponse
+	}
+
+	if subtle.ConstantTimeCompare([]byte(query.Get("state")), []byte(state)) != 1 {
+		return badRequestResponse
+	}
+
+	code := query.Get("code")
+	if code == "" {
+		return badRequestResponse
+	}
+
+	ctx := r.Context()
+
+	claims, err := h.provider.Exchange(ctx, code, codeVerifier, nonce)
+	if err != nil {
+		logError(r, err)
+		return externalAuthFailedResponse
+	}
+
+	user, resp := h.findUser(ctx, r, claims)
+	if resp != nil {
+		return resp
+	}
+
+	if resp := inactiveUserResponse(user); resp != nil {
+		return resp
+	}
+
+	err = startSession(
+		ctx, w, r, h.sessionStore, h.userStore, h.sessionLifetime, user.ID,
+	)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	return redirectResponse{
+		statusCode: http.StatusSeeOther,
+		location:   h.postLoginURL,
+	}
+}
+
+// findUser returns the user the claims belong to,
+// linking or provisioning it if needed.
+func (h *oidcHandler) findUser(
+	ctx context.Context, r *http.Request, claims *oidc.Claims,
+) (*user.User, response) {
+	identity, err := h.identityStore.Get(ctx, claims.Issuer, claims.Subject)
+	if err != nil {
+		logError(r, err)
+		return nil, internalServerErrorResponse
+	}
+
+	if identity != nil {
+		user, err := h.userStore.Get(ctx, userstore.IDColumn, identity.UserID)
+		if err != nil {
+			logError(r, err)
+			return nil, internalServerErrorResponse
+		}
+		if user == nil {
+			return nil, identityNotLinkedResponse
+		}
+		return user, nil
+	}
+
+	email := verifiedEmail(claims)
+
+	if email != "" {
+		user, err := h.userStore.Get(ctx, userstore.EmailColumn, email)
+		if err != nil {
+			logError(r, err)
+			return nil, internalServerErrorResponse
+		}
+		if user != nil {
+			if err := h.link(ctx, user, claims); err != nil {
+				logError(r, err)
+				return nil, internalServerErrorResponse
+			}
+			return user, nil
+		}
+	}
+
+	if h.registrationMode != RegistrationOpen &&
+		h.registrationMode != RegistrationApproval {
+		return nil, identityNotLinkedResponse
+	}
+
+	user, err := h.provision(ctx, r, claims, email)
+	if err != nil {
+		logError(r, err)
+		return nil, internalServerErrorResponse
+	}
+	return user, nil
+}
+
+func (h *oidcHandler) link(
+	ctx context.Context, user *user.User, claims *oidc.Claims,
+) error {
+	identity, err := identity.New(
+		user.ID, claims.Issuer, claims.Subject, verifiedEmail(claims),
+	)
+	if err != nil {
+		return err
+	}
+	return h.identityStore.Create(ctx, identity)
+}
+
+// provision creates a new user for the claims.
+// The username is derived from the preferred username or the email,
+// and a random one is generated if those are invalid or taken.
+// The email is stored as verified, since the provider has verified it.
+func (h *oidcHandler) provision(
+	ctx context.Context, r *http.Request, claims *oidc.Claims, email string,
+) (*user.User, error) {
+	var candidates []string
+	for _, hint := range []string{claims.PreferredUsername, claims.Email} {
+		if username := user.UsernameFromHint(hint); username != "" {
+			candidates = append(candidates, username)
+		}
+	}
+	for range provisionAttempts {
+		candidates = append(
+			candidates, fmt.Sprintf("user_%08d", rand.IntN(100_000_000)),
+		)
+	}
+
+	var newUser *user.User
+	for _, username := range candidates {
+		u, err := user.NewExternal(username, claims.Name)
+		if err != nil {
+			return nil, err
+		}
+		if h.registrationMode == RegistrationApproval {
+			u.Status = user.StatusPending
+		}
+
+		err = h.userStore.Create(ctx, u)
+		if err == userstore.ErrUsernameAlreadyTaken {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		newUser = u
+		break
+	}
+	if newUser == nil {
+		return nil, fmt.Errorf("failed to provision user: no username available")
+	}
+
+	if err := h.link(ctx, newUser, claims); err != nil {
+		// The user is useless without the identity, it can't log in
+		if deleteErr := h.userStore.Delete(ctx, newUser.ID); deleteErr != nil {
+			logError(r, deleteErr)
+		}
+		return nil, err
+	}
+
+	if email != "" {
+		err := h.userStore.Update(ctx, newUser.ID, userstore.EmailColumn, email)
+		if err == nil {
+			_, err = h.userStore.VerifyEmail(ctx, newUser.ID, email)
+		}
+		if err != nil {
+			// Not fatal, the user can set the email later
+			logError(r, err)
+		} else {
+			newUser.Email = email
+			newUser.EmailVerified = true
+		}
+	}
+
+	return newUser, nil
+}
+
+// verifiedEmail returns the email of the claims
+// if the provider has verified it and it's valid, else an empty string.
+func verifiedEmail(claims *oidc.Claims) string {
+	if !claims.EmailVerified || user.ValidateEmail(claims.Email) != nil {
+		return ""
+	}
+	return claims.Email
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/zvxte/kera/model/challenge"
+	"github.com/zvxte/kera/model/session"
+	"github.com/zvxte/kera/model/uuid"
+	"github.com/zvxte/kera/store/challengestore"
+	"github.com/zvxte/kera/store/passkeystore"
+	"github.com/zvxte/kera/store/sessionstore"
+	"github.com/zvxte/kera/store/userstore"
+	"github.com/zvxte/kera/webauthn"
+)
+
+// NewPasskeyMux returns a mux handling login with a passkey.
+// Passkeys are discoverable, so the user is not asked for a username.
+// Registering passkeys is handled by the mux returned from [NewMeMux].
+func NewPasskeyMux(
+	relyingParty *webauthn.RelyingParty,
+	userStore userstore.Store,
+	sessionStore sessionstore.Store,
+	passkeyStore passkeystore.Store,
+	challengeStore challengestore.Store,
+	sessionLifetime session.Lifetime,
+	metrics *Metrics,
+) *http.ServeMux {
+	h := &passkeyHandler{
+		relyingParty:    relyingParty,
+		userStore:       userStore,
+		sessionStore:    sessionStore,
+		passkeyStore:    passkeyStore,
+		challengeStore:  challengeStore,
+		sessionLifetime: sessionLifetime,
+	}
+
+	m := http.NewServeMux()
+	m.HandleFunc("POST /login/options", makeHandlerFunc(h.LoginOptions))
+	m.HandleFunc("POST /login", metrics.countLogins(
+		passkeyLoginMethod, makeHandlerFunc(h.Login),
+	))
+	return m
+}
+
+type passkeyHandler struct {
+	relyingParty    *webauthn.RelyingParty
+	userStore       userstore.Store
+	sessionStore    sessionstore.Store
+	passkeyStore    passkeystore.Store
+	challengeStore  challengestore.Store
+	sessionLifetime session.Lifetime
+}
+
+// LoginOptions starts an authentication ceremony and returns its options
+// to be passed to navigator.credentials.get().
+func (h *passkeyHandler) LoginOptions(w http.ResponseWriter, r *http.Request) response {
+	challengeID, challenge, err := challenge.New(
+		challenge.Authentication, uuid.UUID{}, h.relyingParty.Timeout(),
+	)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	ctx := r.Context()
+
+	err = h.challengeStore.Create(ctx, challenge)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	return newJsonResponse(http.StatusOK, struct {
+		PublicKey webauthn.RequestOptions `json:"publicKey"`
+	}{h.relyingParty.RequestOptions(challengeID, nil)})
+}
+
+// Login finishes an authentication ceremony and creates a new session.
+func (h *passkeyHandler) Login(w http.ResponseWriter, r *http.Request) response {
+	if r.Header.Get("Content-Type") != "application/json" {
+		return unsupportedMediaTypeResponse
+	}
+
+	var in webauthn.AssertionResponse
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		return badRequestResponse
+	}
+
+	challengeID, err := webauthn.Challenge(in.Response.ClientDataJSON)
+	if err != nil {
+		return badRequestResponse
+	}
+
+	ctx := r.Context()
+
+	challenge, err := h.challengeStore.Consume(
+		ctx, webauthn.HashChallenge(challengeID), challenge.Authentication,
+	)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+	if challenge == nil {
+		return invalidCredentialsResponse
+	}
+
+	passkey, err := h.passkeyStore.Get(ctx, in.RawID)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+	if passkey == nil {
+		return invalidCredentialsResponse
+	}
+
+	if len(in.Response.UserHandle) != 0 &&
+		!bytes.Equal(in.Response.UserHandle, passkey.UserID[:]) {
+		return invalidCredentialsResponse
+	}
+
+	signCount, err := h.relyingParty.VerifyAuthentication(
+		challengeID, &in, passkey.PublicKey, passkey.SignCount,
+	)
+	if err == webauthn.ErrSignCountRegressed {
+		requestLogger(r).Warn(
+			"possibly cloned authenticator", "passkey_id", passkey.ID.String(),
+		)
+		return invalidCredentialsResponse
+	}
+	if err != nil {
+		return invalidCredentialsResponse
+	}
+
+	updated, err := h.passkeyStore.UpdateSignCount(
+		ctx, passkey.ID, signCount, time.Now().UTC(),
+	)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+	if !updated {
+		requestLogger(r).Warn(
+			"possibly cloned authenticator", "passkey_id", passkey.ID.String(),
+		)
+		return invalidCredentialsResponse
+	}
+
+	user, err := h.userStore.Get(ctx, userstore.IDColumn, passkey.UserID)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+	if user == nil {
+		return invalidCredentialsResponse
+	}
+
+	if resp := inactiveUserResponse(user); resp != nil {
+		return resp
+	}
+
+	err = startSession(
+		ctx, w, r, h.sessionStore, h.userStore, h.sessionLifetime, user.ID,
+	)
+	if err != nil {
+		logError(r, err)
+		return internalServerErrorResponse
+	}
+
+	return noContentResponse{}
+}
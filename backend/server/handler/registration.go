@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+)
+
+// RegistrationMode represents who can register a new user.
+type RegistrationMode uint8
+
+const (
+	// RegistrationOpen allows anyone to register.
+	RegistrationOpen RegistrationMode = iota
+
+	// RegistrationClosed disallows registration.
+	RegistrationClosed
+
+	// RegistrationInvite allows registration only with a valid invite code.
+	RegistrationInvite
+
+	// RegistrationApproval allows anyone to register,
+	// but the user can't log in until an admin approves it.
+	RegistrationApproval
+)
+
+var ErrInvalidRegistrationMode = errors.New("registration mode is invalid")
+
+// ParseRegistrationMode returns the registration mode of the name,
+// one of "open", "closed", "invite" or "approval".
+func ParseRegistrationMode(name string) (RegistrationMode, error) {
+	switch name {
+	case "open":
+		return RegistrationOpen, nil
+	case "closed":
+		return RegistrationClosed, nil
+	case "invite":
+		return RegistrationInvite, nil
+	case "approval":
+		return RegistrationApproval, nil
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrInvalidRegistrationMode, name)
+	}
+}
+
+func (m RegistrationMode) String() string {
+	switch m {
+	case RegistrationOpen:
+		return "open"
+	case RegistrationClosed:
+		return "closed"
+	case RegistrationInvite:
+		return "invite"
+	case RegistrationApproval:
+		return "approval"
+	default:
+		return ""
+	}
+}
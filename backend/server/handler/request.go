@@ -0,0 +1,271 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zvxte/kera/logging"
+	"github.com/zvxte/kera/model/uuid"
+	"github.com/zvxte/kera/store"
+	"github.com/zvxte/kera/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	requestIDHeaderName = "X-Request-ID"
+	maxRequestIDLen     = 64
+)
+
+// requestInfo represents what's known about a request
+// once it's handled by the nested muxes and middlewares.
+// It's shared by pointer, so the inner handlers can report back
+// to the logging middleware.
+type requestInfo struct {
+	path   string
+	route  string
+	userID uuid.UUID
+	// canceled is the reason the request failed by its context,
+	// if it did: canceledByClient or canceledByDeadline.
+	canceled string
+}
+
+// Reasons of requests failed by their context.
+const (
+	canceledByClient   = "client"
+	canceledByDeadline = "deadline"
+)
+
+// statusRecorder records the status code written to the ResponseWriter.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusRecorder) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusRecorder) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying ResponseWriter.
+func (w *statusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// RequestMiddleware assigns a request ID to the request, returned in
+// the X-Request-ID header, and carries a logger with the request ID
+// and the method in the request's context.
+// A valid X-Request-ID of the client is kept, so requests can be traced
+// across proxies.
+// Once the request is handled, it's logged with its route pattern,
+// user ID, status and latency, and counted in the metrics.
+// Requests failed by their context, canceled by the client or past
+// their deadline, are logged and counted with the reason.
+// The path is not logged, it may contain secret tokens.
+//
+// Every request is traced with a server span, continuing the trace
+// of the client's traceparent header, which is named after the route
+// once it's known. Its trace ID is logged along with the request.
+func RequestMiddleware(
+	next http.Handler, logger *slog.Logger, metrics *Metrics,
+	tracer trace.Tracer,
+) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		ctx := tracing.Propagator().Extract(
+			r.Context(), propagation.HeaderCarrier(r.Header),
+		)
+		ctx, span := tracer.Start(
+			ctx, r.Method, trace.WithSpanKind(trace.SpanKindServer),
+		)
+		defer span.End()
+
+		requestID := r.Header.Get(requestIDHeaderName)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+		w.Header().Set(requestIDHeaderName, requestID)
+
+		requestLogger := logger.With("request_id", requestID, "method", r.Method)
+		if spanContext := span.SpanContext(); spanContext.IsSampled() {
+			requestLogger = requestLogger.With(
+				"trace_id", spanContext.TraceID().String(),
+			)
+		}
+		info := &requestInfo{path: r.URL.Path}
+
+		ctx = logging.WithLogger(ctx, requestLogger)
+		ctx = context.WithValue(ctx, requestInfoContextKey, info)
+		recorder := &statusRecorder{ResponseWriter: w}
+		r = r.WithContext(ctx)
+		next.ServeHTTP(recorder, r)
+
+		status := recorder.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		// The pattern of the outermost mux, if no nested handler reported one
+		if info.route == "" {
+			info.route = r.Pattern
+		}
+		latency := time.Since(start)
+		metrics.observeRequest(info.route, status, latency)
+		if info.canceled != "" {
+			metrics.observeCanceled(info.route, info.canceled)
+		}
+
+		if info.route != "" {
+			span.SetName(info.route)
+		}
+		span.SetAttributes(
+			attribute.String("http.request.method", r.Method),
+			attribute.String("http.route", info.route),
+			attribute.Int("http.response.status_code", status),
+		)
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+
+		attrs := []any{
+			"route", info.route,
+			"status", status,
+			"latency", latency,
+		}
+		if info.userID != (uuid.UUID{}) {
+			attrs = append(attrs, "user_id", info.userID.String())
+		}
+		if info.canceled != "" {
+			attrs = append(attrs, "canceled", info.canceled)
+		}
+		requestLogger.Info("request handled", attrs...)
+	})
+}
+
+// newRequestID returns a UUIDv7, so request IDs sort by time.
+// If it can't be generated, the current time is used instead.
+func newRequestID() string {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return id.String()
+}
+
+// validRequestID returns true if the request ID of a client
+// is short and made of letters, digits, '-', '_' and '.' only.
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLen {
+		return false
+	}
+	for _, c := range requestID {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case c == '-' || c == '_' || c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// setRoute reports the route pattern of the request, like
+// "GET /habits/{id}", to the logging middleware.
+// The pattern of a nested mux lacks the prefix stripped by
+// http.StripPrefix, it's restored from the original path.
+func setRoute(r *http.Request) {
+	info, ok := r.Context().Value(requestInfoContextKey).(*requestInfo)
+	if !ok || r.Pattern == "" {
+		return
+	}
+
+	prefix := strings.TrimSuffix(info.path, r.URL.Path)
+	method, path, found := strings.Cut(r.Pattern, " ")
+	if !found {
+		info.route = prefix + r.Pattern
+		return
+	}
+	info.route = method + " " + prefix + path
+}
+
+// withUserID returns a copy of the context whose logger carries
+// the user ID, which is also reported to the logging middleware.
+func withUserID(ctx context.Context, userID uuid.UUID) context.Context {
+	if info, ok := ctx.Value(requestInfoContextKey).(*requestInfo); ok {
+		info.userID = userID
+	}
+	logger := logging.FromContext(ctx).With("user_id", userID.String())
+	return logging.WithLogger(ctx, logger)
+}
+
+// requestLogger returns the logger of the request.
+func requestLogger(r *http.Request) *slog.Logger {
+	return logging.FromContext(r.Context())
+}
+
+// logError logs the error that failed the request.
+// Errors caused by the request's context are not server errors,
+// they're logged at a lower level.
+func logError(r *http.Request, err error) {
+	switch contextCause(r, err) {
+	case context.Canceled:
+		requestLogger(r).Info("request canceled", "error", err)
+	case context.DeadlineExceeded:
+		requestLogger(r).Warn("request timed out", "error", err)
+	default:
+		requestLogger(r).Error("request failed", "error", err)
+	}
+}
+
+// contextCause returns the error of the request's context
+// if it caused the error, else nil.
+// Errors of background work, whose context is detached from the request,
+// are not caused by it even once the request is done.
+func contextCause(r *http.Request, err error) error {
+	switch ctxErr := r.Context().Err(); {
+	case errors.Is(ctxErr, context.Canceled) &&
+		(errors.Is(err, context.Canceled) || errors.Is(err, store.ErrCanceled)):
+		return ctxErr
+	case errors.Is(ctxErr, context.DeadlineExceeded) &&
+		(errors.Is(err, context.DeadlineExceeded) || errors.Is(err, store.ErrDeadlineExceeded)):
+		return ctxErr
+	}
+	return nil
+}
+
+// canceledResponse returns the response of a request whose context
+// is done, and reports the reason to the logging middleware,
+// or nil if it's not done.
+func canceledResponse(r *http.Request) response {
+	var reason string
+	var resp response
+	switch err := r.Context().Err(); {
+	case errors.Is(err, context.Canceled):
+		reason, resp = canceledByClient, requestCanceledResponse
+	case errors.Is(err, context.DeadlineExceeded):
+		reason, resp = canceledByDeadline, requestTimeoutResponse
+	default:
+		return nil
+	}
+
+	if info, ok := r.Context().Value(requestInfoContextKey).(*requestInfo); ok {
+		info.canceled = reason
+	}
+	return resp
+}
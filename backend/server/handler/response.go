@@ -18,6 +18,10 @@ var (
 		http.StatusBadRequest,
 		newHandlerError(http.StatusBadRequest, ErrBadRequest.Error()),
 	)
+	notFoundResponse = newJsonResponse(
+		http.StatusNotFound,
+		newHandlerError(http.StatusNotFound, ErrNotFound.Error()),
+	)
 	unsupportedMediaTypeResponse = newJsonResponse(
 		http.StatusUnsupportedMediaType,
 		newHandlerError(http.StatusUnsupportedMediaType, ErrUnsupportedMediaType.Error()),
@@ -30,8 +34,97 @@ var (
 		http.StatusConflict,
 		newHandlerError(http.StatusConflict, ErrUsernameAlreadyTaken.Error()),
 	)
+	emailAlreadyTakenResponse = newJsonResponse(
+		http.StatusConflict,
+		newHandlerError(http.StatusConflict, ErrEmailAlreadyTaken.Error()),
+	)
+	invalidTokenResponse = newJsonResponse(
+		http.StatusBadRequest,
+		newHandlerError(http.StatusBadRequest, ErrInvalidToken.Error()),
+	)
+	externalAuthFailedResponse = newJsonResponse(
+		http.StatusBadRequest,
+		newHandlerError(http.StatusBadRequest, ErrExternalAuthFailed.Error()),
+	)
+	identityNotLinkedResponse = newJsonResponse(
+		http.StatusForbidden,
+		newHandlerError(http.StatusForbidden, ErrIdentityNotLinked.Error()),
+	)
+	invalidPasskeyResponse = newJsonResponse(
+		http.StatusBadRequest,
+		newHandlerError(http.StatusBadRequest, ErrInvalidPasskey.Error()),
+	)
+	passkeyAlreadyRegisteredResponse = newJsonResponse(
+		http.StatusConflict,
+		newHandlerError(http.StatusConflict, ErrPasskeyAlreadyRegistered.Error()),
+	)
+	lastSignInMethodResponse = newJsonResponse(
+		http.StatusConflict,
+		newHandlerError(http.StatusConflict, ErrLastSignInMethod.Error()),
+	)
+	forbiddenResponse = newJsonResponse(
+		http.StatusForbidden,
+		newHandlerError(http.StatusForbidden, ErrForbidden.Error()),
+	)
+	registrationClosedResponse = newJsonResponse(
+		http.StatusForbidden,
+		newHandlerError(http.StatusForbidden, ErrRegistrationClosed.Error()),
+	)
+	invalidInviteResponse = newJsonResponse(
+		http.StatusBadRequest,
+		newHandlerError(http.StatusBadRequest, ErrInvalidInvite.Error()),
+	)
+	accountPendingResponse = newJsonResponse(
+		http.StatusForbidden,
+		newHandlerError(http.StatusForbidden, ErrAccountPending.Error()),
+	)
+	userNotPendingResponse = newJsonResponse(
+		http.StatusConflict,
+		newHandlerError(http.StatusConflict, ErrUserNotPending.Error()),
+	)
+	accountDisabledResponse = newJsonResponse(
+		http.StatusForbidden,
+		newHandlerError(http.StatusForbidden, ErrAccountDisabled.Error()),
+	)
+	userAlreadyDisabledResponse = newJsonResponse(
+		http.StatusConflict,
+		newHandlerError(http.StatusConflict, ErrUserAlreadyDisabled.Error()),
+	)
+	userNotDisabledResponse = newJsonResponse(
+		http.StatusConflict,
+		newHandlerError(http.StatusConflict, ErrUserNotDisabled.Error()),
+	)
+	selfAdminActionResponse = newJsonResponse(
+		http.StatusConflict,
+		newHandlerError(http.StatusConflict, ErrSelfAdminAction.Error()),
+	)
+	requestTooLargeResponse = newJsonResponse(
+		http.StatusRequestEntityTooLarge,
+		newHandlerError(http.StatusRequestEntityTooLarge, ErrRequestTooLarge.Error()),
+	)
+	importConflictResponse = newJsonResponse(
+		http.StatusConflict,
+		newHandlerError(http.StatusConflict, ErrImportConflict.Error()),
+	)
+	tooManyFeedsResponse = newJsonResponse(
+		http.StatusConflict,
+		newHandlerError(http.StatusConflict, ErrTooManyFeeds.Error()),
+	)
+	requestCanceledResponse = newJsonResponse(
+		statusClientClosedRequest,
+		newHandlerError(statusClientClosedRequest, ErrRequestCanceled.Error()),
+	)
+	requestTimeoutResponse = newJsonResponse(
+		http.StatusServiceUnavailable,
+		newHandlerError(http.StatusServiceUnavailable, ErrRequestTimeout.Error()),
+	)
 )
 
+// statusClientClosedRequest is the status of requests canceled
+// by the client, it's never read by the client but tells them apart
+// in logs and metrics.
+const statusClientClosedRequest = 499
+
 type response interface {
 	write(w http.ResponseWriter)
 }
@@ -71,3 +164,19 @@ type createdResponse struct{}
 func (r createdResponse) write(w http.ResponseWriter) {
 	w.WriteHeader(http.StatusCreated)
 }
+
+type acceptedResponse struct{}
+
+func (r acceptedResponse) write(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusAccepted)
+}
+
+type redirectResponse struct {
+	statusCode int
+	location   string
+}
+
+func (r redirectResponse) write(w http.ResponseWriter) {
+	w.Header().Set("Location", r.location)
+	w.WriteHeader(r.statusCode)
+}
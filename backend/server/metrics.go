@@ -0,0 +1,136 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/zvxte/kera/job"
+	"github.com/zvxte/kera/metrics"
+	"github.com/zvxte/kera/store/sessionstore"
+)
+
+// sessionsTimeout bounds counting the active sessions on a scrape.
+const sessionsTimeout = 5 * time.Second
+
+// registerMetrics registers the metrics of the database pool,
+// the active sessions and the background jobs,
+// collected when the metrics are scraped.
+func registerMetrics(
+	registry *metrics.Registry,
+	db *sql.DB,
+	sessionStore sessionstore.Store,
+	jobRunner *job.Runner,
+	logger *slog.Logger,
+) {
+	registerDBMetrics(registry, db)
+
+	sessionMetric := func(users bool) func() []metrics.Sample {
+		return func() []metrics.Sample {
+			ctx, cancel := context.WithTimeout(context.Background(), sessionsTimeout)
+			defer cancel()
+
+			sessions, activeUsers, err := sessionStore.CountActive(ctx, time.Now().UTC())
+			if err != nil {
+				logger.Error("failed to collect session metrics", "error", err)
+				return nil
+			}
+			if users {
+				return []metrics.Sample{{Value: float64(activeUsers)}}
+			}
+			return []metrics.Sample{{Value: float64(sessions)}}
+		}
+	}
+	registry.NewGaugeFunc(
+		"kera_sessions_active", "Sessions that have not expired.", nil,
+		sessionMetric(false),
+	)
+	registry.NewGaugeFunc(
+		"kera_users_with_active_sessions", "Users with a session that has not expired.", nil,
+		sessionMetric(true),
+	)
+
+	jobMetric := func(value func(job.Stats) float64) func() []metrics.Sample {
+		return func() []metrics.Sample {
+			stats := jobRunner.Stats()
+			samples := make([]metrics.Sample, len(stats))
+			for i, s := range stats {
+				samples[i] = metrics.Sample{
+					LabelValues: []string{s.Name}, Value: value(s),
+				}
+			}
+			return samples
+		}
+	}
+	jobLabels := []string{"job"}
+	registry.NewCounterFunc(
+		"kera_job_runs_total", "Runs of background jobs.", jobLabels,
+		jobMetric(func(s job.Stats) float64 { return float64(s.Runs) }),
+	)
+	registry.NewCounterFunc(
+		"kera_job_failures_total", "Failed runs of background jobs.", jobLabels,
+		jobMetric(func(s job.Stats) float64 { return float64(s.Failures) }),
+	)
+	registry.NewCounterFunc(
+		"kera_job_affected_records_total", "Records processed by background jobs.", jobLabels,
+		jobMetric(func(s job.Stats) float64 { return float64(s.Affected) }),
+	)
+	registry.NewGaugeFunc(
+		"kera_job_last_duration_seconds", "Duration of the last run of background jobs.", jobLabels,
+		jobMetric(func(s job.Stats) float64 { return s.LastDuration.Seconds() }),
+	)
+	registry.NewGaugeFunc(
+		"kera_job_last_run_timestamp_seconds", "Start time of the last run of background jobs.", jobLabels,
+		jobMetric(func(s job.Stats) float64 {
+			if s.LastRunTime.IsZero() {
+				return 0
+			}
+			return float64(s.LastRunTime.UnixMilli()) / 1000
+		}),
+	)
+}
+
+// registerDBMetrics registers the stats of the database pool,
+// see [sql.DBStats].
+func registerDBMetrics(registry *metrics.Registry, db *sql.DB) {
+	stat := func(value func(sql.DBStats) float64) func() []metrics.Sample {
+		return func() []metrics.Sample {
+			return []metrics.Sample{{Value: value(db.Stats())}}
+		}
+	}
+
+	registry.NewGaugeFunc(
+		"kera_db_max_open_connections", "Maximum open connections to the database, 0 is unlimited.", nil,
+		stat(func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) }),
+	)
+	registry.NewGaugeFunc(
+		"kera_db_connections", "Open connections to the database by state.", []string{"state"},
+		func() []metrics.Sample {
+			s := db.Stats()
+			return []metrics.Sample{
+				{LabelValues: []string{"idle"}, Value: float64(s.Idle)},
+				{LabelValues: []string{"in_use"}, Value: float64(s.InUse)},
+			}
+		},
+	)
+	registry.NewCounterFunc(
+		"kera_db_wait_count_total", "Connections waited for.", nil,
+		stat(func(s sql.DBStats) float64 { return float64(s.WaitCount) }),
+	)
+	registry.NewCounterFunc(
+		"kera_db_wait_duration_seconds_total", "Time spent waiting for connections.", nil,
+		stat(func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() }),
+	)
+	registry.NewCounterFunc(
+		"kera_db_closed_connections_total", "Connections closed by the pool by reason.", []string{"reason"},
+		func() []metrics.Sample {
+			s := db.Stats()
+			return []metrics.Sample{
+				{LabelValues: []string{"max_idle"}, Value: float64(s.MaxIdleClosed)},
+				{LabelValues: []string{"max_idle_time"}, Value: float64(s.MaxIdleTimeClosed)},
+				{LabelValues: []string{"max_lifetime"}, Value: float64(s.MaxLifetimeClosed)},
+			}
+		},
+	)
+}
@@ -2,76 +2,729 @@ package server
 
 import (
 	"context"
+	"database/sql"
+	"encoding/base64"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
+	"github.com/zvxte/kera/config"
 	"github.com/zvxte/kera/database"
+	"github.com/zvxte/kera/export"
+	"github.com/zvxte/kera/hash/argon2id"
+	"github.com/zvxte/kera/importer"
+	"github.com/zvxte/kera/job"
+	"github.com/zvxte/kera/logging"
+	"github.com/zvxte/kera/mail"
+	"github.com/zvxte/kera/metrics"
+	"github.com/zvxte/kera/model/session"
+	"github.com/zvxte/kera/model/user"
+	"github.com/zvxte/kera/oidc"
 	"github.com/zvxte/kera/server/handler"
+	"github.com/zvxte/kera/store/auditstore"
+	"github.com/zvxte/kera/store/challengestore"
+	"github.com/zvxte/kera/store/feedstore"
 	"github.com/zvxte/kera/store/habitstore"
+	"github.com/zvxte/kera/store/identitystore"
+	"github.com/zvxte/kera/store/invitestore"
+	"github.com/zvxte/kera/store/passkeystore"
 	"github.com/zvxte/kera/store/sessionstore"
+	"github.com/zvxte/kera/store/tokenstore"
 	"github.com/zvxte/kera/store/userstore"
+	"github.com/zvxte/kera/tracing"
+	"github.com/zvxte/kera/webauthn"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 type Server struct {
-	mux *http.ServeMux
+	httpServer      *http.Server
+	metricsServer   *http.Server
+	metricsAddress  string
+	shutdownTimeout time.Duration
+	shutdownDelay   time.Duration
+	health          *handler.Health
+	jobRunner       *job.Runner
+	background      *handler.Background
+	db              *sql.DB
+	tracerProvider  tracerProvider
+	logger          *slog.Logger
 }
 
-func NewServer() (*Server, error) {
-	logger := log.Default()
+// NewServer returns a new *Server configured by the validated config.
+// It connects to the database and migrates it.
+func NewServer(cfg *config.Config) (_ *Server, err error) {
+	logger, err := logging.New(os.Stderr, cfg.Log.Level, cfg.Log.Format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Server: %w", err)
+	}
+	slog.SetDefault(logger)
+
+	sessionLifetime, err := session.NewLifetime(
+		cfg.Session.AbsoluteTimeout, cfg.Session.IdleTimeout,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Server: %w", err)
+	}
+
+	passwordHasher, err := NewPasswordHasher(cfg.Password, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Server: %w", err)
+	}
+
+	passwordPolicy, err := NewPasswordPolicy(cfg.Password, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Server: %w", err)
+	}
 
-	dataSourceName := os.Getenv("DSN")
-	if dataSourceName == "" {
-		return nil, errors.New("failed to create Server: DSN is not set")
+	registrationMode, err := handler.ParseRegistrationMode(cfg.Registration.Mode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Server: %w", err)
 	}
 
+	tracerProvider, err := newTracerProvider(cfg.Tracing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Server: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tracerProvider.Shutdown(context.Background())
+		}
+	}()
+	tracing.SetGlobal(tracerProvider)
+	tracer := tracerProvider.Tracer(tracing.TracerName)
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	sqlDatabase, err := database.NewSqlDatabase(
-		ctx, database.PostgresDriverName, dataSourceName,
+		ctx, database.PostgresDriverName, cfg.Database.DSN.Value(),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Server: %w", err)
 	}
-	err = sqlDatabase.Setup(ctx)
+	defer func() {
+		if err != nil {
+			sqlDatabase.DB.Close()
+		}
+	}()
+
+	// Migrating may wait for another replica holding the migration lock,
+	// so it's not limited by the timeout of the other startup steps
+	migrationCtx, cancelMigration := context.WithTimeout(
+		context.Background(), cfg.Database.MigrationTimeout,
+	)
+	defer cancelMigration()
+
+	err = sqlDatabase.Setup(migrationCtx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Server: %w", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	sqlUserStore, err := userstore.NewSql(sqlDatabase.DB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Server: %w", err)
+	}
+	userStore := userstore.NewTraced(sqlUserStore, tracer)
+
+	sqlSessionStore, err := sessionstore.NewSql(sqlDatabase.DB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Server: %w", err)
+	}
+	sessionStore := sessionstore.NewTraced(sqlSessionStore, tracer)
+
+	sqlHabitStore, err := habitstore.NewSql(sqlDatabase.DB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Server: %w", err)
+	}
+	habitStore := habitstore.NewTraced(sqlHabitStore, tracer)
+
+	sqlTokenStore, err := tokenstore.NewSql(sqlDatabase.DB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Server: %w", err)
+	}
+	tokenStore := tokenstore.NewTraced(sqlTokenStore, tracer)
+
+	sqlIdentityStore, err := identitystore.NewSql(sqlDatabase.DB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Server: %w", err)
+	}
+	identityStore := identitystore.NewTraced(sqlIdentityStore, tracer)
+
+	sqlPasskeyStore, err := passkeystore.NewSql(sqlDatabase.DB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Server: %w", err)
+	}
+	passkeyStore := passkeystore.NewTraced(sqlPasskeyStore, tracer)
+
+	sqlChallengeStore, err := challengestore.NewSql(sqlDatabase.DB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Server: %w", err)
+	}
+	challengeStore := challengestore.NewTraced(sqlChallengeStore, tracer)
+
+	sqlInviteStore, err := invitestore.NewSql(sqlDatabase.DB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Server: %w", err)
+	}
+	inviteStore := invitestore.NewTraced(sqlInviteStore, tracer)
+
+	sqlAuditStore, err := auditstore.NewSql(sqlDatabase.DB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Server: %w", err)
+	}
+	auditStore := auditstore.NewTraced(sqlAuditStore, tracer)
+
+	sqlFeedStore, err := feedstore.NewSql(sqlDatabase.DB)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Server: %w", err)
 	}
+	feedStore := feedstore.NewTraced(sqlFeedStore, tracer)
 
-	userStore, err := userstore.NewSql(sqlDatabase.DB)
+	err = promoteAdmins(ctx, userStore, cfg.Registration.AdminUsernames, logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Server: %w", err)
 	}
 
-	sessionStore, err := sessionstore.NewSql(sqlDatabase.DB)
+	relyingParty, err := newRelyingParty(cfg.WebAuthn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Server: %w", err)
 	}
 
-	habitStore, err := habitstore.NewSql(sqlDatabase.DB)
+	mailer, err := newMailer(cfg.Mail)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Server: %w", err)
 	}
 
-	authMux := handler.NewAuthMux(userStore, sessionStore, logger)
-	meMux := handler.NewMeMux(userStore, sessionStore, logger)
-	habitsMux := handler.NewHabitsMux(habitStore, userStore, logger)
+	jobRunner, err := newJobRunner(
+		cfg.Jobs, sessionStore, tokenStore, challengeStore, inviteStore, logger,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Server: %w", err)
+	}
+
+	httpServer := newHTTPServer(cfg.Server, logger)
+
+	background := handler.NewBackground()
+
+	registry := metrics.NewRegistry()
+	handlerMetrics := handler.NewMetrics(registry)
+	registerMetrics(registry, sqlDatabase.DB, sessionStore, jobRunner, logger)
+
+	authMux := handler.NewAuthMux(
+		userStore, sessionStore, tokenStore, mailer,
+		sessionLifetime, registrationMode, passwordPolicy, passwordHasher,
+		background, handlerMetrics,
+	)
+	meMux := handler.NewMeMux(
+		userStore, sessionStore, tokenStore, identityStore,
+		passkeyStore, challengeStore, feedStore, relyingParty, mailer,
+		export.New(userStore, habitStore, sessionStore),
+		importer.New(userStore, habitStore),
+		passwordPolicy, passwordHasher, background,
+	)
+	habitsMux := handler.NewHabitsMux(
+		habitStore, userStore, cfg.Habits.HistoryPatchWindow,
+	)
+	adminMux := handler.NewAdminMux(
+		userStore, sessionStore, tokenStore, inviteStore, auditStore, mailer,
+	)
+	calendarMux := handler.NewCalendarMux(feedStore, userStore, habitStore)
+
+	health := newHealth(sqlDatabase, jobRunner, logger)
+	healthMux := handler.NewHealthMux(health)
 
 	mux := http.NewServeMux()
+	mux.Handle("GET /healthz", healthMux)
+	mux.Handle("GET /readyz", healthMux)
 	mux.Handle("/auth/", http.StripPrefix("/auth", authMux))
+
+	oidcCtx, cancelOIDC := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelOIDC()
+
+	oidcProvider, err := newOIDCProvider(oidcCtx, cfg.OIDC)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Server: %w", err)
+	}
+	if oidcProvider != nil {
+		// Provisioning is a registration, it follows the registration mode
+		oidcRegistrationMode := registrationMode
+		if !cfg.OIDC.AutoProvision {
+			oidcRegistrationMode = handler.RegistrationClosed
+		}
+
+		oidcMux := handler.NewOIDCMux(
+			oidcProvider, userStore, sessionStore, identityStore,
+			sessionLifetime, oidcRegistrationMode, cfg.OIDC.PostLoginURL, handlerMetrics,
+		)
+		mux.Handle("/auth/oidc/", http.StripPrefix("/auth/oidc", oidcMux))
+	}
+
+	if relyingParty != nil {
+		passkeyMux := handler.NewPasskeyMux(
+			relyingParty, userStore, sessionStore, passkeyStore,
+			challengeStore, sessionLifetime, handlerMetrics,
+		)
+		mux.Handle("/auth/passkey/", http.StripPrefix("/auth/passkey", passkeyMux))
+	}
+
 	mux.Handle("/me/", handler.SessionMiddleware(
-		http.StripPrefix("/me", meMux), sessionStore),
+		http.StripPrefix("/me", meMux), sessionStore, sessionLifetime),
 	)
 	mux.Handle("/habits/", handler.SessionMiddleware(
-		http.StripPrefix("/habits", habitsMux), sessionStore),
+		http.StripPrefix("/habits", habitsMux), sessionStore, sessionLifetime),
 	)
-	return &Server{mux: mux}, nil
+	mux.Handle("/calendar/", http.StripPrefix("/calendar", calendarMux))
+	mux.Handle("/admin/", handler.SessionMiddleware(
+		handler.AdminMiddleware(http.StripPrefix("/admin", adminMux), userStore),
+		sessionStore, sessionLifetime),
+	)
+
+	// A separate metrics server keeps /metrics off the public address
+	var metricsServer *http.Server
+	if cfg.Metrics.Enabled {
+		if cfg.Metrics.Address == "" {
+			mux.Handle("GET /metrics", registry)
+		} else {
+			metricsMux := http.NewServeMux()
+			metricsMux.Handle("GET /metrics", registry)
+			metricsServer = newHTTPServer(cfg.Server, logger)
+			metricsServer.Handler = metricsMux
+		}
+	}
+
+	httpServer.Handler = handler.RequestMiddleware(
+		handler.DeadlineMiddleware(mux, newDeadlines(cfg.Server)),
+		logger, handlerMetrics, tracer,
+	)
+
+	return &Server{
+		httpServer:      httpServer,
+		metricsServer:   metricsServer,
+		metricsAddress:  cfg.Metrics.Address,
+		shutdownTimeout: cfg.Server.ShutdownTimeout,
+		shutdownDelay:   cfg.Server.ShutdownDelay,
+		health:          health,
+		jobRunner:       jobRunner,
+		background:      background,
+		db:              sqlDatabase.DB,
+		tracerProvider:  tracerProvider,
+		logger:          logger,
+	}, nil
 }
 
-func (server *Server) Run(address string) {
-	http.ListenAndServe(address, server.mux)
+// Run serves HTTP on the address, and the metrics on their own address
+// if it's configured, and runs the background jobs
+// until SIGINT or SIGTERM is received, or the server fails.
+// It then shuts down gracefully: fails readiness for the shutdown delay,
+// stops accepting connections, drains the requests in progress, stops the jobs, waits for
+// the background work of handlers and closes the database.
+// A second signal during the shutdown kills the process.
+// It returns an error if the server fails or the shutdown
+// does not finish within the shutdown timeout.
+func (server *Server) Run(address string) error {
+	ctx, stop := signal.NotifyContext(
+		context.Background(), os.Interrupt, syscall.SIGTERM,
+	)
+	defer stop()
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return errors.Join(
+			fmt.Errorf("failed to listen: %w", err), server.shutdown(),
+		)
+	}
+
+	var metricsListener net.Listener
+	if server.metricsServer != nil {
+		metricsListener, err = net.Listen("tcp", server.metricsAddress)
+		if err != nil {
+			listener.Close()
+			return errors.Join(
+				fmt.Errorf("failed to listen for metrics: %w", err), server.shutdown(),
+			)
+		}
+	}
+
+	server.jobRunner.Start()
+
+	serveErr := make(chan error, 2)
+	go func() {
+		serveErr <- server.httpServer.Serve(listener)
+	}()
+	server.logger.Info("listening", "address", listener.Addr().String())
+
+	if metricsListener != nil {
+		go func() {
+			serveErr <- server.metricsServer.Serve(metricsListener)
+		}()
+		server.logger.Info(
+			"serving metrics", "address", metricsListener.Addr().String(),
+		)
+	}
+
+	var errs []error
+	select {
+	case err := <-serveErr:
+		errs = append(errs, fmt.Errorf("failed to serve: %w", err))
+	case <-ctx.Done():
+		// Restores the default behavior, so a second signal kills the process
+		stop()
+		server.logger.Info("shutting down")
+
+		// Lets load balancers notice the failing readiness
+		// before the server stops accepting connections
+		server.health.ShutDown()
+		time.Sleep(server.shutdownDelay)
+	}
+
+	errs = append(errs, server.shutdown())
+	return errors.Join(errs...)
+}
+
+// shutdown releases everything [Server.Run] uses,
+// it waits for up to the shutdown timeout for the requests
+// and the background work in progress.
+func (server *Server) shutdown() error {
+	server.health.ShutDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), server.shutdownTimeout)
+	defer cancel()
+
+	var errs []error
+
+	if err := server.httpServer.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to drain requests: %w", err))
+	}
+
+	if server.metricsServer != nil {
+		if err := server.metricsServer.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop serving metrics: %w", err))
+		}
+	}
+
+	server.jobRunner.Stop()
+
+	if err := server.background.Wait(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to wait for background work: %w", err))
+	}
+
+	if err := server.db.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to close database: %w", err))
+	}
+
+	// Flushes the spans of the drained requests and background work
+	if err := server.tracerProvider.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to flush traces: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// newDeadlines returns the deadlines of requests, the long request timeout
+// applies to routes waiting on slow queries or other services.
+func newDeadlines(cfg config.Server) *handler.Deadlines {
+	deadlines := handler.NewDeadlines(cfg.RequestTimeout)
+	for _, pattern := range []string{
+		"GET /me/export",
+		"POST /me/import",
+		"GET /calendar/{file}",
+		"GET /auth/oidc/callback",
+		"POST /admin/users/{id}/password-reset",
+	} {
+		deadlines.Set(pattern, cfg.LongRequestTimeout)
+	}
+	return deadlines
+}
+
+// tracerProvider represents the trace.TracerProvider of the server,
+// flushed on shutdown.
+type tracerProvider interface {
+	trace.TracerProvider
+	Shutdown(ctx context.Context) error
+}
+
+// noopTracerProvider represents a tracerProvider recording nothing.
+type noopTracerProvider struct {
+	noop.TracerProvider
+}
+
+func (noopTracerProvider) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// newTracerProvider returns the tracerProvider exporting spans
+// to the configured endpoint, or recording nothing if tracing is disabled.
+func newTracerProvider(cfg config.Tracing) (tracerProvider, error) {
+	if !cfg.Enabled {
+		return noopTracerProvider{noop.NewTracerProvider()}, nil
+	}
+
+	exporter, err := tracing.NewExporter(context.Background(), cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := tracing.NewProvider(exporter, cfg.ServiceName, cfg.SampleRatio)
+	if err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
+
+// newHealth returns the *handler.Health of the server, ready
+// if the database is reachable and migrated, and the jobs are running.
+// Database errors are only logged, they may reveal its address.
+func newHealth(
+	sqlDatabase *database.SqlDatabase, jobRunner *job.Runner, logger *slog.Logger,
+) *handler.Health {
+	health := handler.NewHealth()
+
+	health.AddCheck("database", func(ctx context.Context) error {
+		if err := sqlDatabase.Ping(ctx); err != nil {
+			logger.Error("readiness check failed", "check", "database", "error", err)
+			return errors.New("database is unreachable")
+		}
+		return nil
+	})
+
+	health.AddCheck("migrations", func(ctx context.Context) error {
+		err := sqlDatabase.CheckMigrations(ctx)
+		if err != nil && !errors.Is(err, database.ErrMigrationVersionMismatch) {
+			logger.Error("readiness check failed", "check", "migrations", "error", err)
+			return errors.New("migration history is unavailable")
+		}
+		return err
+	})
+
+	health.AddCheck("jobs", func(ctx context.Context) error {
+		return jobRunner.Check(time.Now())
+	})
+
+	return health
+}
+
+// newHTTPServer returns an *http.Server without a handler.
+// The write timeout bounds the slowest responses, like exports.
+func newHTTPServer(cfg config.Server, logger *slog.Logger) *http.Server {
+	return &http.Server{
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+		ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelWarn),
+	}
+}
+
+// NewPasswordPolicy returns a *user.PasswordPolicy rejecting passwords
+// that contain the username, with the configured minimum entropy
+// and breached list.
+// The breached list is a SHA-1 hash file or a directory of range files,
+// see [user.LoadBreachedPasswords].
+func NewPasswordPolicy(cfg config.Password, logger *slog.Logger) (*user.PasswordPolicy, error) {
+	policy := *user.DefaultPasswordPolicy
+	policy.MinEntropy = cfg.MinEntropy
+
+	if cfg.BreachedList != "" {
+		breached, err := user.LoadBreachedPasswords(cfg.BreachedList)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("loaded breached passwords", "count", breached.Len())
+		policy.Breached = breached
+	}
+
+	return &policy, nil
+}
+
+// NewPasswordHasher returns an argon2id hasher with the params
+// from [newPasswordParams] and the configured peppers,
+// a comma separated list of KEY_ID:BASE64_KEY.
+// The first pepper is used for new hashes, the others only verify
+// existing ones until they are rehashed on the next login.
+// Imported bcrypt hashes are upgraded the same way, see [user.LegacyHasher].
+func NewPasswordHasher(cfg config.Password, logger *slog.Logger) (user.PasswordHasher, error) {
+	params, err := newPasswordParams(cfg.Argon2, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	var peppers []argon2id.Pepper
+	for _, value := range strings.Split(cfg.Peppers.Value(), ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		keyID, encodedKey, ok := strings.Cut(value, ":")
+		if !ok {
+			return nil, errors.New("password.peppers is invalid: missing key ID")
+		}
+
+		key, err := base64.StdEncoding.DecodeString(encodedKey)
+		if err != nil {
+			return nil, fmt.Errorf("password.peppers is invalid: %w", err)
+		}
+
+		peppers = append(peppers, argon2id.Pepper{KeyID: keyID, Key: key})
+	}
+
+	hasher, err := argon2id.NewHasher(params, peppers...)
+	if err != nil {
+		return nil, fmt.Errorf("password.peppers is invalid: %w", err)
+	}
+	return user.NewLegacyHasher(hasher), nil
+}
+
+// newPasswordParams returns the configured argon2id params.
+// If the target time is set, the iterations are calibrated instead,
+// so hashing takes about that long on the host.
+func newPasswordParams(cfg config.Argon2, logger *slog.Logger) (*argon2id.Params, error) {
+	if cfg.TargetTime > 0 {
+		params, elapsed, err := argon2id.Calibrate(
+			cfg.TargetTime, cfg.Memory, cfg.Parallelism,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calibrate argon2id: %w", err)
+		}
+		logger.Info(
+			"calibrated argon2id", "params", params.String(), "elapsed", elapsed,
+		)
+		return params, nil
+	}
+
+	params, err := argon2id.NewParams(
+		cfg.Memory, cfg.Iterations, cfg.Parallelism, 32, 16,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("argon2id params are invalid: %w", err)
+	}
+	return params, nil
+}
+
+// newJobRunner returns a *job.Runner with all background jobs.
+func newJobRunner(
+	cfg config.Jobs,
+	sessionStore sessionstore.Store,
+	tokenStore tokenstore.Store,
+	challengeStore challengestore.Store,
+	inviteStore invitestore.Store,
+	logger *slog.Logger,
+) (*job.Runner, error) {
+	return job.NewRunner(
+		cfg.Interval, cfg.Jitter, logger,
+		job.NewPurge("session_purge", sessionStore, job.DefaultBatchSize),
+		job.NewPurge("token_purge", tokenStore, job.DefaultBatchSize),
+		job.NewPurge("challenge_purge", challengeStore, job.DefaultBatchSize),
+		job.NewPurge("invite_purge", inviteStore, job.DefaultBatchSize),
+	)
+}
+
+// promoteAdmins grants the admin role to the users with the provided
+// usernames and activates them, so the first admin
+// of an instance can be set up. Unknown usernames are only logged.
+func promoteAdmins(
+	ctx context.Context, userStore userstore.Store,
+	usernames []string, logger *slog.Logger,
+) error {
+	for _, username := range usernames {
+		u, err := userStore.Get(ctx, userstore.UsernameColumn, username)
+		if err != nil {
+			return err
+		}
+		if u == nil {
+			logger.Warn("admin does not exist", "username", username)
+			continue
+		}
+
+		if !u.IsAdmin() {
+			err := userStore.Update(ctx, u.ID, userstore.RoleColumn, user.RoleAdmin)
+			if err != nil {
+				return err
+			}
+			logger.Info("promoted user to admin", "username", username)
+		}
+
+		if !u.IsActive() {
+			err := userStore.Update(ctx, u.ID, userstore.StatusColumn, user.StatusActive)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// newMailer returns the configured mail.Mailer:
+//   - "log" writes messages to standard error, for development,
+//   - "file" appends messages to the file,
+//   - "smtp" delivers messages to the SMTP server,
+//     optionally authenticated with a username and a password.
+func newMailer(cfg config.Mail) (mail.Mailer, error) {
+	switch cfg.Mailer {
+	case "log":
+		return mail.NewWriter(os.Stderr, cfg.From), nil
+
+	case "file":
+		return mail.NewFile(cfg.File, cfg.From)
+
+	case "smtp":
+		return mail.NewSMTP(
+			cfg.SMTP.Host, cfg.SMTP.Port,
+			cfg.SMTP.Username, cfg.SMTP.Password.Value(), cfg.From,
+		), nil
+
+	default:
+		return nil, fmt.Errorf("mail.mailer is invalid: %q", cfg.Mailer)
+	}
+}
+
+// newOIDCProvider returns the configured *oidc.Provider.
+// It returns nil if the issuer URL is not set, login with
+// an external provider is disabled then.
+func newOIDCProvider(ctx context.Context, cfg config.OIDC) (*oidc.Provider, error) {
+	if cfg.IssuerURL == "" {
+		return nil, nil
+	}
+
+	return oidc.NewProvider(ctx, oidc.Config{
+		IssuerURL:    cfg.IssuerURL,
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret.Value(),
+		RedirectURL:  cfg.RedirectURL,
+		Scopes:       cfg.Scopes,
+	})
+}
+
+// newRelyingParty returns the configured *webauthn.RelyingParty,
+// allowing "https://" + the RP ID if no origins are set.
+// It returns nil if the RP ID is not set, passkeys are disabled then.
+func newRelyingParty(cfg config.WebAuthn) (*webauthn.RelyingParty, error) {
+	if cfg.RPID == "" {
+		return nil, nil
+	}
+
+	origins := cfg.Origins
+	if len(origins) == 0 {
+		origins = []string{"https://" + cfg.RPID}
+	}
+
+	return webauthn.NewRelyingParty(webauthn.Config{
+		RPID:                    cfg.RPID,
+		RPName:                  cfg.RPName,
+		Origins:                 origins,
+		RequireUserVerification: cfg.RequireUserVerification,
+	})
 }
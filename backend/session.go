@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/zvxte/kera/job"
+	"github.com/zvxte/kera/model/session"
+	"github.com/zvxte/kera/model/uuid"
+	"github.com/zvxte/kera/store/sessionstore"
+	"github.com/zvxte/kera/store/userstore"
+)
+
+const sessionUsage = `Usage:
+  kera session list [flags] USERNAME         lists the sessions of the user
+  kera session revoke [flags] USERNAME [ID]  ends the session of the ID, or all sessions of the user
+  kera session purge [flags]                 deletes the expired sessions of all users`
+
+// runSession manages the sessions of the database read from database.dsn of the config,
+// the same way as the sessions endpoints and the session purge job do.
+func runSession(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("session: command is required\n%s", sessionUsage)
+	}
+	command, args := args[0], args[1:]
+
+	// The range of the number of arguments of the command
+	var minArgs, maxArgs int
+	switch command {
+	case "list":
+		minArgs, maxArgs = 1, 1
+	case "revoke":
+		minArgs, maxArgs = 1, 2
+	case "purge":
+	case "-h", "-help", "--help":
+		fmt.Fprintln(os.Stderr, sessionUsage)
+		return flag.ErrHelp
+	default:
+		return fmt.Errorf("session: unknown command %q\n%s", command, sessionUsage)
+	}
+
+	flags := flag.NewFlagSet("session "+command, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), sessionUsage)
+		flags.PrintDefaults()
+	}
+	cfg, err := loadConfig(flags, args)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case flags.NArg() < minArgs:
+		return fmt.Errorf("session %s: USERNAME is required\n%s", command, sessionUsage)
+	case flags.NArg() > maxArgs:
+		return fmt.Errorf("session %s: unexpected arguments %q\n%s", command, flags.Args()[maxArgs:], sessionUsage)
+	}
+
+	// The public ID is parsed before connecting
+	var publicID uuid.UUID
+	if command == "revoke" && flags.NArg() == 2 {
+		publicID, err = uuid.Parse(flags.Arg(1))
+		if err != nil {
+			return fmt.Errorf("session revoke: invalid ID %q", flags.Arg(1))
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	sqlDatabase, err := openDatabase(ctx, cfg, "session "+command)
+	if err != nil {
+		return err
+	}
+	defer sqlDatabase.DB.Close()
+
+	sessionStore, err := sessionstore.NewSql(sqlDatabase.DB)
+	if err != nil {
+		return fmt.Errorf("session %s: %w", command, err)
+	}
+
+	if command == "purge" {
+		result, err := job.NewPurge("session_purge", sessionStore, job.DefaultBatchSize).Run(ctx)
+		fmt.Printf("purged %d expired sessions\n", result.Affected)
+		if err != nil {
+			return fmt.Errorf("session purge: %w", err)
+		}
+		return nil
+	}
+
+	userStore, err := userstore.NewSql(sqlDatabase.DB)
+	if err != nil {
+		return fmt.Errorf("session %s: %w", command, err)
+	}
+
+	user, err := getUser(ctx, userStore, flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("session %s: %w", command, err)
+	}
+
+	switch {
+	case command == "list":
+		sessions, err := sessionStore.GetAll(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("session list: %w", err)
+		}
+		return printSessions(sessions)
+
+	case publicID != (uuid.UUID{}):
+		deleted, err := sessionStore.DeleteByPublicID(ctx, publicID, user.ID)
+		if err != nil {
+			return fmt.Errorf("session revoke: %w", err)
+		}
+		if !deleted {
+			return fmt.Errorf("session revoke: session %s of %q not found", publicID, user.Username)
+		}
+		fmt.Printf("revoked session %s of %q\n", publicID, user.Username)
+
+	default:
+		err = sessionStore.Delete(ctx, sessionstore.UserIDColumn, user.ID)
+		if err != nil {
+			return fmt.Errorf("session revoke: %w", err)
+		}
+		fmt.Printf("revoked all sessions of %q\n", user.Username)
+	}
+
+	return nil
+}
+
+func printSessions(sessions []*session.Session) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tCREATED\tLAST SEEN\tEXPIRES\tIP ADDRESS\tUSER AGENT")
+	for _, session := range sessions {
+		fmt.Fprintf(
+			w, "%s\t%s\t%s\t%s\t%s\t%s\n", session.PublicID,
+			session.CreationTime.UTC().Format(time.RFC3339),
+			session.LastSeenTime.UTC().Format(time.RFC3339),
+			session.ExpirationTime.UTC().Format(time.RFC3339),
+			session.IPAddress, session.UserAgent,
+		)
+	}
+	return w.Flush()
+}
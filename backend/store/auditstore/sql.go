@@ -0,0 +1,128 @@
+package auditstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/zvxte/kera/model/audit"
+	"github.com/zvxte/kera/model/uuid"
+	"github.com/zvxte/kera/store"
+)
+
+// Sql represents an relational database implementation
+// of the [auditstore.Store] interface.
+// It uses an [*sql.DB] pool to interact with the database.
+type Sql struct {
+	db *sql.DB
+}
+
+func NewSql(db *sql.DB) (Sql, error) {
+	if db == nil {
+		return Sql{}, store.ErrNilDB
+	}
+	return Sql{db}, nil
+}
+
+func (s Sql) Create(ctx context.Context, entry *audit.Entry) error {
+	const query = `
+	INSERT INTO admin_audit_log(
+		id, admin_id, action, target_id, details, creation_time
+	)
+	VALUES ($1, $2, $3, $4, $5, $6);
+	`
+
+	var adminID any
+	if entry.AdminID != (uuid.UUID{}) {
+		adminID = entry.AdminID
+	}
+
+	_, err := s.db.ExecContext(
+		ctx, query,
+		entry.ID, adminID, entry.Action, entry.TargetID,
+		entry.Details, entry.CreationTime,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create audit entry: %w", err)
+	}
+
+	return nil
+}
+
+func (s Sql) GetAll(
+	ctx context.Context, limit, offset uint,
+) ([]*audit.Entry, error) {
+	const query = `
+	SELECT id, admin_id, action, target_id, details, creation_time
+	FROM admin_audit_log
+	ORDER BY creation_time DESC, id DESC
+	LIMIT $1 OFFSET $2;
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*audit.Entry
+
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get all audit entries: %w", err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get all audit entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// scanner is implemented by both [*sql.Row] and [*sql.Rows].
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanEntry scans a single admin_audit_log row into an *audit.Entry.
+// It returns [sql.ErrNoRows] unwrapped, so the caller can check for it.
+func scanEntry(row scanner) (*audit.Entry, error) {
+	var rawID, rawTargetID, details string
+	var rawAdminID sql.NullString
+	var action audit.Action
+	var creationTime time.Time
+
+	err := row.Scan(
+		&rawID, &rawAdminID, &action, &rawTargetID, &details, &creationTime,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		return nil, err
+	}
+
+	var adminID uuid.UUID
+	if rawAdminID.Valid {
+		adminID, err = uuid.Parse(rawAdminID.String)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	targetID, err := uuid.Parse(rawTargetID)
+	if err != nil {
+		return nil, err
+	}
+
+	return audit.Load(
+		id, adminID, action, targetID, details, creationTime,
+	), nil
+}
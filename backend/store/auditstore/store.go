@@ -0,0 +1,18 @@
+package auditstore
+
+import (
+	"context"
+
+	"github.com/zvxte/kera/model/audit"
+)
+
+type Store interface {
+	// Create inserts a new entry into the store.
+	// It returns an error if there is a connection issue.
+	Create(ctx context.Context, entry *audit.Entry) error
+
+	// GetAll returns up to limit entries, newest first,
+	// skipping the first offset ones, or a nil slice.
+	// It fails if there is a connection issue.
+	GetAll(ctx context.Context, limit, offset uint) ([]*audit.Entry, error)
+}
@@ -0,0 +1,43 @@
+package auditstore
+
+import (
+	"context"
+
+	"github.com/zvxte/kera/model/audit"
+	"github.com/zvxte/kera/store"
+	"github.com/zvxte/kera/tracing"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Traced represents a [Store] recording a span of every call,
+// named after the package and the method, like auditstore.Create.
+// Errors of calls whose context is done are mapped by [store.MapContextError].
+type Traced struct {
+	store  Store
+	tracer trace.Tracer
+}
+
+// NewTraced returns the store recording spans with the tracer.
+func NewTraced(store Store, tracer trace.Tracer) Traced {
+	return Traced{store, tracer}
+}
+
+func (s Traced) Create(ctx context.Context, entry *audit.Entry) error {
+	ctx, span := s.tracer.Start(ctx, "auditstore.Create")
+	defer span.End()
+
+	err := s.store.Create(ctx, entry)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return err
+}
+
+func (s Traced) GetAll(ctx context.Context, limit, offset uint) ([]*audit.Entry, error) {
+	ctx, span := s.tracer.Start(ctx, "auditstore.GetAll")
+	defer span.End()
+
+	result, err := s.store.GetAll(ctx, limit, offset)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return result, err
+}
@@ -0,0 +1,113 @@
+package challengestore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/zvxte/kera/model/challenge"
+	"github.com/zvxte/kera/model/uuid"
+	"github.com/zvxte/kera/store"
+)
+
+// Sql represents an relational database implementation
+// of the [challengestore.Store] interface.
+// It uses an [*sql.DB] pool to interact with the database.
+type Sql struct {
+	db *sql.DB
+}
+
+func NewSql(db *sql.DB) (Sql, error) {
+	if db == nil {
+		return Sql{}, store.ErrNilDB
+	}
+	return Sql{db}, nil
+}
+
+func (s Sql) Create(ctx context.Context, challenge *challenge.Challenge) error {
+	const query = `
+	INSERT INTO webauthn_challenges(id, ceremony, user_id, expiration_time)
+	VALUES ($1, $2, $3, $4);
+	`
+
+	// Authentication challenges are not bound to a user
+	var userID any
+	if challenge.UserID != (uuid.UUID{}) {
+		userID = challenge.UserID
+	}
+
+	_, err := s.db.ExecContext(
+		ctx, query,
+		challenge.HashedID[:], challenge.Ceremony, userID,
+		challenge.ExpirationTime,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create challenge: %w", err)
+	}
+
+	return nil
+}
+
+func (s Sql) Consume(
+	ctx context.Context,
+	hashedID challenge.HashedID, ceremony challenge.Ceremony,
+) (*challenge.Challenge, error) {
+	const query = `
+	DELETE FROM webauthn_challenges
+	WHERE id = $1 AND ceremony = $2
+	RETURNING user_id, expiration_time;
+	`
+
+	var rawUserID sql.NullString
+	var expirationTime time.Time
+
+	row := s.db.QueryRowContext(ctx, query, hashedID[:], ceremony)
+	err := row.Scan(&rawUserID, &expirationTime)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume challenge: %w", err)
+	}
+
+	// An expired challenge is deleted as well, it's useless anyway
+	if !time.Now().Before(expirationTime) {
+		return nil, nil
+	}
+
+	var userID uuid.UUID
+	if rawUserID.Valid {
+		userID, err = uuid.Parse(rawUserID.String)
+		if err != nil {
+			return nil, fmt.Errorf("failed to consume challenge: %w", err)
+		}
+	}
+
+	return challenge.Load(hashedID, ceremony, userID, expirationTime), nil
+}
+
+func (s Sql) DeleteExpired(
+	ctx context.Context, now time.Time, limit uint,
+) (uint, error) {
+	const query = `
+	DELETE FROM webauthn_challenges
+	WHERE id IN (
+		SELECT id FROM webauthn_challenges
+		WHERE expiration_time <= $1
+		LIMIT $2
+	);
+	`
+
+	result, err := s.db.ExecContext(ctx, query, now, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired challenges: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired challenges: %w", err)
+	}
+
+	return uint(deleted), nil
+}
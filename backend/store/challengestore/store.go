@@ -0,0 +1,29 @@
+package challengestore
+
+import (
+	"context"
+	"time"
+
+	"github.com/zvxte/kera/model/challenge"
+)
+
+type Store interface {
+	// Create inserts a new challenge into the store.
+	// It returns an error if there is a connection issue.
+	Create(ctx context.Context, challenge *challenge.Challenge) error
+
+	// Consume deletes a challenge with the provided hashed ID and ceremony
+	// from the store and returns it, or nil if there is no such challenge
+	// or it has expired. A challenge can be consumed only once.
+	// It fails if there is a connection issue.
+	Consume(
+		ctx context.Context,
+		hashedID challenge.HashedID, ceremony challenge.Ceremony,
+	) (*challenge.Challenge, error)
+
+	// DeleteExpired deletes up to limit challenges
+	// with expiration time at or before the provided time.
+	// It returns the number of deleted challenges.
+	// It fails if there is a connection issue.
+	DeleteExpired(ctx context.Context, now time.Time, limit uint) (uint, error)
+}
@@ -0,0 +1,57 @@
+package challengestore
+
+import (
+	"context"
+	"time"
+
+	"github.com/zvxte/kera/model/challenge"
+	"github.com/zvxte/kera/store"
+	"github.com/zvxte/kera/tracing"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Traced represents a [Store] recording a span of every call,
+// named after the package and the method, like challengestore.Create.
+// Errors of calls whose context is done are mapped by [store.MapContextError].
+type Traced struct {
+	store  Store
+	tracer trace.Tracer
+}
+
+// NewTraced returns the store recording spans with the tracer.
+func NewTraced(store Store, tracer trace.Tracer) Traced {
+	return Traced{store, tracer}
+}
+
+func (s Traced) Create(ctx context.Context, challenge *challenge.Challenge) error {
+	ctx, span := s.tracer.Start(ctx, "challengestore.Create")
+	defer span.End()
+
+	err := s.store.Create(ctx, challenge)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return err
+}
+
+func (s Traced) Consume(
+	ctx context.Context,
+	hashedID challenge.HashedID, ceremony challenge.Ceremony,
+) (*challenge.Challenge, error) {
+	ctx, span := s.tracer.Start(ctx, "challengestore.Consume")
+	defer span.End()
+
+	result, err := s.store.Consume(ctx, hashedID, ceremony)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return result, err
+}
+
+func (s Traced) DeleteExpired(ctx context.Context, now time.Time, limit uint) (uint, error) {
+	ctx, span := s.tracer.Start(ctx, "challengestore.DeleteExpired")
+	defer span.End()
+
+	result, err := s.store.DeleteExpired(ctx, now, limit)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return result, err
+}
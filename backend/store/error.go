@@ -1,9 +1,41 @@
 package store
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrNilDB              = errors.New("function called with nil *sql.DB")
 	ErrInvalidColumn      = errors.New("column is invalid")
 	ErrInvalidColumnValue = errors.New("column value is invalid")
+	ErrCanceled           = errors.New("store call was canceled")
+	ErrDeadlineExceeded   = errors.New("store call exceeded its deadline")
 )
+
+// MapContextError returns the error of a store call wrapped with
+// ErrCanceled or ErrDeadlineExceeded if the context of the call is done,
+// along with the context error.
+// Drivers don't always wrap the context error, e.g. a query interrupted
+// by a canceled context may fail with a closed connection,
+// so it can't be told apart from a database failure otherwise.
+// Other errors are returned as they are.
+func MapContextError(ctx context.Context, err error) error {
+	ctxErr := ctx.Err()
+	if err == nil || ctxErr == nil {
+		return err
+	}
+	if errors.Is(err, ErrCanceled) || errors.Is(err, ErrDeadlineExceeded) {
+		return err
+	}
+
+	sentinel := ErrCanceled
+	if errors.Is(ctxErr, context.DeadlineExceeded) {
+		sentinel = ErrDeadlineExceeded
+	}
+	if errors.Is(err, ctxErr) {
+		return fmt.Errorf("%w: %w", sentinel, err)
+	}
+	return fmt.Errorf("%w: %w: %w", sentinel, ctxErr, err)
+}
@@ -0,0 +1,48 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMapContextError(t *testing.T) {
+	errConnClosed := errors.New("conn closed")
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancel := context.WithDeadline(context.Background(), time.Now())
+	defer cancel()
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		err    error
+		wanted []error
+	}{
+		{"Nil error", canceled, nil, nil},
+		{"Context not done", context.Background(), errConnClosed, []error{errConnClosed}},
+		{"Canceled", canceled, errConnClosed, []error{ErrCanceled, context.Canceled, errConnClosed}},
+		{"Canceled: wrapped context error", canceled, context.Canceled, []error{ErrCanceled, context.Canceled}},
+		{"Deadline exceeded", expired, errConnClosed, []error{ErrDeadlineExceeded, context.DeadlineExceeded, errConnClosed}},
+		{"Already mapped", canceled, MapContextError(canceled, errConnClosed), []error{ErrCanceled, errConnClosed}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := MapContextError(test.ctx, test.err)
+			if test.wanted == nil && err != nil {
+				t.Errorf("MapContextError(%v), error=%v, wanted nil", test.err, err)
+			}
+			for _, wanted := range test.wanted {
+				if !errors.Is(err, wanted) {
+					t.Errorf("MapContextError(%v), error=%v, wanted=%v", test.err, err, wanted)
+				}
+			}
+			if errors.Is(err, ErrCanceled) && errors.Is(err, ErrDeadlineExceeded) {
+				t.Errorf("MapContextError(%v), error=%v, mapped twice", test.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package feedstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/zvxte/kera/model/feed"
+	"github.com/zvxte/kera/model/uuid"
+	"github.com/zvxte/kera/store"
+)
+
+// Sql represents an relational database implementation
+// of the [feedstore.Store] interface.
+// It uses an [*sql.DB] pool to interact with the database.
+type Sql struct {
+	db *sql.DB
+}
+
+func NewSql(db *sql.DB) (Sql, error) {
+	if db == nil {
+		return Sql{}, store.ErrNilDB
+	}
+	return Sql{db}, nil
+}
+
+func (s Sql) Create(ctx context.Context, feed *feed.Feed) error {
+	const query = `
+	INSERT INTO calendar_feeds(
+		id, hashed_token, user_id, completions, creation_time
+	)
+	VALUES ($1, $2, $3, $4, $5);
+	`
+
+	_, err := s.db.ExecContext(
+		ctx, query,
+		feed.ID, feed.HashedToken[:], feed.UserID,
+		feed.Completions, feed.CreationTime,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create calendar feed: %w", err)
+	}
+
+	return nil
+}
+
+func (s Sql) Get(
+	ctx context.Context, hashedToken feed.HashedToken,
+) (*feed.Feed, error) {
+	const query = `
+	SELECT id, hashed_token, user_id, completions, creation_time
+	FROM calendar_feeds
+	WHERE hashed_token = $1;
+	`
+
+	f, err := scanFeed(s.db.QueryRowContext(ctx, query, hashedToken[:]))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get calendar feed: %w", err)
+	}
+
+	return f, nil
+}
+
+func (s Sql) GetAll(ctx context.Context, userID uuid.UUID) ([]*feed.Feed, error) {
+	const query = `
+	SELECT id, hashed_token, user_id, completions, creation_time
+	FROM calendar_feeds
+	WHERE user_id = $1
+	ORDER BY creation_time;
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all calendar feeds: %w", err)
+	}
+	defer rows.Close()
+
+	var feeds []*feed.Feed
+
+	for rows.Next() {
+		f, err := scanFeed(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get all calendar feeds: %w", err)
+		}
+
+		feeds = append(feeds, f)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get all calendar feeds: %w", err)
+	}
+
+	return feeds, nil
+}
+
+func (s Sql) Delete(
+	ctx context.Context, id uuid.UUID, userID uuid.UUID,
+) (bool, error) {
+	const query = `
+	DELETE FROM calendar_feeds
+	WHERE id = $1 AND user_id = $2;
+	`
+
+	result, err := s.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete calendar feed: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete calendar feed: %w", err)
+	}
+
+	return deleted > 0, nil
+}
+
+// scanner is implemented by both [*sql.Row] and [*sql.Rows].
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanFeed scans a single calendar_feeds row into a *feed.Feed.
+// It returns [sql.ErrNoRows] unwrapped, so the caller can check for it.
+func scanFeed(row scanner) (*feed.Feed, error) {
+	var rawID, rawUserID string
+	var rawHashedToken []byte
+	var completions bool
+	var creationTime time.Time
+
+	err := row.Scan(
+		&rawID, &rawHashedToken, &rawUserID, &completions, &creationTime,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := uuid.Parse(rawUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	var hashedToken feed.HashedToken
+	if len(rawHashedToken) != feed.HashedTokenLen {
+		return nil, fmt.Errorf("hashed token length is %d", len(rawHashedToken))
+	}
+	copy(hashedToken[:], rawHashedToken)
+
+	return feed.Load(id, hashedToken, userID, completions, creationTime), nil
+}
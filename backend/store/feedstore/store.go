@@ -0,0 +1,30 @@
+package feedstore
+
+import (
+	"context"
+
+	"github.com/zvxte/kera/model/feed"
+	"github.com/zvxte/kera/model/uuid"
+)
+
+type Store interface {
+	// Create inserts a new calendar feed into the store.
+	// It returns an error if there is a connection issue.
+	Create(ctx context.Context, feed *feed.Feed) error
+
+	// Get returns the calendar feed with the provided hashed token
+	// or nil if there is no such feed.
+	// It fails if there is a connection issue.
+	Get(ctx context.Context, hashedToken feed.HashedToken) (*feed.Feed, error)
+
+	// GetAll returns the user's calendar feeds ordered by creation time
+	// or a nil slice.
+	// It fails if there is a connection issue.
+	GetAll(ctx context.Context, userID uuid.UUID) ([]*feed.Feed, error)
+
+	// Delete deletes a user's calendar feed from the store,
+	// its token stops working.
+	// It returns false if there is no such feed.
+	// It fails if there is a connection issue.
+	Delete(ctx context.Context, id uuid.UUID, userID uuid.UUID) (bool, error)
+}
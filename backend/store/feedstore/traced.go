@@ -0,0 +1,64 @@
+package feedstore
+
+import (
+	"context"
+
+	"github.com/zvxte/kera/model/feed"
+	"github.com/zvxte/kera/model/uuid"
+	"github.com/zvxte/kera/store"
+	"github.com/zvxte/kera/tracing"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Traced represents a [Store] recording a span of every call,
+// named after the package and the method, like feedstore.Create.
+// Errors of calls whose context is done are mapped by [store.MapContextError].
+type Traced struct {
+	store  Store
+	tracer trace.Tracer
+}
+
+// NewTraced returns the store recording spans with the tracer.
+func NewTraced(store Store, tracer trace.Tracer) Traced {
+	return Traced{store, tracer}
+}
+
+func (s Traced) Create(ctx context.Context, feed *feed.Feed) error {
+	ctx, span := s.tracer.Start(ctx, "feedstore.Create")
+	defer span.End()
+
+	err := s.store.Create(ctx, feed)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return err
+}
+
+func (s Traced) Get(ctx context.Context, hashedToken feed.HashedToken) (*feed.Feed, error) {
+	ctx, span := s.tracer.Start(ctx, "feedstore.Get")
+	defer span.End()
+
+	result, err := s.store.Get(ctx, hashedToken)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return result, err
+}
+
+func (s Traced) GetAll(ctx context.Context, userID uuid.UUID) ([]*feed.Feed, error) {
+	ctx, span := s.tracer.Start(ctx, "feedstore.GetAll")
+	defer span.End()
+
+	result, err := s.store.GetAll(ctx, userID)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return result, err
+}
+
+func (s Traced) Delete(ctx context.Context, id uuid.UUID, userID uuid.UUID) (bool, error) {
+	ctx, span := s.tracer.Start(ctx, "feedstore.Delete")
+	defer span.End()
+
+	result, err := s.store.Delete(ctx, id, userID)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return result, err
+}
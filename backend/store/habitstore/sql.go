@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/zvxte/kera/model/date"
@@ -243,3 +244,106 @@ func (s Sql) GetMonthHistory(
 
 	return history, nil
 }
+
+func (s Sql) Import(
+	ctx context.Context, imports []Import, userID uuid.UUID, displayName string,
+) error {
+	const (
+		createQuery = `
+		INSERT INTO habits(
+			id, user_id, status, title, description,
+			tracked_week_days, start_date, end_date
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+		`
+		existsQuery = `
+		SELECT EXISTS (SELECT 1 FROM habits WHERE id = $1 AND user_id = $2);
+		`
+		historyQuery = `
+		INSERT INTO habit_histories(habit_id, date, days)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (habit_id, date)
+		DO UPDATE
+		SET days = habit_histories.days | $3;
+		`
+		displayNameQuery = `
+		UPDATE users SET display_name = $1 WHERE id = $2;
+		`
+	)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to import habits: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, imp := range imports {
+		h := imp.Habit
+
+		if imp.Existing {
+			var exists bool
+			err := tx.QueryRowContext(ctx, existsQuery, h.ID, userID).Scan(&exists)
+			if err != nil {
+				return fmt.Errorf("failed to import habits: %w", err)
+			}
+			if !exists {
+				return fmt.Errorf("failed to import habits: habit %s not found", h.ID)
+			}
+		} else {
+			_, err := tx.ExecContext(
+				ctx, createQuery,
+				h.ID, userID, h.Status, h.Title, h.Description,
+				h.TrackedWeekDays, time.Time(h.StartDate), time.Time(h.EndDate),
+			)
+			if err != nil {
+				return fmt.Errorf("failed to import habits: %w", err)
+			}
+		}
+
+		months, bitmaps := monthBitmaps(imp.DoneDates)
+		for i, month := range months {
+			_, err := tx.ExecContext(ctx, historyQuery, h.ID, month, bitmaps[i])
+			if err != nil {
+				return fmt.Errorf("failed to import habits: %w", err)
+			}
+		}
+	}
+
+	if displayName != "" {
+		_, err := tx.ExecContext(ctx, displayNameQuery, displayName, userID)
+		if err != nil {
+			return fmt.Errorf("failed to import habits: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to import habits: %w", err)
+	}
+
+	return nil
+}
+
+// monthBitmaps returns the first days of the months of the dates
+// in ascending order, with the bitmaps of the dates in each month.
+func monthBitmaps(dates []date.Date) ([]time.Time, []int64) {
+	bitmaps := make(map[int64]int64)
+	for _, d := range dates {
+		month := time.Time(d.FirstOfMonth()).Unix()
+		bitmaps[month] |= 1 << (time.Time(d).Day() - 1)
+	}
+
+	keys := make([]int64, 0, len(bitmaps))
+	for key := range bitmaps {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	months := make([]time.Time, len(keys))
+	values := make([]int64, len(keys))
+	for i, key := range keys {
+		months[i] = time.Unix(key, 0).UTC()
+		values[i] = bitmaps[key]
+	}
+
+	return months, values
+}
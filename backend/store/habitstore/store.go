@@ -49,6 +49,27 @@ type Store interface {
 	GetMonthHistory(
 		ctx context.Context, id uuid.UUID, historyDate date.Date, userID uuid.UUID,
 	) (habit.History, error)
+
+	// Import creates the habits and sets their done days in a single
+	// transaction, without the [habit.HistoryPatchWindow] limit.
+	// Imports of existing habits only set the done days.
+	// If displayName is not empty, the display name of the user
+	// is set in the same transaction.
+	// It fails if an existing habit does not belong to the user,
+	// or if there is a connection issue, nothing is imported then.
+	Import(
+		ctx context.Context, imports []Import, userID uuid.UUID, displayName string,
+	) error
+}
+
+// Import represents a habit with its done days to import.
+type Import struct {
+	Habit *habit.Habit
+
+	// Existing is true if the habit is already in the store.
+	Existing bool
+
+	DoneDates []date.Date
 }
 
 // Column represents a store column.
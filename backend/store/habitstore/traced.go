@@ -0,0 +1,113 @@
+package habitstore
+
+import (
+	"context"
+
+	"github.com/zvxte/kera/model/date"
+	"github.com/zvxte/kera/model/habit"
+	"github.com/zvxte/kera/model/uuid"
+	"github.com/zvxte/kera/store"
+	"github.com/zvxte/kera/tracing"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Traced represents a [Store] recording a span of every call,
+// named after the package and the method, like habitstore.Create.
+// Errors of calls whose context is done are mapped by [store.MapContextError].
+type Traced struct {
+	store  Store
+	tracer trace.Tracer
+}
+
+// NewTraced returns the store recording spans with the tracer.
+func NewTraced(store Store, tracer trace.Tracer) Traced {
+	return Traced{store, tracer}
+}
+
+func (s Traced) Create(ctx context.Context, habit *habit.Habit, userID uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "habitstore.Create")
+	defer span.End()
+
+	err := s.store.Create(ctx, habit, userID)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return err
+}
+
+func (s Traced) GetAll(ctx context.Context, userID uuid.UUID) ([]*habit.Habit, error) {
+	ctx, span := s.tracer.Start(ctx, "habitstore.GetAll")
+	defer span.End()
+
+	result, err := s.store.GetAll(ctx, userID)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return result, err
+}
+
+func (s Traced) Update(
+	ctx context.Context, id uuid.UUID, col Column, value any, userID uuid.UUID,
+) error {
+	ctx, span := s.tracer.Start(ctx, "habitstore.Update")
+	defer span.End()
+
+	err := s.store.Update(ctx, id, col, value, userID)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return err
+}
+
+func (s Traced) Delete(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "habitstore.Delete")
+	defer span.End()
+
+	err := s.store.Delete(ctx, id, userID)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return err
+}
+
+func (s Traced) End(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "habitstore.End")
+	defer span.End()
+
+	err := s.store.End(ctx, id, userID)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return err
+}
+
+func (s Traced) UpdateHistory(
+	ctx context.Context, id uuid.UUID, historyDate date.Date, userID uuid.UUID,
+) error {
+	ctx, span := s.tracer.Start(ctx, "habitstore.UpdateHistory")
+	defer span.End()
+
+	err := s.store.UpdateHistory(ctx, id, historyDate, userID)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return err
+}
+
+func (s Traced) GetMonthHistory(
+	ctx context.Context, id uuid.UUID, historyDate date.Date, userID uuid.UUID,
+) (habit.History, error) {
+	ctx, span := s.tracer.Start(ctx, "habitstore.GetMonthHistory")
+	defer span.End()
+
+	result, err := s.store.GetMonthHistory(ctx, id, historyDate, userID)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return result, err
+}
+
+func (s Traced) Import(
+	ctx context.Context, imports []Import, userID uuid.UUID, displayName string,
+) error {
+	ctx, span := s.tracer.Start(ctx, "habitstore.Import")
+	defer span.End()
+
+	err := s.store.Import(ctx, imports, userID, displayName)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return err
+}
@@ -0,0 +1,163 @@
+package identitystore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/zvxte/kera/model/identity"
+	"github.com/zvxte/kera/model/uuid"
+	"github.com/zvxte/kera/store"
+)
+
+// Sql represents an relational database implementation
+// of the [identitystore.Store] interface.
+// It uses an [*sql.DB] pool to interact with the database.
+type Sql struct {
+	db *sql.DB
+}
+
+func NewSql(db *sql.DB) (Sql, error) {
+	if db == nil {
+		return Sql{}, store.ErrNilDB
+	}
+	return Sql{db}, nil
+}
+
+func (s Sql) Create(ctx context.Context, identity *identity.Identity) error {
+	const query = `
+	INSERT INTO user_identities(
+		id, user_id, issuer, subject, email, creation_time
+	)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	ON CONFLICT (issuer, subject) DO NOTHING
+	RETURNING 1;
+	`
+
+	var result uint8
+
+	row := s.db.QueryRowContext(
+		ctx, query,
+		identity.ID, identity.UserID, identity.Issuer, identity.Subject,
+		identity.Email, identity.CreationTime,
+	)
+	err := row.Scan(&result)
+	if err == sql.ErrNoRows {
+		return ErrIdentityAlreadyLinked
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create identity: %w", err)
+	}
+
+	return nil
+}
+
+func (s Sql) Get(
+	ctx context.Context, issuer, subject string,
+) (*identity.Identity, error) {
+	const query = `
+	SELECT id, user_id, issuer, subject, email, creation_time
+	FROM user_identities
+	WHERE issuer = $1 AND subject = $2;
+	`
+
+	row := s.db.QueryRowContext(ctx, query, issuer, subject)
+	identity, err := scanIdentity(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get identity: %w", err)
+	}
+
+	return identity, nil
+}
+
+func (s Sql) GetAll(
+	ctx context.Context, userID uuid.UUID,
+) ([]*identity.Identity, error) {
+	const query = `
+	SELECT id, user_id, issuer, subject, email, creation_time
+	FROM user_identities
+	WHERE user_id = $1
+	ORDER BY creation_time;
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all identities: %w", err)
+	}
+	defer rows.Close()
+
+	var identities []*identity.Identity
+
+	for rows.Next() {
+		identity, err := scanIdentity(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get all identities: %w", err)
+		}
+
+		identities = append(identities, identity)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get all identities: %w", err)
+	}
+
+	return identities, nil
+}
+
+func (s Sql) Delete(
+	ctx context.Context, id uuid.UUID, userID uuid.UUID,
+) (bool, error) {
+	const query = `
+	DELETE FROM user_identities
+	WHERE id = $1 AND user_id = $2;
+	`
+
+	result, err := s.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete identity: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete identity: %w", err)
+	}
+
+	return deleted > 0, nil
+}
+
+// scanner is implemented by both [*sql.Row] and [*sql.Rows].
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanIdentity scans a single user_identities row into an *identity.Identity.
+// It returns [sql.ErrNoRows] unwrapped, so the caller can check for it.
+func scanIdentity(row scanner) (*identity.Identity, error) {
+	var rawID, rawUserID, issuer, subject, email string
+	var creationTime time.Time
+
+	err := row.Scan(
+		&rawID, &rawUserID, &issuer, &subject, &email, &creationTime,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := uuid.Parse(rawUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return identity.Load(
+		id, userID, issuer, subject, email, creationTime,
+	), nil
+}
@@ -0,0 +1,32 @@
+package identitystore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/zvxte/kera/model/identity"
+	"github.com/zvxte/kera/model/uuid"
+)
+
+var ErrIdentityAlreadyLinked = errors.New("identity is already linked")
+
+type Store interface {
+	// Create inserts a new identity into the store.
+	// It returns an error if there is a connection issue,
+	// or [identitystore.ErrIdentityAlreadyLinked] if an identity
+	// with the same issuer and subject already exists.
+	Create(ctx context.Context, identity *identity.Identity) error
+
+	// Get returns an identity with the provided issuer and subject or nil.
+	// It fails if there is a connection issue.
+	Get(ctx context.Context, issuer, subject string) (*identity.Identity, error)
+
+	// GetAll returns an identity slice of the provided user or a nil slice.
+	// It fails if there is a connection issue.
+	GetAll(ctx context.Context, userID uuid.UUID) ([]*identity.Identity, error)
+
+	// Delete deletes an identity of the provided user.
+	// It returns false if there is no such identity.
+	// It fails if there is a connection issue.
+	Delete(ctx context.Context, id uuid.UUID, userID uuid.UUID) (bool, error)
+}
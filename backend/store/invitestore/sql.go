@@ -0,0 +1,178 @@
+package invitestore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/zvxte/kera/model/invite"
+	"github.com/zvxte/kera/model/uuid"
+	"github.com/zvxte/kera/store"
+)
+
+// Sql represents an relational database implementation
+// of the [invitestore.Store] interface.
+// It uses an [*sql.DB] pool to interact with the database.
+type Sql struct {
+	db *sql.DB
+}
+
+func NewSql(db *sql.DB) (Sql, error) {
+	if db == nil {
+		return Sql{}, store.ErrNilDB
+	}
+	return Sql{db}, nil
+}
+
+func (s Sql) Create(ctx context.Context, invite *invite.Invite) error {
+	const query = `
+	INSERT INTO invites(
+		id, hashed_code, creator_id, max_uses, uses,
+		creation_time, expiration_time
+	)
+	VALUES ($1, $2, $3, $4, $5, $6, $7);
+	`
+
+	// Invites created outside of the admin API have no creator
+	var creatorID any
+	if invite.CreatorID != (uuid.UUID{}) {
+		creatorID = invite.CreatorID
+	}
+
+	_, err := s.db.ExecContext(
+		ctx, query,
+		invite.ID, invite.HashedCode[:], creatorID,
+		int64(invite.MaxUses), int64(invite.Uses),
+		invite.CreationTime, invite.ExpirationTime,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create invite: %w", err)
+	}
+
+	return nil
+}
+
+func (s Sql) GetAll(ctx context.Context) ([]*invite.Invite, error) {
+	const query = `
+	SELECT
+		id, hashed_code, creator_id, max_uses, uses,
+		creation_time, expiration_time
+	FROM invites
+	ORDER BY creation_time;
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all invites: %w", err)
+	}
+	defer rows.Close()
+
+	var invites []*invite.Invite
+
+	for rows.Next() {
+		invite, err := scanInvite(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get all invites: %w", err)
+		}
+
+		invites = append(invites, invite)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get all invites: %w", err)
+	}
+
+	return invites, nil
+}
+
+func (s Sql) Delete(ctx context.Context, id uuid.UUID) (bool, error) {
+	const query = `
+	DELETE FROM invites
+	WHERE id = $1;
+	`
+
+	result, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete invite: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete invite: %w", err)
+	}
+
+	return deleted > 0, nil
+}
+
+func (s Sql) DeleteExpired(
+	ctx context.Context, now time.Time, limit uint,
+) (uint, error) {
+	const query = `
+	DELETE FROM invites
+	WHERE id IN (
+		SELECT id FROM invites
+		WHERE expiration_time <= $1 OR uses >= max_uses
+		LIMIT $2
+	);
+	`
+
+	result, err := s.db.ExecContext(ctx, query, now, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired invites: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired invites: %w", err)
+	}
+
+	return uint(deleted), nil
+}
+
+// scanner is implemented by both [*sql.Row] and [*sql.Rows].
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanInvite scans a single invites row into an *invite.Invite.
+// It returns [sql.ErrNoRows] unwrapped, so the caller can check for it.
+func scanInvite(row scanner) (*invite.Invite, error) {
+	var rawID string
+	var rawCreatorID sql.NullString
+	var rawHashedCode []byte
+	var maxUses, uses int64
+	var creationTime, expirationTime time.Time
+
+	err := row.Scan(
+		&rawID, &rawHashedCode, &rawCreatorID, &maxUses, &uses,
+		&creationTime, &expirationTime,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		return nil, err
+	}
+
+	var creatorID uuid.UUID
+	if rawCreatorID.Valid {
+		creatorID, err = uuid.Parse(rawCreatorID.String)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var hashedCode invite.HashedCode
+	if len(rawHashedCode) != invite.HashedCodeLen {
+		return nil, fmt.Errorf("hashed code length is %d", len(rawHashedCode))
+	}
+	copy(hashedCode[:], rawHashedCode)
+
+	return invite.Load(
+		id, hashedCode, creatorID, uint32(maxUses), uint32(uses),
+		creationTime, expirationTime,
+	), nil
+}
@@ -0,0 +1,30 @@
+package invitestore
+
+import (
+	"context"
+	"time"
+
+	"github.com/zvxte/kera/model/invite"
+	"github.com/zvxte/kera/model/uuid"
+)
+
+type Store interface {
+	// Create inserts a new invite into the store.
+	// It returns an error if there is a connection issue.
+	Create(ctx context.Context, invite *invite.Invite) error
+
+	// GetAll returns all invites ordered by creation time or a nil slice.
+	// It fails if there is a connection issue.
+	GetAll(ctx context.Context) ([]*invite.Invite, error)
+
+	// Delete deletes an invite from the store.
+	// It returns false if there is no such invite.
+	// It fails if there is a connection issue.
+	Delete(ctx context.Context, id uuid.UUID) (bool, error)
+
+	// DeleteExpired deletes up to limit invites that are used up
+	// or with expiration time at or before the provided time.
+	// It returns the number of deleted invites.
+	// It fails if there is a connection issue.
+	DeleteExpired(ctx context.Context, now time.Time, limit uint) (uint, error)
+}
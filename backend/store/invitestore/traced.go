@@ -0,0 +1,65 @@
+package invitestore
+
+import (
+	"context"
+	"time"
+
+	"github.com/zvxte/kera/model/invite"
+	"github.com/zvxte/kera/model/uuid"
+	"github.com/zvxte/kera/store"
+	"github.com/zvxte/kera/tracing"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Traced represents a [Store] recording a span of every call,
+// named after the package and the method, like invitestore.Create.
+// Errors of calls whose context is done are mapped by [store.MapContextError].
+type Traced struct {
+	store  Store
+	tracer trace.Tracer
+}
+
+// NewTraced returns the store recording spans with the tracer.
+func NewTraced(store Store, tracer trace.Tracer) Traced {
+	return Traced{store, tracer}
+}
+
+func (s Traced) Create(ctx context.Context, invite *invite.Invite) error {
+	ctx, span := s.tracer.Start(ctx, "invitestore.Create")
+	defer span.End()
+
+	err := s.store.Create(ctx, invite)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return err
+}
+
+func (s Traced) GetAll(ctx context.Context) ([]*invite.Invite, error) {
+	ctx, span := s.tracer.Start(ctx, "invitestore.GetAll")
+	defer span.End()
+
+	result, err := s.store.GetAll(ctx)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return result, err
+}
+
+func (s Traced) Delete(ctx context.Context, id uuid.UUID) (bool, error) {
+	ctx, span := s.tracer.Start(ctx, "invitestore.Delete")
+	defer span.End()
+
+	result, err := s.store.Delete(ctx, id)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return result, err
+}
+
+func (s Traced) DeleteExpired(ctx context.Context, now time.Time, limit uint) (uint, error) {
+	ctx, span := s.tracer.Start(ctx, "invitestore.DeleteExpired")
+	defer span.End()
+
+	result, err := s.store.DeleteExpired(ctx, now, limit)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return result, err
+}
@@ -0,0 +1,218 @@
+package passkeystore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/zvxte/kera/model/passkey"
+	"github.com/zvxte/kera/model/uuid"
+	"github.com/zvxte/kera/store"
+)
+
+// Sql represents an relational database implementation
+// of the [passkeystore.Store] interface.
+// It uses an [*sql.DB] pool to interact with the database.
+type Sql struct {
+	db *sql.DB
+}
+
+func NewSql(db *sql.DB) (Sql, error) {
+	if db == nil {
+		return Sql{}, store.ErrNilDB
+	}
+	return Sql{db}, nil
+}
+
+func (s Sql) Create(ctx context.Context, passkey *passkey.Passkey) error {
+	const query = `
+	INSERT INTO passkeys(
+		id, user_id, name, credential_id, public_key, sign_count,
+		creation_time, last_used_time
+	)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, NULL)
+	ON CONFLICT (credential_id) DO NOTHING
+	RETURNING 1;
+	`
+
+	var result uint8
+
+	row := s.db.QueryRowContext(
+		ctx, query,
+		passkey.ID, passkey.UserID, passkey.Name,
+		passkey.CredentialID, passkey.PublicKey, int64(passkey.SignCount),
+		passkey.CreationTime,
+	)
+	err := row.Scan(&result)
+	if err == sql.ErrNoRows {
+		return ErrCredentialAlreadyRegistered
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create passkey: %w", err)
+	}
+
+	return nil
+}
+
+func (s Sql) Get(
+	ctx context.Context, credentialID []byte,
+) (*passkey.Passkey, error) {
+	const query = `
+	SELECT
+		id, user_id, name, credential_id, public_key, sign_count,
+		creation_time, last_used_time
+	FROM passkeys
+	WHERE credential_id = $1;
+	`
+
+	row := s.db.QueryRowContext(ctx, query, credentialID)
+	passkey, err := scanPasskey(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get passkey: %w", err)
+	}
+
+	return passkey, nil
+}
+
+func (s Sql) GetAll(
+	ctx context.Context, userID uuid.UUID,
+) ([]*passkey.Passkey, error) {
+	const query = `
+	SELECT
+		id, user_id, name, credential_id, public_key, sign_count,
+		creation_time, last_used_time
+	FROM passkeys
+	WHERE user_id = $1
+	ORDER BY creation_time;
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all passkeys: %w", err)
+	}
+	defer rows.Close()
+
+	var passkeys []*passkey.Passkey
+
+	for rows.Next() {
+		passkey, err := scanPasskey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get all passkeys: %w", err)
+		}
+
+		passkeys = append(passkeys, passkey)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get all passkeys: %w", err)
+	}
+
+	return passkeys, nil
+}
+
+func (s Sql) UpdateName(
+	ctx context.Context, id uuid.UUID, userID uuid.UUID, name string,
+) (bool, error) {
+	const query = `
+	UPDATE passkeys SET name = $1
+	WHERE id = $2 AND user_id = $3;
+	`
+
+	result, err := s.db.ExecContext(ctx, query, name, id, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to update passkey name: %w", err)
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to update passkey name: %w", err)
+	}
+
+	return updated > 0, nil
+}
+
+func (s Sql) UpdateSignCount(
+	ctx context.Context, id uuid.UUID, signCount uint32, lastUsedTime time.Time,
+) (bool, error) {
+	const query = `
+	UPDATE passkeys SET sign_count = $1, last_used_time = $2
+	WHERE id = $3 AND (sign_count < $1 OR (sign_count = 0 AND $1 = 0));
+	`
+
+	result, err := s.db.ExecContext(
+		ctx, query, int64(signCount), lastUsedTime, id,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to update passkey sign count: %w", err)
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to update passkey sign count: %w", err)
+	}
+
+	return updated > 0, nil
+}
+
+func (s Sql) Delete(
+	ctx context.Context, id uuid.UUID, userID uuid.UUID,
+) (bool, error) {
+	const query = `
+	DELETE FROM passkeys
+	WHERE id = $1 AND user_id = $2;
+	`
+
+	result, err := s.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete passkey: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete passkey: %w", err)
+	}
+
+	return deleted > 0, nil
+}
+
+// scanner is implemented by both [*sql.Row] and [*sql.Rows].
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanPasskey scans a single passkeys row into a *passkey.Passkey.
+// It returns [sql.ErrNoRows] unwrapped, so the caller can check for it.
+func scanPasskey(row scanner) (*passkey.Passkey, error) {
+	var rawID, rawUserID, name string
+	var credentialID, publicKey []byte
+	var signCount int64
+	var creationTime time.Time
+	var lastUsedTime sql.NullTime
+
+	err := row.Scan(
+		&rawID, &rawUserID, &name, &credentialID, &publicKey, &signCount,
+		&creationTime, &lastUsedTime,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := uuid.Parse(rawUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return passkey.Load(
+		id, userID, name, credentialID, publicKey, uint32(signCount),
+		creationTime, lastUsedTime.Time,
+	), nil
+}
@@ -0,0 +1,49 @@
+package passkeystore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/zvxte/kera/model/passkey"
+	"github.com/zvxte/kera/model/uuid"
+)
+
+var ErrCredentialAlreadyRegistered = errors.New("passkey is already registered")
+
+type Store interface {
+	// Create inserts a new passkey into the store.
+	// It returns an error if there is a connection issue,
+	// or [passkeystore.ErrCredentialAlreadyRegistered]
+	// on a credential ID conflict.
+	Create(ctx context.Context, passkey *passkey.Passkey) error
+
+	// Get returns a passkey with the provided credential ID or nil.
+	// It fails if there is a connection issue.
+	Get(ctx context.Context, credentialID []byte) (*passkey.Passkey, error)
+
+	// GetAll returns a passkey slice of the provided user or a nil slice.
+	// It fails if there is a connection issue.
+	GetAll(ctx context.Context, userID uuid.UUID) ([]*passkey.Passkey, error)
+
+	// UpdateName updates the name of a passkey of the provided user.
+	// It returns false if there is no such passkey.
+	// It fails if there is a connection issue.
+	UpdateName(
+		ctx context.Context, id uuid.UUID, userID uuid.UUID, name string,
+	) (bool, error)
+
+	// UpdateSignCount sets the sign count and the last used time of a passkey.
+	// The sign count is set only if it's greater than the stored one,
+	// or both are zero. It returns false otherwise, so concurrent logins
+	// with the same counter value can't both succeed.
+	// It fails if there is a connection issue.
+	UpdateSignCount(
+		ctx context.Context, id uuid.UUID, signCount uint32, lastUsedTime time.Time,
+	) (bool, error)
+
+	// Delete deletes a passkey of the provided user.
+	// It returns false if there is no such passkey.
+	// It fails if there is a connection issue.
+	Delete(ctx context.Context, id uuid.UUID, userID uuid.UUID) (bool, error)
+}
@@ -0,0 +1,89 @@
+package passkeystore
+
+import (
+	"context"
+	"time"
+
+	"github.com/zvxte/kera/model/passkey"
+	"github.com/zvxte/kera/model/uuid"
+	"github.com/zvxte/kera/store"
+	"github.com/zvxte/kera/tracing"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Traced represents a [Store] recording a span of every call,
+// named after the package and the method, like passkeystore.Create.
+// Errors of calls whose context is done are mapped by [store.MapContextError].
+type Traced struct {
+	store  Store
+	tracer trace.Tracer
+}
+
+// NewTraced returns the store recording spans with the tracer.
+func NewTraced(store Store, tracer trace.Tracer) Traced {
+	return Traced{store, tracer}
+}
+
+func (s Traced) Create(ctx context.Context, passkey *passkey.Passkey) error {
+	ctx, span := s.tracer.Start(ctx, "passkeystore.Create")
+	defer span.End()
+
+	err := s.store.Create(ctx, passkey)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return err
+}
+
+func (s Traced) Get(ctx context.Context, credentialID []byte) (*passkey.Passkey, error) {
+	ctx, span := s.tracer.Start(ctx, "passkeystore.Get")
+	defer span.End()
+
+	result, err := s.store.Get(ctx, credentialID)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return result, err
+}
+
+func (s Traced) GetAll(ctx context.Context, userID uuid.UUID) ([]*passkey.Passkey, error) {
+	ctx, span := s.tracer.Start(ctx, "passkeystore.GetAll")
+	defer span.End()
+
+	result, err := s.store.GetAll(ctx, userID)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return result, err
+}
+
+func (s Traced) UpdateName(
+	ctx context.Context, id uuid.UUID, userID uuid.UUID, name string,
+) (bool, error) {
+	ctx, span := s.tracer.Start(ctx, "passkeystore.UpdateName")
+	defer span.End()
+
+	result, err := s.store.UpdateName(ctx, id, userID, name)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return result, err
+}
+
+func (s Traced) UpdateSignCount(
+	ctx context.Context, id uuid.UUID, signCount uint32, lastUsedTime time.Time,
+) (bool, error) {
+	ctx, span := s.tracer.Start(ctx, "passkeystore.UpdateSignCount")
+	defer span.End()
+
+	result, err := s.store.UpdateSignCount(ctx, id, signCount, lastUsedTime)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return result, err
+}
+
+func (s Traced) Delete(ctx context.Context, id uuid.UUID, userID uuid.UUID) (bool, error) {
+	ctx, span := s.tracer.Start(ctx, "passkeystore.Delete")
+	defer span.End()
+
+	result, err := s.store.Delete(ctx, id, userID)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return result, err
+}
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/zvxte/kera/model/date"
 	"github.com/zvxte/kera/model/session"
 	"github.com/zvxte/kera/model/uuid"
 	"github.com/zvxte/kera/store"
@@ -28,14 +27,18 @@ func NewSql(db *sql.DB) (Sql, error) {
 
 func (s Sql) Create(ctx context.Context, session *session.Session) error {
 	const query = `
-	INSERT INTO sessions(id, user_id, creation_date, expiration_date)
-	VALUES ($1, $2, $3, $4);
+	INSERT INTO sessions(
+		id, public_id, user_id, user_agent, ip_address,
+		creation_time, expiration_time, last_seen_time
+	)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
 	`
 
 	_, err := s.db.ExecContext(
 		ctx, query,
-		session.HashedID[:], session.UserID, time.Time(session.CreationDate),
-		time.Time(session.ExpirationDate),
+		session.HashedID[:], session.PublicID, session.UserID,
+		session.UserAgent, session.IPAddress,
+		session.CreationTime, session.ExpirationTime, session.LastSeenTime,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create session: %w", err)
@@ -48,7 +51,9 @@ func (s Sql) Get(
 	ctx context.Context, col Column, value any,
 ) (*session.Session, error) {
 	const hashedIDQuery = `
-	SELECT id, user_id, creation_date, expiration_date
+	SELECT
+		id, public_id, user_id, user_agent, ip_address,
+		creation_time, expiration_time, last_seen_time
 	FROM sessions
 	WHERE id = $1;
 	`
@@ -66,13 +71,8 @@ func (s Sql) Get(
 		return nil, store.ErrInvalidColumn
 	}
 
-	var rawHashedID, rawUserID string
-	var creationDate, expirationDate time.Time
-
 	row := s.db.QueryRowContext(ctx, query, value)
-	err := row.Scan(
-		&rawHashedID, &rawUserID, &creationDate, &expirationDate,
-	)
+	session, err := scanSession(row)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -80,23 +80,96 @@ func (s Sql) Get(
 		return nil, fmt.Errorf("failed to get session: %w", err)
 	}
 
-	userID, err := uuid.Parse(rawUserID)
+	return session, nil
+}
+
+func (s Sql) GetAll(
+	ctx context.Context, userID uuid.UUID,
+) ([]*session.Session, error) {
+	const query = `
+	SELECT
+		id, public_id, user_id, user_agent, ip_address,
+		creation_time, expiration_time, last_seen_time
+	FROM sessions
+	WHERE user_id = $1
+	ORDER BY last_seen_time DESC, creation_time DESC;
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get session: %w", err)
+		return nil, fmt.Errorf("failed to get all sessions: %w", err)
 	}
+	defer rows.Close()
 
-	var hashedID session.HashedID
-	n := copy(hashedID[:], []byte(rawHashedID))
-	if n != session.HashedIDLen {
-		return nil, fmt.Errorf("failed to get session: %w", err)
+	var sessions []*session.Session
+
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get all sessions: %w", err)
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get all sessions: %w", err)
 	}
 
-	session := session.Load(
-		hashedID, userID, date.Load(creationDate),
-		date.Load(expirationDate),
+	return sessions, nil
+}
+
+func (s Sql) Update(
+	ctx context.Context, hashedID session.HashedID, col Column, value any,
+) error {
+	const (
+		expirationTimeQuery = `
+		UPDATE sessions SET expiration_time = $1 WHERE id = $2;
+		`
+		lastSeenTimeQuery = `
+		UPDATE sessions SET last_seen_time = $1 WHERE id = $2;
+		`
 	)
 
-	return session, nil
+	var query string
+	switch col {
+	case ExpirationTimeColumn:
+		if _, ok := value.(time.Time); !ok {
+			return store.ErrInvalidColumnValue
+		}
+		query = expirationTimeQuery
+	case LastSeenTimeColumn:
+		if _, ok := value.(time.Time); !ok {
+			return store.ErrInvalidColumnValue
+		}
+		query = lastSeenTimeQuery
+	default:
+		return store.ErrInvalidColumn
+	}
+
+	_, err := s.db.ExecContext(ctx, query, value, hashedID[:])
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", col, err)
+	}
+
+	return nil
+}
+
+func (s Sql) Renew(
+	ctx context.Context, hashedID session.HashedID,
+	expirationTime, lastSeenTime time.Time,
+) error {
+	const query = `
+	UPDATE sessions SET expiration_time = $1, last_seen_time = $2
+	WHERE id = $3;
+	`
+
+	_, err := s.db.ExecContext(ctx, query, expirationTime, lastSeenTime, hashedID[:])
+	if err != nil {
+		return fmt.Errorf("failed to renew session: %w", err)
+	}
+
+	return nil
 }
 
 func (s Sql) Delete(ctx context.Context, col Column, value any) error {
@@ -135,6 +208,68 @@ func (s Sql) Delete(ctx context.Context, col Column, value any) error {
 	return nil
 }
 
+func (s Sql) DeleteByPublicID(
+	ctx context.Context, publicID uuid.UUID, userID uuid.UUID,
+) (bool, error) {
+	const query = `
+	DELETE FROM sessions
+	WHERE public_id = $1 AND user_id = $2;
+	`
+
+	result, err := s.db.ExecContext(ctx, query, publicID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete session: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete session: %w", err)
+	}
+
+	return deleted > 0, nil
+}
+
+func (s Sql) DeleteAllExcept(
+	ctx context.Context, userID uuid.UUID, hashedID session.HashedID,
+) error {
+	const query = `
+	DELETE FROM sessions
+	WHERE user_id = $1 AND id <> $2;
+	`
+
+	_, err := s.db.ExecContext(ctx, query, userID, hashedID[:])
+	if err != nil {
+		return fmt.Errorf("failed to delete sessions: %w", err)
+	}
+
+	return nil
+}
+
+func (s Sql) DeleteExpired(
+	ctx context.Context, now time.Time, limit uint,
+) (uint, error) {
+	const query = `
+	DELETE FROM sessions
+	WHERE id IN (
+		SELECT id FROM sessions
+		WHERE expiration_time <= $1
+		LIMIT $2
+	);
+	`
+
+	result, err := s.db.ExecContext(ctx, query, now, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired sessions: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired sessions: %w", err)
+	}
+
+	return uint(deleted), nil
+}
+
 func (s Sql) Count(ctx context.Context, userID uuid.UUID) (uint, error) {
 	const query = `
 	SELECT COUNT(id)
@@ -152,3 +287,63 @@ func (s Sql) Count(ctx context.Context, userID uuid.UUID) (uint, error) {
 
 	return count, nil
 }
+
+func (s Sql) CountActive(
+	ctx context.Context, now time.Time,
+) (sessions, users uint, err error) {
+	const query = `
+	SELECT COUNT(id), COUNT(DISTINCT user_id)
+	FROM sessions
+	WHERE expiration_time > $1;
+	`
+
+	row := s.db.QueryRowContext(ctx, query, now)
+	err = row.Scan(&sessions, &users)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count active sessions: %w", err)
+	}
+
+	return sessions, users, nil
+}
+
+// scanner is implemented by both [*sql.Row] and [*sql.Rows].
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanSession scans a single sessions row into a *session.Session.
+// It returns [sql.ErrNoRows] unwrapped, so the caller can check for it.
+func scanSession(row scanner) (*session.Session, error) {
+	var rawHashedID []byte
+	var rawPublicID, rawUserID, userAgent, ipAddress string
+	var creationTime, expirationTime, lastSeenTime time.Time
+
+	err := row.Scan(
+		&rawHashedID, &rawPublicID, &rawUserID, &userAgent, &ipAddress,
+		&creationTime, &expirationTime, &lastSeenTime,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	publicID, err := uuid.Parse(rawPublicID)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := uuid.Parse(rawUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	var hashedID session.HashedID
+	n := copy(hashedID[:], rawHashedID)
+	if n != session.HashedIDLen {
+		return nil, fmt.Errorf("invalid hashed ID length: %d", n)
+	}
+
+	return session.Load(
+		hashedID, publicID, userID, userAgent, ipAddress,
+		creationTime, expirationTime, lastSeenTime,
+	), nil
+}
@@ -2,6 +2,7 @@ package sessionstore
 
 import (
 	"context"
+	"time"
 
 	"github.com/zvxte/kera/model/session"
 	"github.com/zvxte/kera/model/uuid"
@@ -19,6 +20,27 @@ type Store interface {
 	// Supported columns: [sessionstore.HashedIDColumn].
 	Get(ctx context.Context, col Column, value any) (*session.Session, error)
 
+	// GetAll returns a session slice of the provided user or a nil slice.
+	// It fails if there is a connection issue.
+	GetAll(ctx context.Context, userID uuid.UUID) ([]*session.Session, error)
+
+	// Update updates a session in the store.
+	// It fails if there is a connection issue.
+	// It returns [store.ErrInvalidColumn] or [store.ErrInvalidColumnValue]
+	// if unsupported column or invalid column value is provided.
+	// Supported columns: [sessionstore.ExpirationTimeColumn],
+	// [sessionstore.LastSeenTimeColumn].
+	Update(
+		ctx context.Context, hashedID session.HashedID, col Column, value any,
+	) error
+
+	// Renew sets the expiration and last seen times of a session at once.
+	// It fails if there is a connection issue.
+	Renew(
+		ctx context.Context, hashedID session.HashedID,
+		expirationTime, lastSeenTime time.Time,
+	) error
+
 	// Delete deletes a session from the store.
 	// It fails if there is a connection issue.
 	// It returns [store.ErrInvalidColumn] or [store.ErrInvalidColumnValue]
@@ -26,9 +48,35 @@ type Store interface {
 	// Supported columns: [sessionstore.HashedIDColumn], [sessionstore.UserIDColumn].
 	Delete(ctx context.Context, col Column, value any) error
 
+	// DeleteByPublicID deletes a session of the provided user
+	// identified by its public ID.
+	// It returns false if there is no such session.
+	// It fails if there is a connection issue.
+	DeleteByPublicID(
+		ctx context.Context, publicID uuid.UUID, userID uuid.UUID,
+	) (bool, error)
+
+	// DeleteAllExcept deletes all sessions of the provided user
+	// except the one with the provided hashed ID.
+	// It fails if there is a connection issue.
+	DeleteAllExcept(
+		ctx context.Context, userID uuid.UUID, hashedID session.HashedID,
+	) error
+
+	// DeleteExpired deletes up to limit sessions
+	// with expiration time at or before the provided time.
+	// It returns the number of deleted sessions.
+	// It fails if there is a connection issue.
+	DeleteExpired(ctx context.Context, now time.Time, limit uint) (uint, error)
+
 	// Count returns the number of sessions of the provided user.
 	// It fails if there is a connection issue.
 	Count(ctx context.Context, userID uuid.UUID) (uint, error)
+
+	// CountActive returns the number of sessions with expiration time
+	// after the provided time, and the number of users they belong to.
+	// It fails if there is a connection issue.
+	CountActive(ctx context.Context, now time.Time) (sessions, users uint, err error)
 }
 
 // Column represents a store column.
@@ -37,6 +85,8 @@ type Column uint8
 const (
 	HashedIDColumn Column = iota
 	UserIDColumn
+	ExpirationTimeColumn
+	LastSeenTimeColumn
 )
 
 func (c Column) String() string {
@@ -45,6 +95,10 @@ func (c Column) String() string {
 		return "id"
 	case UserIDColumn:
 		return "user_id"
+	case ExpirationTimeColumn:
+		return "expiration_time"
+	case LastSeenTimeColumn:
+		return "last_seen_time"
 	default:
 		return ""
 	}
@@ -0,0 +1,144 @@
+package sessionstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/zvxte/kera/model/session"
+	"github.com/zvxte/kera/model/uuid"
+	"github.com/zvxte/kera/store"
+	"github.com/zvxte/kera/tracing"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Traced represents a [Store] recording a span of every call,
+// named after the package and the method, like sessionstore.Create.
+// Errors of calls whose context is done are mapped by [store.MapContextError].
+type Traced struct {
+	store  Store
+	tracer trace.Tracer
+}
+
+// NewTraced returns the store recording spans with the tracer.
+func NewTraced(store Store, tracer trace.Tracer) Traced {
+	return Traced{store, tracer}
+}
+
+func (s Traced) Create(ctx context.Context, session *session.Session) error {
+	ctx, span := s.tracer.Start(ctx, "sessionstore.Create")
+	defer span.End()
+
+	err := s.store.Create(ctx, session)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return err
+}
+
+func (s Traced) Get(ctx context.Context, col Column, value any) (*session.Session, error) {
+	ctx, span := s.tracer.Start(ctx, "sessionstore.Get")
+	defer span.End()
+
+	result, err := s.store.Get(ctx, col, value)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return result, err
+}
+
+func (s Traced) GetAll(ctx context.Context, userID uuid.UUID) ([]*session.Session, error) {
+	ctx, span := s.tracer.Start(ctx, "sessionstore.GetAll")
+	defer span.End()
+
+	result, err := s.store.GetAll(ctx, userID)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return result, err
+}
+
+func (s Traced) Update(
+	ctx context.Context, hashedID session.HashedID, col Column, value any,
+) error {
+	ctx, span := s.tracer.Start(ctx, "sessionstore.Update")
+	defer span.End()
+
+	err := s.store.Update(ctx, hashedID, col, value)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return err
+}
+
+func (s Traced) Renew(
+	ctx context.Context, hashedID session.HashedID,
+	expirationTime, lastSeenTime time.Time,
+) error {
+	ctx, span := s.tracer.Start(ctx, "sessionstore.Renew")
+	defer span.End()
+
+	err := s.store.Renew(ctx, hashedID, expirationTime, lastSeenTime)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return err
+}
+
+func (s Traced) Delete(ctx context.Context, col Column, value any) error {
+	ctx, span := s.tracer.Start(ctx, "sessionstore.Delete")
+	defer span.End()
+
+	err := s.store.Delete(ctx, col, value)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return err
+}
+
+func (s Traced) DeleteByPublicID(
+	ctx context.Context, publicID uuid.UUID, userID uuid.UUID,
+) (bool, error) {
+	ctx, span := s.tracer.Start(ctx, "sessionstore.DeleteByPublicID")
+	defer span.End()
+
+	result, err := s.store.DeleteByPublicID(ctx, publicID, userID)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return result, err
+}
+
+func (s Traced) DeleteAllExcept(
+	ctx context.Context, userID uuid.UUID, hashedID session.HashedID,
+) error {
+	ctx, span := s.tracer.Start(ctx, "sessionstore.DeleteAllExcept")
+	defer span.End()
+
+	err := s.store.DeleteAllExcept(ctx, userID, hashedID)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return err
+}
+
+func (s Traced) DeleteExpired(ctx context.Context, now time.Time, limit uint) (uint, error) {
+	ctx, span := s.tracer.Start(ctx, "sessionstore.DeleteExpired")
+	defer span.End()
+
+	result, err := s.store.DeleteExpired(ctx, now, limit)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return result, err
+}
+
+func (s Traced) Count(ctx context.Context, userID uuid.UUID) (uint, error) {
+	ctx, span := s.tracer.Start(ctx, "sessionstore.Count")
+	defer span.End()
+
+	result, err := s.store.Count(ctx, userID)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return result, err
+}
+
+func (s Traced) CountActive(ctx context.Context, now time.Time) (sessions, users uint, err error) {
+	ctx, span := s.tracer.Start(ctx, "sessionstore.CountActive")
+	defer span.End()
+
+	sessions, users, err = s.store.CountActive(ctx, now)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return sessions, users, err
+}
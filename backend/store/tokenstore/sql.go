@@ -0,0 +1,123 @@
+package tokenstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/zvxte/kera/model/token"
+	"github.com/zvxte/kera/model/uuid"
+	"github.com/zvxte/kera/store"
+)
+
+// Sql represents an relational database implementation
+// of the [tokenstore.Store] interface.
+// It uses an [*sql.DB] pool to interact with the database.
+type Sql struct {
+	db *sql.DB
+}
+
+func NewSql(db *sql.DB) (Sql, error) {
+	if db == nil {
+		return Sql{}, store.ErrNilDB
+	}
+	return Sql{db}, nil
+}
+
+func (s Sql) Create(ctx context.Context, token *token.Token) error {
+	const query = `
+	INSERT INTO user_tokens(
+		id, user_id, purpose, email, creation_time, expiration_time
+	)
+	VALUES ($1, $2, $3, $4, $5, $6);
+	`
+
+	_, err := s.db.ExecContext(
+		ctx, query,
+		token.HashedID[:], token.UserID, token.Purpose, token.Email,
+		token.CreationTime, token.ExpirationTime,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create token: %w", err)
+	}
+
+	return nil
+}
+
+func (s Sql) Consume(
+	ctx context.Context, hashedID token.HashedID, purpose token.Purpose,
+) (*token.Token, error) {
+	const query = `
+	DELETE FROM user_tokens
+	WHERE id = $1 AND purpose = $2
+	RETURNING user_id, email, creation_time, expiration_time;
+	`
+
+	var rawUserID, email string
+	var creationTime, expirationTime time.Time
+
+	row := s.db.QueryRowContext(ctx, query, hashedID[:], purpose)
+	err := row.Scan(&rawUserID, &email, &creationTime, &expirationTime)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume token: %w", err)
+	}
+
+	// An expired token is deleted as well, it's useless anyway
+	if !time.Now().Before(expirationTime) {
+		return nil, nil
+	}
+
+	userID, err := uuid.Parse(rawUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume token: %w", err)
+	}
+
+	return token.Load(
+		hashedID, userID, purpose, email, creationTime, expirationTime,
+	), nil
+}
+
+func (s Sql) DeleteAll(
+	ctx context.Context, userID uuid.UUID, purpose token.Purpose,
+) error {
+	const query = `
+	DELETE FROM user_tokens
+	WHERE user_id = $1 AND purpose = $2;
+	`
+
+	_, err := s.db.ExecContext(ctx, query, userID, purpose)
+	if err != nil {
+		return fmt.Errorf("failed to delete tokens: %w", err)
+	}
+
+	return nil
+}
+
+func (s Sql) DeleteExpired(
+	ctx context.Context, now time.Time, limit uint,
+) (uint, error) {
+	const query = `
+	DELETE FROM user_tokens
+	WHERE id IN (
+		SELECT id FROM user_tokens
+		WHERE expiration_time <= $1
+		LIMIT $2
+	);
+	`
+
+	result, err := s.db.ExecContext(ctx, query, now, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired tokens: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired tokens: %w", err)
+	}
+
+	return uint(deleted), nil
+}
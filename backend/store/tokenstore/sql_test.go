@@ -0,0 +1,84 @@
+package tokenstore
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/zvxte/kera/database"
+	"github.com/zvxte/kera/model/token"
+	"github.com/zvxte/kera/model/user"
+	"github.com/zvxte/kera/store/userstore"
+)
+
+func TestSqlConsume(t *testing.T) {
+	dataSourceName := os.Getenv("DSN")
+	if dataSourceName == "" {
+		t.Skip("skipping: DSN is not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	sqlDatabase, err := database.NewSqlDatabase(ctx, database.PostgresDriverName, dataSourceName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDatabase.DB.Close()
+
+	if err := sqlDatabase.Teardown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDatabase.Teardown(ctx)
+	if err := sqlDatabase.Setup(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	userStore, err := userstore.NewSql(sqlDatabase.DB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokenStore, err := NewSql(sqlDatabase.DB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	alice, err := user.NewExternal("alice", "Alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := userStore.Create(ctx, alice); err != nil {
+		t.Fatal(err)
+	}
+
+	id, resetToken, err := token.New(alice.ID, token.PasswordReset, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tokenStore.Create(ctx, resetToken); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		purpose token.Purpose
+		found   bool
+	}{
+		{"Wrong purpose", token.EmailVerification, false},
+		{"First use", token.PasswordReset, true},
+		{"Replay", token.PasswordReset, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := tokenStore.Consume(ctx, token.HashID(id), test.purpose)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if found := got != nil && got.UserID == alice.ID; found != test.found {
+				t.Errorf("Consume(%v), found=%v, wanted=%v", test.purpose, found, test.found)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package tokenstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/zvxte/kera/model/token"
+	"github.com/zvxte/kera/model/uuid"
+)
+
+type Store interface {
+	// Create inserts a new token into the store.
+	// It returns an error if there is a connection issue.
+	Create(ctx context.Context, token *token.Token) error
+
+	// Consume deletes a token with the provided hashed ID and purpose
+	// from the store and returns it, or nil if there is no such token
+	// or it has expired. A token can be consumed only once.
+	// It fails if there is a connection issue.
+	Consume(
+		ctx context.Context, hashedID token.HashedID, purpose token.Purpose,
+	) (*token.Token, error)
+
+	// DeleteAll deletes all tokens of the provided user and purpose.
+	// It fails if there is a connection issue.
+	DeleteAll(
+		ctx context.Context, userID uuid.UUID, purpose token.Purpose,
+	) error
+
+	// DeleteExpired deletes up to limit tokens
+	// with expiration time at or before the provided time.
+	// It returns the number of deleted tokens.
+	// It fails if there is a connection issue.
+	DeleteExpired(ctx context.Context, now time.Time, limit uint) (uint, error)
+}
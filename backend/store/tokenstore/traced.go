@@ -0,0 +1,69 @@
+package tokenstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/zvxte/kera/model/token"
+	"github.com/zvxte/kera/model/uuid"
+	"github.com/zvxte/kera/store"
+	"github.com/zvxte/kera/tracing"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Traced represents a [Store] recording a span of every call,
+// named after the package and the method, like tokenstore.Create.
+// Errors of calls whose context is done are mapped by [store.MapContextError].
+type Traced struct {
+	store  Store
+	tracer trace.Tracer
+}
+
+// NewTraced returns the store recording spans with the tracer.
+func NewTraced(store Store, tracer trace.Tracer) Traced {
+	return Traced{store, tracer}
+}
+
+func (s Traced) Create(ctx context.Context, token *token.Token) error {
+	ctx, span := s.tracer.Start(ctx, "tokenstore.Create")
+	defer span.End()
+
+	err := s.store.Create(ctx, token)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return err
+}
+
+func (s Traced) Consume(
+	ctx context.Context, hashedID token.HashedID, purpose token.Purpose,
+) (*token.Token, error) {
+	ctx, span := s.tracer.Start(ctx, "tokenstore.Consume")
+	defer span.End()
+
+	result, err := s.store.Consume(ctx, hashedID, purpose)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return result, err
+}
+
+func (s Traced) DeleteAll(
+	ctx context.Context, userID uuid.UUID, purpose token.Purpose,
+) error {
+	ctx, span := s.tracer.Start(ctx, "tokenstore.DeleteAll")
+	defer span.End()
+
+	err := s.store.DeleteAll(ctx, userID, purpose)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return err
+}
+
+func (s Traced) DeleteExpired(ctx context.Context, now time.Time, limit uint) (uint, error) {
+	ctx, span := s.tracer.Start(ctx, "tokenstore.DeleteExpired")
+	defer span.End()
+
+	result, err := s.store.DeleteExpired(ctx, now, limit)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return result, err
+}
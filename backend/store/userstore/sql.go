@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/zvxte/kera/model/date"
+	"github.com/zvxte/kera/model/invite"
 	"github.com/zvxte/kera/model/user"
 	"github.com/zvxte/kera/model/uuid"
 	"github.com/zvxte/kera/store"
@@ -31,9 +32,10 @@ func NewSql(db *sql.DB) (Sql, error) {
 func (s Sql) Create(ctx context.Context, user *user.User) error {
 	const query = `
 	INSERT INTO users(
-		id, username, username_lower, display_name, hashed_password, creation_date
+		id, username, username_lower, display_name, hashed_password,
+		role, status, creation_date
 	)
-	VALUES ($1, $2, $3, $4, $5, $6)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	ON CONFLICT (username_lower) DO NOTHING
 	RETURNING 1;
 	`
@@ -43,7 +45,8 @@ func (s Sql) Create(ctx context.Context, user *user.User) error {
 	row := s.db.QueryRowContext(
 		ctx, query,
 		user.ID, user.Username, strings.ToLower(user.Username),
-		user.DisplayName, user.HashedPassword, time.Time(user.CreationDate),
+		user.DisplayName, user.HashedPassword, user.Role, user.Status,
+		time.Time(user.CreationDate),
 	)
 	err := row.Scan(&result)
 	if err == sql.ErrNoRows {
@@ -56,20 +59,92 @@ func (s Sql) Create(ctx context.Context, user *user.User) error {
 	return nil
 }
 
+func (s Sql) CreateInvited(
+	ctx context.Context, user *user.User,
+	hashedCode invite.HashedCode, now time.Time,
+) error {
+	const (
+		inviteQuery = `
+		UPDATE invites SET uses = uses + 1
+		WHERE hashed_code = $1 AND uses < max_uses AND expiration_time > $2;
+		`
+		userQuery = `
+		INSERT INTO users(
+			id, username, username_lower, display_name, hashed_password,
+			role, status, creation_date
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (username_lower) DO NOTHING
+		RETURNING 1;
+		`
+	)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, inviteQuery, hashedCode[:], now)
+	if err != nil {
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+	used, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+	if used == 0 {
+		return ErrInvalidInvite
+	}
+
+	var created uint8
+
+	row := tx.QueryRowContext(
+		ctx, userQuery,
+		user.ID, user.Username, strings.ToLower(user.Username),
+		user.DisplayName, user.HashedPassword, user.Role, user.Status,
+		time.Time(user.CreationDate),
+	)
+	err = row.Scan(&created)
+	if err == sql.ErrNoRows {
+		return ErrUsernameAlreadyTaken
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return nil
+}
+
 func (s Sql) Get(
 	ctx context.Context, col Column, value any,
 ) (*user.User, error) {
 	const (
 		idQuery = `
-		SELECT id, username, display_name, hashed_password, creation_date
+		SELECT
+			id, username, display_name, hashed_password,
+			email, email_verified, role, status, creation_date
 		FROM users
 		WHERE id = $1;
 		`
 		usernameQuery = `
-		SELECT id, username, display_name, hashed_password, creation_date
+		SELECT
+			id, username, display_name, hashed_password,
+			email, email_verified, role, status, creation_date
 		FROM users
 		WHERE username_lower = $1;
 		`
+		emailQuery = `
+		SELECT
+			id, username, display_name, hashed_password,
+			email, email_verified, role, status, creation_date
+		FROM users
+		WHERE LOWER(email) = $1 AND email_verified;
+		`
 	)
 
 	var query string
@@ -80,23 +155,25 @@ func (s Sql) Get(
 		}
 		query = idQuery
 	case UsernameColumn:
-		value, ok := value.(string)
+		username, ok := value.(string)
 		if !ok {
 			return nil, store.ErrInvalidColumnValue
 		}
-		value = strings.ToLower(value)
+		value = strings.ToLower(username)
 		query = usernameQuery
+	case EmailColumn:
+		email, ok := value.(string)
+		if !ok {
+			return nil, store.ErrInvalidColumnValue
+		}
+		value = strings.ToLower(email)
+		query = emailQuery
 	default:
 		return nil, store.ErrInvalidColumn
 	}
 
-	var rawUserID, username, displayName, hashedPassword string
-	var creationDate time.Time
-
 	row := s.db.QueryRowContext(ctx, query, value)
-	err := row.Scan(
-		&rawUserID, &username, &displayName, &hashedPassword, &creationDate,
-	)
+	user, err := scanUser(row)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -104,20 +181,88 @@ func (s Sql) Get(
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
-	id, err := uuid.Parse(rawUserID)
+	return user, nil
+}
+
+func (s Sql) List(
+	ctx context.Context, limit, offset uint,
+) ([]*Summary, error) {
+	const query = `
+	SELECT
+		u.id, u.username, u.display_name, u.hashed_password,
+		u.email, u.email_verified, u.role, u.status, u.creation_date,
+		(SELECT COUNT(*) FROM habits h WHERE h.user_id = u.id),
+		u.last_login_time
+	FROM users u
+	ORDER BY u.id
+	LIMIT $1 OFFSET $2;
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, limit, offset)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user: %w", err)
+		return nil, fmt.Errorf("failed to list users: %w", err)
 	}
+	defer rows.Close()
 
-	user, err := user.Load(
-		id, username, displayName,
-		hashedPassword, date.Load(creationDate),
-	)
+	var summaries []*Summary
+
+	for rows.Next() {
+		var habitCount int64
+		var lastLoginTime sql.NullTime
+
+		user, err := scanUser(rows, &habitCount, &lastLoginTime)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list users: %w", err)
+		}
+
+		summaries = append(summaries, &Summary{
+			User:          user,
+			HabitCount:    uint(habitCount),
+			LastLoginTime: lastLoginTime.Time.UTC(),
+		})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	return summaries, nil
+}
+
+func (s Sql) GetAllByStatus(
+	ctx context.Context, status user.Status,
+) ([]*user.User, error) {
+	const query = `
+	SELECT
+		id, username, display_name, hashed_password,
+		email, email_verified, role, status, creation_date
+	FROM users
+	WHERE status = $1
+	ORDER BY id;
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, status)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user: %w", err)
+		return nil, fmt.Errorf("failed to get all users: %w", err)
 	}
+	defer rows.Close()
 
-	return user, nil
+	var users []*user.User
+
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get all users: %w", err)
+		}
+
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get all users: %w", err)
+	}
+
+	return users, nil
 }
 
 func (s Sql) Update(
@@ -130,6 +275,19 @@ func (s Sql) Update(
 		hashedPasswordQuery = `
 		UPDATE users SET hashed_password = $1 WHERE id = $2;
 		`
+		emailQuery = `
+		UPDATE users SET email = NULLIF($1, ''), email_verified = FALSE
+		WHERE id = $2;
+		`
+		roleQuery = `
+		UPDATE users SET role = $1 WHERE id = $2;
+		`
+		statusQuery = `
+		UPDATE users SET status = $1 WHERE id = $2;
+		`
+		lastLoginTimeQuery = `
+		UPDATE users SET last_login_time = $1 WHERE id = $2;
+		`
 	)
 
 	var query string
@@ -144,6 +302,26 @@ func (s Sql) Update(
 			return store.ErrInvalidColumnValue
 		}
 		query = hashedPasswordQuery
+	case EmailColumn:
+		if _, ok := value.(string); !ok {
+			return store.ErrInvalidColumnValue
+		}
+		query = emailQuery
+	case RoleColumn:
+		if role, ok := value.(user.Role); !ok || !role.Valid() {
+			return store.ErrInvalidColumnValue
+		}
+		query = roleQuery
+	case StatusColumn:
+		if status, ok := value.(user.Status); !ok || !status.Valid() {
+			return store.ErrInvalidColumnValue
+		}
+		query = statusQuery
+	case LastLoginTimeColumn:
+		if _, ok := value.(time.Time); !ok {
+			return store.ErrInvalidColumnValue
+		}
+		query = lastLoginTimeQuery
 	default:
 		return store.ErrInvalidColumn
 	}
@@ -156,6 +334,29 @@ func (s Sql) Update(
 	return nil
 }
 
+func (s Sql) VerifyEmail(
+	ctx context.Context, id uuid.UUID, email string,
+) (bool, error) {
+	const query = `
+	UPDATE users SET email_verified = TRUE
+	WHERE id = $1 AND LOWER(email) = $2
+	RETURNING 1;
+	`
+
+	var result uint8
+
+	row := s.db.QueryRowContext(ctx, query, id, strings.ToLower(email))
+	err := row.Scan(&result)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to verify email: %w", err)
+	}
+
+	return true, nil
+}
+
 func (s Sql) Delete(ctx context.Context, id uuid.UUID) error {
 	const query = `
 	DELETE FROM users
@@ -169,3 +370,39 @@ func (s Sql) Delete(ctx context.Context, id uuid.UUID) error {
 
 	return nil
 }
+
+// scanner is implemented by both [*sql.Row] and [*sql.Rows].
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanUser scans a single users row into a *user.User.
+// Columns following the user ones are scanned into extra.
+// It returns [sql.ErrNoRows] unwrapped, so the caller can check for it.
+func scanUser(row scanner, extra ...any) (*user.User, error) {
+	var rawUserID, username, displayName, hashedPassword string
+	var email sql.NullString
+	var emailVerified bool
+	var role user.Role
+	var status user.Status
+	var creationDate time.Time
+
+	dest := []any{
+		&rawUserID, &username, &displayName, &hashedPassword,
+		&email, &emailVerified, &role, &status, &creationDate,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := uuid.Parse(rawUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return user.Load(
+		id, username, displayName, hashedPassword,
+		email.String, emailVerified, role, status, date.Load(creationDate),
+	)
+}
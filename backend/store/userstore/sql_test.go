@@ -0,0 +1,168 @@
+package userstore
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/zvxte/kera/database"
+	"github.com/zvxte/kera/model/invite"
+	"github.com/zvxte/kera/model/user"
+	"github.com/zvxte/kera/model/uuid"
+	"github.com/zvxte/kera/store/invitestore"
+)
+
+func TestSqlGet(t *testing.T) {
+	dataSourceName := os.Getenv("DSN")
+	if dataSourceName == "" {
+		t.Skip("skipping: DSN is not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	sqlDatabase, err := database.NewSqlDatabase(ctx, database.PostgresDriverName, dataSourceName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDatabase.DB.Close()
+
+	if err := sqlDatabase.Teardown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDatabase.Teardown(ctx)
+	if err := sqlDatabase.Setup(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	userStore, err := NewSql(sqlDatabase.DB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	alice, err := user.NewExternal("Alice", "Alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := userStore.Create(ctx, alice); err != nil {
+		t.Fatal(err)
+	}
+	const email = "Alice.Smith@Example.com"
+	if err := userStore.Update(ctx, alice.ID, EmailColumn, email); err != nil {
+		t.Fatal(err)
+	}
+	if verified, err := userStore.VerifyEmail(ctx, alice.ID, email); err != nil || !verified {
+		t.Fatalf("VerifyEmail(%q), verified=%v, error=%v", email, verified, err)
+	}
+
+	tests := []struct {
+		name  string
+		col   Column
+		value string
+		found bool
+	}{
+		{"Username: as stored", UsernameColumn, "Alice", true},
+		{"Username: lowercase", UsernameColumn, "alice", true},
+		{"Username: uppercase", UsernameColumn, "ALICE", true},
+		{"Username: unknown", UsernameColumn, "bob", false},
+		{"Email: as stored", EmailColumn, email, true},
+		{"Email: lowercase", EmailColumn, "alice.smith@example.com", true},
+		{"Email: uppercase", EmailColumn, "ALICE.SMITH@EXAMPLE.COM", true},
+		{"Email: unknown", EmailColumn, "bob@example.com", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := userStore.Get(ctx, test.col, test.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if found := got != nil && got.ID == alice.ID; found != test.found {
+				t.Errorf("Get(%v, %q), found=%v, wanted=%v", test.col, test.value, found, test.found)
+			}
+		})
+	}
+}
+
+func TestSqlCreateInvited(t *testing.T) {
+	dataSourceName := os.Getenv("DSN")
+	if dataSourceName == "" {
+		t.Skip("skipping: DSN is not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	sqlDatabase, err := database.NewSqlDatabase(ctx, database.PostgresDriverName, dataSourceName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDatabase.DB.Close()
+
+	if err := sqlDatabase.Teardown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDatabase.Teardown(ctx)
+	if err := sqlDatabase.Setup(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	userStore, err := NewSql(sqlDatabase.DB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inviteStore, err := invitestore.NewSql(sqlDatabase.DB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	alice, err := user.NewExternal("alice", "Alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := userStore.Create(ctx, alice); err != nil {
+		t.Fatal(err)
+	}
+
+	code, singleUse, err := invite.New(uuid.UUID{}, 1, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inviteStore.Create(ctx, singleUse); err != nil {
+		t.Fatal(err)
+	}
+	unknownCode, _, err := invite.New(uuid.UUID{}, 1, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The cases run in order, the invite is used up by the valid one
+	tests := []struct {
+		name     string
+		username string
+		code     string
+		expected error
+	}{
+		{"Invalid: unknown invite", "bob", unknownCode, ErrInvalidInvite},
+		{"Invalid: unknown invite, taken username", "alice", unknownCode, ErrInvalidInvite},
+		{"Invalid: taken username", "alice", code, ErrUsernameAlreadyTaken},
+		{"Valid", "bob", code, nil},
+		{"Invalid: used up invite", "carol", code, ErrInvalidInvite},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			newUser, err := user.NewExternal(test.username, test.username)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = userStore.CreateInvited(ctx, newUser, invite.HashCode(test.code), time.Now())
+			if !errors.Is(err, test.expected) {
+				t.Errorf("CreateInvited(%q), error=%v, expected=%v", test.username, err, test.expected)
+			}
+		})
+	}
+}
@@ -3,12 +3,17 @@ package userstore
 import (
 	"context"
 	"errors"
+	"time"
 
+	"github.com/zvxte/kera/model/invite"
 	"github.com/zvxte/kera/model/user"
 	"github.com/zvxte/kera/model/uuid"
 )
 
-var ErrUsernameAlreadyTaken = errors.New("username is already taken")
+var (
+	ErrUsernameAlreadyTaken = errors.New("username is already taken")
+	ErrInvalidInvite        = errors.New("invite is invalid")
+)
 
 type Store interface {
 	// Create inserts a new user into the store.
@@ -16,25 +21,65 @@ type Store interface {
 	// or [userstore.ErrUsernameAlreadyTaken] on a username conflict.
 	Create(ctx context.Context, user *user.User) error
 
+	// CreateInvited uses the invite with the provided hashed code
+	// and inserts a new user into the store in a single transaction.
+	// It returns [userstore.ErrInvalidInvite] if there is no such invite,
+	// it's used up or expired at the provided time, checked before the username,
+	// or [userstore.ErrUsernameAlreadyTaken] on a username conflict,
+	// the invite is not used then.
+	// It fails if there is a connection issue.
+	CreateInvited(
+		ctx context.Context, user *user.User,
+		hashedCode invite.HashedCode, now time.Time,
+	) error
+
 	// Get returns a user from the store or nil.
 	// It fails if there is a connection issue.
 	// It returns [store.ErrInvalidColumn] or [store.ErrInvalidColumnValue]
 	// if unsupported column or invalid column value is provided.
-	// Supported columns: [userstore.IDColumn], [userstore.UsernameColumn].
+	// Supported columns: [userstore.IDColumn], [userstore.UsernameColumn],
+	// [userstore.EmailColumn] (matches only verified emails).
 	Get(ctx context.Context, col Column, value any) (*user.User, error)
 
+	// List returns up to limit users with their usage ordered by ID
+	// (creation order), skipping the first offset ones, or a nil slice.
+	// It fails if there is a connection issue.
+	List(ctx context.Context, limit, offset uint) ([]*Summary, error)
+
+	// GetAllByStatus returns a user slice with the provided status
+	// ordered by ID (creation order) or a nil slice.
+	// It fails if there is a connection issue.
+	GetAllByStatus(ctx context.Context, status user.Status) ([]*user.User, error)
+
 	// Update updates a user in the store.
 	// It fails if there is a connection issue.
 	// It returns [store.ErrInvalidColumn] or [store.ErrInvalidColumnValue]
 	// if unsupported column or invalid column value is provided.
-	// Supported columns: [userstore.DisplayNameColumn], [userstore.HashedPasswordColumn].
+	// Supported columns: [userstore.DisplayNameColumn], [userstore.HashedPasswordColumn],
+	// [userstore.EmailColumn] (unsets the email verification, empty value unsets the email),
+	// [userstore.RoleColumn] ([user.Role]), [userstore.StatusColumn] ([user.Status]),
+	// [userstore.LastLoginTimeColumn] ([time.Time]).
 	Update(ctx context.Context, id uuid.UUID, col Column, value any) error
 
+	// VerifyEmail marks the email of a user as verified.
+	// It returns false if the user's current email is not the provided one.
+	// It fails if there is a connection issue.
+	VerifyEmail(ctx context.Context, id uuid.UUID, email string) (bool, error)
+
 	// Delete deletes a user from the store.
 	// It fails if there is a connection issue.
 	Delete(ctx context.Context, id uuid.UUID) error
 }
 
+// Summary represents a user with its usage, as seen by admins.
+type Summary struct {
+	User       *user.User
+	HabitCount uint
+
+	// LastLoginTime is zero if the user never logged in.
+	LastLoginTime time.Time
+}
+
 // Column represents a store column.
 type Column uint8
 
@@ -43,6 +88,10 @@ const (
 	UsernameColumn
 	DisplayNameColumn
 	HashedPasswordColumn
+	EmailColumn
+	RoleColumn
+	StatusColumn
+	LastLoginTimeColumn
 )
 
 func (c Column) String() string {
@@ -55,6 +104,14 @@ func (c Column) String() string {
 		return "display_name"
 	case HashedPasswordColumn:
 		return "hashed_password"
+	case EmailColumn:
+		return "email"
+	case RoleColumn:
+		return "role"
+	case StatusColumn:
+		return "status"
+	case LastLoginTimeColumn:
+		return "last_login_time"
 	default:
 		return ""
 	}
@@ -0,0 +1,109 @@
+package userstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/zvxte/kera/model/invite"
+	"github.com/zvxte/kera/model/user"
+	"github.com/zvxte/kera/model/uuid"
+	"github.com/zvxte/kera/store"
+	"github.com/zvxte/kera/tracing"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Traced represents a [Store] recording a span of every call,
+// named after the package and the method, like userstore.Create.
+// Errors of calls whose context is done are mapped by [store.MapContextError].
+type Traced struct {
+	store  Store
+	tracer trace.Tracer
+}
+
+// NewTraced returns the store recording spans with the tracer.
+func NewTraced(store Store, tracer trace.Tracer) Traced {
+	return Traced{store, tracer}
+}
+
+func (s Traced) Create(ctx context.Context, user *user.User) error {
+	ctx, span := s.tracer.Start(ctx, "userstore.Create")
+	defer span.End()
+
+	err := s.store.Create(ctx, user)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return err
+}
+
+func (s Traced) CreateInvited(
+	ctx context.Context, user *user.User,
+	hashedCode invite.HashedCode, now time.Time,
+) error {
+	ctx, span := s.tracer.Start(ctx, "userstore.CreateInvited")
+	defer span.End()
+
+	err := s.store.CreateInvited(ctx, user, hashedCode, now)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return err
+}
+
+func (s Traced) Get(ctx context.Context, col Column, value any) (*user.User, error) {
+	ctx, span := s.tracer.Start(ctx, "userstore.Get")
+	defer span.End()
+
+	result, err := s.store.Get(ctx, col, value)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return result, err
+}
+
+func (s Traced) List(ctx context.Context, limit, offset uint) ([]*Summary, error) {
+	ctx, span := s.tracer.Start(ctx, "userstore.List")
+	defer span.End()
+
+	result, err := s.store.List(ctx, limit, offset)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return result, err
+}
+
+func (s Traced) GetAllByStatus(ctx context.Context, status user.Status) ([]*user.User, error) {
+	ctx, span := s.tracer.Start(ctx, "userstore.GetAllByStatus")
+	defer span.End()
+
+	result, err := s.store.GetAllByStatus(ctx, status)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return result, err
+}
+
+func (s Traced) Update(ctx context.Context, id uuid.UUID, col Column, value any) error {
+	ctx, span := s.tracer.Start(ctx, "userstore.Update")
+	defer span.End()
+
+	err := s.store.Update(ctx, id, col, value)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return err
+}
+
+func (s Traced) VerifyEmail(ctx context.Context, id uuid.UUID, email string) (bool, error) {
+	ctx, span := s.tracer.Start(ctx, "userstore.VerifyEmail")
+	defer span.End()
+
+	result, err := s.store.VerifyEmail(ctx, id, email)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return result, err
+}
+
+func (s Traced) Delete(ctx context.Context, id uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "userstore.Delete")
+	defer span.End()
+
+	err := s.store.Delete(ctx, id)
+	err = store.MapContextError(ctx, err)
+	tracing.SetError(span, err)
+	return err
+}
@@ -0,0 +1,48 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/zvxte/kera/model/user"
+)
+
+// hasher represents a user.PasswordHasher recording spans
+// as children of the span in its context.
+type hasher struct {
+	ctx    context.Context
+	hasher user.PasswordHasher
+}
+
+// Hasher returns the hasher recording a span of every call,
+// as a child of the span in the context.
+// Hashing is slow on purpose, its spans tell it apart from queries.
+func Hasher(ctx context.Context, h user.PasswordHasher) user.PasswordHasher {
+	return hasher{ctx, h}
+}
+
+func (h hasher) Hash(plainPassword string) (string, error) {
+	_, span := Start(h.ctx, "password.Hash")
+	defer span.End()
+
+	hashedPassword, err := h.hasher.Hash(plainPassword)
+	SetError(span, err)
+	return hashedPassword, err
+}
+
+func (h hasher) Verify(plainPassword, hashedPassword string) (bool, error) {
+	_, span := Start(h.ctx, "password.Verify")
+	defer span.End()
+
+	isValid, err := h.hasher.Verify(plainPassword, hashedPassword)
+	SetError(span, err)
+	return isValid, err
+}
+
+func (h hasher) NeedsRehash(hashedPassword string) (bool, error) {
+	_, span := Start(h.ctx, "password.NeedsRehash")
+	defer span.End()
+
+	needsRehash, err := h.hasher.NeedsRehash(hashedPassword)
+	SetError(span, err)
+	return needsRehash, err
+}
@@ -0,0 +1,110 @@
+// Package tracing records OpenTelemetry spans of kera
+// and exports them with OTLP over HTTP.
+//
+// HTTP requests start their spans with the tracer of the server,
+// code handling them starts child spans with [Start],
+// which uses the tracer provider of the span in the context,
+// so nothing is recorded if tracing is disabled.
+// Trace context is propagated with W3C traceparent and tracestate headers.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the name of the tracer of kera's spans.
+const TracerName = "github.com/zvxte/kera"
+
+var (
+	ErrInvalidEndpoint    = errors.New("tracing endpoint is invalid: it must be an http or https URL")
+	ErrInvalidSampleRatio = errors.New("tracing sample ratio is invalid: it must be between 0 and 1")
+)
+
+// Propagator returns the W3C trace context and baggage propagator.
+func Propagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{},
+	)
+}
+
+// NewExporter returns an OTLP exporter sending spans over HTTP
+// to the endpoint URL, like http://localhost:4318/v1/traces.
+// It fails if the endpoint is not an http or https URL.
+func NewExporter(ctx context.Context, endpoint string) (sdktrace.SpanExporter, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidEndpoint, endpoint)
+	}
+
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+	return exporter, nil
+}
+
+// NewProvider returns a *sdktrace.TracerProvider batching spans
+// to the exporter. Root spans are sampled by the ratio, from 0 to 1,
+// child spans follow their parent, so traces started by other services
+// are kept whole.
+// It fails if the ratio is out of range.
+func NewProvider(
+	exporter sdktrace.SpanExporter, serviceName string, sampleRatio float64,
+) (*sdktrace.TracerProvider, error) {
+	if sampleRatio < 0 || sampleRatio > 1 {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSampleRatio, sampleRatio)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", serviceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tracing resource: %w", err)
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(
+			sdktrace.TraceIDRatioBased(sampleRatio),
+		)),
+	), nil
+}
+
+// SetGlobal installs the provider and [Propagator] as the global
+// OpenTelemetry ones, used by libraries instrumented with them.
+func SetGlobal(provider trace.TracerProvider) {
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(Propagator())
+}
+
+// Start starts a span as a child of the span in the context,
+// with the tracer of its provider.
+func Start(
+	ctx context.Context, name string, opts ...trace.SpanStartOption,
+) (context.Context, trace.Span) {
+	tracer := trace.SpanFromContext(ctx).TracerProvider().Tracer(TracerName)
+	return tracer.Start(ctx, name, opts...)
+}
+
+// SetError records the error on the span and marks it as failed.
+// It's a no-op if the error is nil.
+func SetError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,189 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/zvxte/kera/model/user"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var errHash = errors.New("hash failed")
+
+// fakeHasher represents a user.PasswordHasher failing with errHash
+// if the password is "fail".
+type fakeHasher struct{}
+
+func (fakeHasher) Hash(plainPassword string) (string, error) {
+	if plainPassword == "fail" {
+		return "", errHash
+	}
+	return "hashed:" + plainPassword, nil
+}
+
+func (fakeHasher) Verify(plainPassword, hashedPassword string) (bool, error) {
+	return "hashed:"+plainPassword == hashedPassword, nil
+}
+
+func (fakeHasher) NeedsRehash(hashedPassword string) (bool, error) {
+	return false, nil
+}
+
+var _ user.PasswordHasher = fakeHasher{}
+
+func newTestTracer() (trace.Tracer, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	return provider.Tracer(TracerName), exporter
+}
+
+func TestStart(t *testing.T) {
+	tracer, exporter := newTestTracer()
+
+	ctx, parent := tracer.Start(context.Background(), "parent")
+	_, child := Start(ctx, "child")
+	child.End()
+	parent.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("Start(), spans=%d, want=2", len(spans))
+	}
+	if spans[0].Name != "child" {
+		t.Errorf("Start(), name=%q, want=%q", spans[0].Name, "child")
+	}
+	if spans[0].Parent.SpanID() != spans[1].SpanContext.SpanID() {
+		t.Errorf("Start(), child span is not a child of the parent span")
+	}
+
+	// Without a span in the context nothing is recorded
+	_, span := Start(context.Background(), "orphan")
+	if span.IsRecording() {
+		t.Errorf("Start(), orphan span is recording")
+	}
+}
+
+func TestSetError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code codes.Code
+	}{
+		{"Error", errHash, codes.Error},
+		{"Nil", nil, codes.Unset},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tracer, exporter := newTestTracer()
+
+			_, span := tracer.Start(context.Background(), "span")
+			SetError(span, test.err)
+			span.End()
+
+			code := exporter.GetSpans()[0].Status.Code
+			if code != test.code {
+				t.Errorf("SetError(%v), code=%v, want=%v", test.err, code, test.code)
+			}
+		})
+	}
+}
+
+func TestHasher(t *testing.T) {
+	tests := []struct {
+		name          string
+		plainPassword string
+		shouldErr     bool
+	}{
+		{"Valid", "password", false},
+		{"Invalid: failing hash", "fail", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tracer, exporter := newTestTracer()
+			ctx, parent := tracer.Start(context.Background(), "parent")
+
+			hasher := Hasher(ctx, fakeHasher{})
+			hashedPassword, err := hasher.Hash(test.plainPassword)
+			if (err != nil) != test.shouldErr {
+				t.Fatalf("Hash(%q), error=%v, shouldErr=%v", test.plainPassword, err, test.shouldErr)
+			}
+			if !test.shouldErr {
+				isValid, err := hasher.Verify(test.plainPassword, hashedPassword)
+				if err != nil || !isValid {
+					t.Errorf("Verify(%q), isValid=%v, error=%v", test.plainPassword, isValid, err)
+				}
+			}
+			parent.End()
+
+			spans := exporter.GetSpans()
+			hashSpan := spans[0]
+			if hashSpan.Name != "password.Hash" {
+				t.Errorf("Hash(%q), name=%q, want=%q", test.plainPassword, hashSpan.Name, "password.Hash")
+			}
+			if hashSpan.Parent.SpanID() != parent.SpanContext().SpanID() {
+				t.Errorf("Hash(%q), span is not a child of the parent span", test.plainPassword)
+			}
+			if (hashSpan.Status.Code == codes.Error) != test.shouldErr {
+				t.Errorf("Hash(%q), code=%v, shouldErr=%v", test.plainPassword, hashSpan.Status.Code, test.shouldErr)
+			}
+		})
+	}
+}
+
+func TestNewExporter(t *testing.T) {
+	tests := []struct {
+		name      string
+		endpoint  string
+		shouldErr bool
+	}{
+		{"Valid: http", "http://localhost:4318/v1/traces", false},
+		{"Valid: https", "https://otel.example.com/v1/traces", false},
+		{"Invalid: empty", "", true},
+		{"Invalid: scheme", "grpc://localhost:4317", true},
+		{"Invalid: no host", "http:///v1/traces", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			exporter, err := NewExporter(context.Background(), test.endpoint)
+			if (err != nil) != test.shouldErr {
+				t.Errorf("NewExporter(%q), error=%v, shouldErr=%v", test.endpoint, err, test.shouldErr)
+			}
+			if exporter != nil {
+				exporter.Shutdown(context.Background())
+			}
+		})
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	tests := []struct {
+		name        string
+		sampleRatio float64
+		shouldErr   bool
+	}{
+		{"Valid: all", 1, false},
+		{"Valid: none", 0, false},
+		{"Valid: half", 0.5, false},
+		{"Invalid: negative", -0.1, true},
+		{"Invalid: above one", 1.1, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			provider, err := NewProvider(tracetest.NewInMemoryExporter(), "kera", test.sampleRatio)
+			if (err != nil) != test.shouldErr {
+				t.Errorf("NewProvider(%v), error=%v, shouldErr=%v", test.sampleRatio, err, test.shouldErr)
+			}
+			if provider != nil {
+				provider.Shutdown(context.Background())
+			}
+		})
+	}
+}
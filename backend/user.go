@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/zvxte/kera/logging"
+	"github.com/zvxte/kera/model/audit"
+	"github.com/zvxte/kera/model/token"
+	"github.com/zvxte/kera/model/user"
+	"github.com/zvxte/kera/model/uuid"
+	"github.com/zvxte/kera/server"
+	"github.com/zvxte/kera/store/auditstore"
+	"github.com/zvxte/kera/store/sessionstore"
+	"github.com/zvxte/kera/store/tokenstore"
+	"github.com/zvxte/kera/store/userstore"
+)
+
+const userUsage = `Usage:
+  kera user create [flags] USERNAME          creates a user with the password read from standard input
+  kera user reset-password [flags] USERNAME  sets the password read from standard input, ends sessions and reset tokens
+  kera user disable [flags] USERNAME         disables the user and ends its sessions`
+
+// runUser manages the users of the database read from database.dsn of the config,
+// the same way as the register and admin endpoints do.
+func runUser(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("user: command is required\n%s", userUsage)
+	}
+	command, args := args[0], args[1:]
+
+	switch command {
+	case "create", "reset-password", "disable":
+	case "-h", "-help", "--help":
+		fmt.Fprintln(os.Stderr, userUsage)
+		return flag.ErrHelp
+	default:
+		return fmt.Errorf("user: unknown command %q\n%s", command, userUsage)
+	}
+
+	flags := flag.NewFlagSet("user "+command, flag.ContinueOnError)
+	var admin *bool
+	if command == "create" {
+		admin = flags.Bool("admin", false, "create the user as an admin")
+	}
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), userUsage)
+		flags.PrintDefaults()
+	}
+	cfg, err := loadConfig(flags, args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("user %s: USERNAME is required\n%s", command, userUsage)
+	}
+	username := flags.Arg(0)
+
+	// The password is read before connecting,
+	// so a missing one fails without touching the database
+	var plainPassword string
+	var policy *user.PasswordPolicy
+	var hasher user.PasswordHasher
+	if command != "disable" {
+		plainPassword, err = readPassword(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("user %s: %w", command, err)
+		}
+
+		logger, err := logging.New(os.Stderr, cfg.Log.Level, cfg.Log.Format)
+		if err != nil {
+			return fmt.Errorf("user %s: %w", command, err)
+		}
+		policy, err = server.NewPasswordPolicy(cfg.Password, logger)
+		if err != nil {
+			return fmt.Errorf("user %s: %w", command, err)
+		}
+		hasher, err = server.NewPasswordHasher(cfg.Password, logger)
+		if err != nil {
+			return fmt.Errorf("user %s: %w", command, err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	sqlDatabase, err := openDatabase(ctx, cfg, "user "+command)
+	if err != nil {
+		return err
+	}
+	defer sqlDatabase.DB.Close()
+
+	userStore, err := userstore.NewSql(sqlDatabase.DB)
+	if err != nil {
+		return fmt.Errorf("user %s: %w", command, err)
+	}
+	sessionStore, err := sessionstore.NewSql(sqlDatabase.DB)
+	if err != nil {
+		return fmt.Errorf("user %s: %w", command, err)
+	}
+	tokenStore, err := tokenstore.NewSql(sqlDatabase.DB)
+	if err != nil {
+		return fmt.Errorf("user %s: %w", command, err)
+	}
+	auditStore, err := auditstore.NewSql(sqlDatabase.DB)
+	if err != nil {
+		return fmt.Errorf("user %s: %w", command, err)
+	}
+
+	switch command {
+	case "create":
+		err = createUser(ctx, userStore, username, plainPassword, policy, hasher, *admin)
+	case "reset-password":
+		err = resetPassword(
+			ctx, userStore, sessionStore, tokenStore, auditStore,
+			username, plainPassword, policy, hasher,
+		)
+	case "disable":
+		err = disableUser(ctx, userStore, sessionStore, auditStore, username)
+	}
+	if err != nil {
+		return fmt.Errorf("user %s: %w", command, err)
+	}
+	return nil
+}
+
+// createUser creates an active user, like the register endpoint
+// in the open registration mode.
+func createUser(
+	ctx context.Context, userStore userstore.Store,
+	username, plainPassword string,
+	policy *user.PasswordPolicy, hasher user.PasswordHasher, admin bool,
+) error {
+	newUser, err := user.New(username, plainPassword, policy, hasher)
+	if err != nil {
+		return err
+	}
+	if admin {
+		newUser.Role = user.RoleAdmin
+	}
+
+	err = userStore.Create(ctx, newUser)
+	if errors.Is(err, userstore.ErrUsernameAlreadyTaken) {
+		return fmt.Errorf("user %q already exists", username)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("created %s %q\n", newUser.Role, username)
+	return nil
+}
+
+// resetPassword sets a new password of the user, ends all its sessions
+// and revokes its password reset tokens, like a confirmed password reset.
+// Unlike the admin endpoint, it sets the password right away
+// instead of issuing a password reset token.
+func resetPassword(
+	ctx context.Context, userStore userstore.Store,
+	sessionStore sessionstore.Store, tokenStore tokenstore.Store,
+	auditStore auditstore.Store,
+	username, plainPassword string,
+	policy *user.PasswordPolicy, hasher user.PasswordHasher,
+) error {
+	target, err := getUser(ctx, userStore, username)
+	if err != nil {
+		return err
+	}
+
+	if err := policy.Validate(target.Username, plainPassword); err != nil {
+		return err
+	}
+
+	hashedPassword, err := hasher.Hash(plainPassword)
+	if err != nil {
+		return err
+	}
+
+	err = userStore.Update(
+		ctx, target.ID, userstore.HashedPasswordColumn, hashedPassword,
+	)
+	if err != nil {
+		return err
+	}
+
+	err = sessionStore.Delete(ctx, sessionstore.UserIDColumn, target.ID)
+	if err != nil {
+		return err
+	}
+
+	err = tokenStore.DeleteAll(ctx, target.ID, token.PasswordReset)
+	if err != nil {
+		return err
+	}
+
+	recordAudit(ctx, auditStore, audit.UserPasswordReset, target)
+
+	fmt.Printf("reset password of %q\n", username)
+	return nil
+}
+
+// disableUser disables the user and ends all its sessions,
+// like the admin endpoint.
+func disableUser(
+	ctx context.Context, userStore userstore.Store,
+	sessionStore sessionstore.Store, auditStore auditstore.Store,
+	username string,
+) error {
+	target, err := getUser(ctx, userStore, username)
+	if err != nil {
+		return err
+	}
+	if target.Status == user.StatusDisabled {
+		return fmt.Errorf("user %q is already disabled", username)
+	}
+
+	err = userStore.Update(
+		ctx, target.ID, userstore.StatusColumn, user.StatusDisabled,
+	)
+	if err != nil {
+		return err
+	}
+
+	err = sessionStore.Delete(ctx, sessionstore.UserIDColumn, target.ID)
+	if err != nil {
+		return err
+	}
+
+	recordAudit(ctx, auditStore, audit.UserDisabled, target)
+
+	fmt.Printf("disabled %q\n", username)
+	return nil
+}
+
+// recordAudit adds the action to the audit trail without an admin,
+// as it's done from the command line.
+// The action is already done, so a failure is only reported.
+func recordAudit(
+	ctx context.Context, auditStore auditstore.Store,
+	action audit.Action, target *user.User,
+) {
+	entry, err := audit.New(uuid.UUID{}, action, target.ID, target.Username)
+	if err == nil {
+		err = auditStore.Create(ctx, entry)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to record audit entry: %v\n", err)
+	}
+}
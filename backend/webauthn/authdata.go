@@ -0,0 +1,82 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// Authenticator data flags.
+const (
+	flagUserPresent   = 0x01
+	flagUserVerified  = 0x04
+	flagAttestedData  = 0x40
+	flagExtensionData = 0x80
+)
+
+// authDataMinLen represents the length of the authenticator data
+// without attested credential data and extensions.
+const authDataMinLen = 37
+
+var ErrInvalidAuthData = errors.New("webauthn: authenticator data is invalid")
+
+// authenticatorData represents parsed authenticator data.
+type authenticatorData struct {
+	rpIDHash  [32]byte
+	flags     byte
+	signCount uint32
+
+	// Attested credential data, set only during registration
+	aaguid       [16]byte
+	credentialID []byte
+	publicKey    []byte
+}
+
+// parseAuthenticatorData parses the authenticator data.
+// Extensions are skipped, but the data must not contain anything else.
+func parseAuthenticatorData(data []byte) (*authenticatorData, error) {
+	if len(data) < authDataMinLen {
+		return nil, ErrInvalidAuthData
+	}
+
+	var ad authenticatorData
+	copy(ad.rpIDHash[:], data[:32])
+	ad.flags = data[32]
+	ad.signCount = binary.BigEndian.Uint32(data[33:37])
+	rest := data[authDataMinLen:]
+
+	if ad.flags&flagAttestedData != 0 {
+		if len(rest) < 18 {
+			return nil, ErrInvalidAuthData
+		}
+		copy(ad.aaguid[:], rest[:16])
+		idLen := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+
+		if idLen == 0 || idLen > credentialIDMaxLen || len(rest) < idLen {
+			return nil, ErrInvalidAuthData
+		}
+		ad.credentialID = append([]byte(nil), rest[:idLen]...)
+		rest = rest[idLen:]
+
+		_, n, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, ErrInvalidAuthData
+		}
+		ad.publicKey = append([]byte(nil), rest[:n]...)
+		rest = rest[n:]
+	}
+
+	if ad.flags&flagExtensionData != 0 {
+		_, n, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, ErrInvalidAuthData
+		}
+		rest = rest[n:]
+	}
+
+	if len(rest) != 0 {
+		return nil, ErrInvalidAuthData
+	}
+
+	return &ad, nil
+}
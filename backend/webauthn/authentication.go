@@ -0,0 +1,104 @@
+package webauthn
+
+import (
+	"crypto/sha256"
+	"errors"
+)
+
+// assertionTypeGet represents the client data type of an authentication.
+const assertionTypeGet = "webauthn.get"
+
+var (
+	ErrInvalidAssertion = errors.New("webauthn: assertion is invalid")
+
+	// ErrSignCountRegressed is returned when the sign counter
+	// did not increase, which indicates a cloned authenticator.
+	ErrSignCountRegressed = errors.New("webauthn: sign counter did not increase")
+)
+
+// RequestOptions represents options of navigator.credentials.get().
+type RequestOptions struct {
+	Challenge        string                 `json:"challenge"`
+	Timeout          int64                  `json:"timeout"`
+	RPID             string                 `json:"rpId"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+// RequestOptions returns options of an authentication ceremony.
+// Without allowed credential IDs the authenticator
+// offers any discoverable passkey of the relying party.
+func (rp *RelyingParty) RequestOptions(
+	challenge string, allowCredentialIDs [][]byte,
+) RequestOptions {
+	return RequestOptions{
+		Challenge:        challenge,
+		Timeout:          rp.config.Timeout.Milliseconds(),
+		RPID:             rp.config.RPID,
+		AllowCredentials: descriptors(allowCredentialIDs),
+		UserVerification: rp.userVerification(),
+	}
+}
+
+// AssertionResponse represents a PublicKeyCredential
+// returned by navigator.credentials.get().
+type AssertionResponse struct {
+	ID       string `json:"id"`
+	RawID    Bytes  `json:"rawId"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    Bytes `json:"clientDataJSON"`
+		AuthenticatorData Bytes `json:"authenticatorData"`
+		Signature         Bytes `json:"signature"`
+		UserHandle        Bytes `json:"userHandle"`
+	} `json:"response"`
+}
+
+// VerifyAuthentication verifies the response of an authentication ceremony
+// started with the challenge, against the stored credential's public key
+// and sign count. It returns the new sign count to be stored.
+// Authenticators that don't implement the counter always report zero,
+// otherwise the counter must increase.
+func (rp *RelyingParty) VerifyAuthentication(
+	challenge string, res *AssertionResponse,
+	publicKey []byte, signCount uint32,
+) (uint32, error) {
+	if res.Type != "public-key" {
+		return 0, ErrInvalidAssertion
+	}
+
+	err := rp.verifyClientData(
+		res.Response.ClientDataJSON, assertionTypeGet, challenge,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	ad, err := parseAuthenticatorData(res.Response.AuthenticatorData)
+	if err != nil {
+		return 0, err
+	}
+	if err := rp.verifyAuthenticatorData(ad); err != nil {
+		return 0, err
+	}
+
+	key, err := parseCOSEKey(publicKey)
+	if err != nil {
+		return 0, err
+	}
+
+	clientDataHash := sha256.Sum256(res.Response.ClientDataJSON)
+	signed := append(
+		append([]byte(nil), res.Response.AuthenticatorData...),
+		clientDataHash[:]...,
+	)
+	if err := key.verify(signed, res.Response.Signature); err != nil {
+		return 0, err
+	}
+
+	if (ad.signCount != 0 || signCount != 0) && ad.signCount <= signCount {
+		return 0, ErrSignCountRegressed
+	}
+
+	return ad.signCount, nil
+}
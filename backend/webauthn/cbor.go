@@ -0,0 +1,190 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// cborMaxDepth limits nesting of decoded CBOR items.
+const cborMaxDepth = 16
+
+var errInvalidCBOR = errors.New("webauthn: CBOR is invalid")
+
+// decodeCBOR decodes the first CBOR item of the data,
+// and returns it with the number of bytes it occupies.
+// Only the subset used by WebAuthn is supported: definite length
+// integers, byte and text strings, arrays, maps, booleans and null.
+// Integers are returned as int64, maps as map[any]any
+// with int64 or string keys, and arrays as []any.
+func decodeCBOR(data []byte) (any, int, error) {
+	d := cborDecoder{data: data}
+	v, err := d.decode(0)
+	if err != nil {
+		return nil, 0, err
+	}
+	return v, d.pos, nil
+}
+
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *cborDecoder) decode(depth int) (any, error) {
+	if depth > cborMaxDepth {
+		return nil, errInvalidCBOR
+	}
+
+	major, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case 0:
+		if arg > math.MaxInt64 {
+			return nil, errInvalidCBOR
+		}
+		return int64(arg), nil
+
+	case 1:
+		if arg > math.MaxInt64 {
+			return nil, errInvalidCBOR
+		}
+		return -1 - int64(arg), nil
+
+	case 2:
+		b, err := d.bytes(arg)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), b...), nil
+
+	case 3:
+		b, err := d.bytes(arg)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+
+	case 4:
+		// Every item takes at least one byte
+		if arg > uint64(len(d.data)-d.pos) {
+			return nil, errInvalidCBOR
+		}
+		items := make([]any, 0, arg)
+		for range arg {
+			item, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+
+	case 5:
+		if arg > uint64(len(d.data)-d.pos)/2 {
+			return nil, errInvalidCBOR
+		}
+		m := make(map[any]any, arg)
+		for range arg {
+			key, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, errInvalidCBOR
+			}
+			if _, ok := m[key]; ok {
+				return nil, errInvalidCBOR
+			}
+
+			value, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = value
+		}
+		return m, nil
+
+	case 6:
+		// Tags carry no meaning for WebAuthn, the tagged item is returned
+		return d.decode(depth + 1)
+
+	default:
+		switch arg {
+		case 20:
+			return false, nil
+		case 21:
+			return true, nil
+		case 22, 23:
+			return nil, nil
+		default:
+			return nil, errInvalidCBOR
+		}
+	}
+}
+
+// head decodes the initial byte and the argument of an item.
+func (d *cborDecoder) head() (byte, uint64, error) {
+	if d.pos >= len(d.data) {
+		return 0, 0, errInvalidCBOR
+	}
+	initial := d.data[d.pos]
+	d.pos++
+
+	major := initial >> 5
+	info := initial & 0x1f
+
+	// Simple values and floats keep their additional information as is
+	if major == 7 {
+		if info > 23 {
+			return 0, 0, errInvalidCBOR
+		}
+		return major, uint64(info), nil
+	}
+
+	switch {
+	case info < 24:
+		return major, uint64(info), nil
+	case info == 24:
+		b, err := d.bytes(1)
+		if err != nil {
+			return 0, 0, err
+		}
+		return major, uint64(b[0]), nil
+	case info == 25:
+		b, err := d.bytes(2)
+		if err != nil {
+			return 0, 0, err
+		}
+		return major, uint64(binary.BigEndian.Uint16(b)), nil
+	case info == 26:
+		b, err := d.bytes(4)
+		if err != nil {
+			return 0, 0, err
+		}
+		return major, uint64(binary.BigEndian.Uint32(b)), nil
+	case info == 27:
+		b, err := d.bytes(8)
+		if err != nil {
+			return 0, 0, err
+		}
+		return major, binary.BigEndian.Uint64(b), nil
+	default:
+		// Indefinite lengths are not allowed in WebAuthn
+		return 0, 0, errInvalidCBOR
+	}
+}
+
+func (d *cborDecoder) bytes(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, errInvalidCBOR
+	}
+	b := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return b, nil
+}
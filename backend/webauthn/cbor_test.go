@@ -0,0 +1,63 @@
+package webauthn
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeCBOR(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      []byte
+		expected  any
+		shouldErr bool
+	}{
+		{"Valid: small int", []byte{0x17}, int64(23), false},
+		{"Valid: uint16", []byte{0x19, 0x01, 0x00}, int64(256), false},
+		{"Valid: negative int", []byte{0x38, 0x18}, int64(-25), false},
+		{"Valid: bytes", []byte{0x42, 0x01, 0x02}, []byte{0x01, 0x02}, false},
+		{"Valid: text", []byte{0x63, 'f', 'm', 't'}, "fmt", false},
+		{"Valid: array", []byte{0x82, 0x01, 0xf5}, []any{int64(1), true}, false},
+		{
+			"Valid: map",
+			[]byte{0xa2, 0x01, 0x02, 0x20, 0x60},
+			map[any]any{int64(1): int64(2), int64(-1): ""},
+			false,
+		},
+		{"Valid: tag", []byte{0xc1, 0x01}, int64(1), false},
+		{"Invalid: empty", []byte{}, nil, true},
+		{"Invalid: truncated bytes", []byte{0x45, 0x01}, nil, true},
+		{"Invalid: indefinite length", []byte{0x5f, 0xff}, nil, true},
+		{"Invalid: float", []byte{0xf9, 0x3c, 0x00}, nil, true},
+		{"Invalid: duplicate key", []byte{0xa2, 0x01, 0x01, 0x01, 0x02}, nil, true},
+		{"Invalid: array key", []byte{0xa1, 0x80, 0x01}, nil, true},
+		{"Invalid: huge array", []byte{0x9a, 0xff, 0xff, 0xff, 0xff}, nil, true},
+		{
+			"Invalid: too deep",
+			[]byte{
+				0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81,
+				0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x01,
+			},
+			nil,
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, _, err := decodeCBOR(test.data)
+			if (err != nil) != test.shouldErr {
+				t.Errorf(
+					"decodeCBOR(%x), error=%v, shouldErr=%v",
+					test.data, err, test.shouldErr,
+				)
+			}
+			if err == nil && !reflect.DeepEqual(got, test.expected) {
+				t.Errorf(
+					"decodeCBOR(%x), got=%#v, expected=%#v",
+					test.data, got, test.expected,
+				)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+// COSE algorithm identifiers of supported credential keys.
+const (
+	AlgES256 int64 = -7
+	AlgEdDSA int64 = -8
+	AlgRS256 int64 = -257
+)
+
+// COSE key types and parameters, see RFC 9053.
+const (
+	coseKeyTypeOKP = 1
+	coseKeyTypeEC2 = 2
+	coseKeyTypeRSA = 3
+
+	coseCurveP256    = 1
+	coseCurveEd25519 = 6
+
+	coseKeyType  = 1
+	coseKeyAlg   = 3
+	coseKeyCurve = -1
+	coseKeyX     = -2
+	coseKeyY     = -3
+	coseKeyN     = -1
+	coseKeyE     = -2
+)
+
+var (
+	ErrUnsupportedKey   = errors.New("webauthn: credential key is unsupported")
+	ErrInvalidSignature = errors.New("webauthn: signature is invalid")
+)
+
+// coseKey represents a parsed COSE_Key.
+type coseKey struct {
+	alg       int64
+	publicKey crypto.PublicKey
+}
+
+// parseCOSEKey parses a CBOR encoded COSE_Key.
+// ES256 (P-256), EdDSA (Ed25519) and RS256 keys are supported.
+func parseCOSEKey(data []byte) (*coseKey, error) {
+	v, n, err := decodeCBOR(data)
+	if err != nil {
+		return nil, err
+	}
+	if n != len(data) {
+		return nil, errInvalidCBOR
+	}
+
+	m, ok := v.(map[any]any)
+	if !ok {
+		return nil, ErrUnsupportedKey
+	}
+
+	kty, _ := m[int64(coseKeyType)].(int64)
+	alg, _ := m[int64(coseKeyAlg)].(int64)
+
+	switch {
+	case kty == coseKeyTypeEC2 && alg == AlgES256:
+		crv, _ := m[int64(coseKeyCurve)].(int64)
+		x, _ := m[int64(coseKeyX)].([]byte)
+		y, _ := m[int64(coseKeyY)].([]byte)
+		if crv != coseCurveP256 || len(x) != 32 || len(y) != 32 {
+			return nil, ErrUnsupportedKey
+		}
+		key := &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}
+		if _, err := key.ECDH(); err != nil {
+			return nil, ErrUnsupportedKey
+		}
+		return &coseKey{alg: alg, publicKey: key}, nil
+
+	case kty == coseKeyTypeOKP && alg == AlgEdDSA:
+		crv, _ := m[int64(coseKeyCurve)].(int64)
+		x, _ := m[int64(coseKeyX)].([]byte)
+		if crv != coseCurveEd25519 || len(x) != ed25519.PublicKeySize {
+			return nil, ErrUnsupportedKey
+		}
+		return &coseKey{alg: alg, publicKey: ed25519.PublicKey(x)}, nil
+
+	case kty == coseKeyTypeRSA && alg == AlgRS256:
+		n, _ := m[int64(coseKeyN)].([]byte)
+		e, _ := m[int64(coseKeyE)].([]byte)
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return nil, ErrUnsupportedKey
+		}
+		exponent := int(new(big.Int).SetBytes(e).Int64())
+		if exponent < 3 {
+			return nil, ErrUnsupportedKey
+		}
+		key := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: exponent}
+		return &coseKey{alg: alg, publicKey: key}, nil
+
+	default:
+		return nil, ErrUnsupportedKey
+	}
+}
+
+// verify verifies the signature of the message with the key.
+func (k *coseKey) verify(message, signature []byte) error {
+	return verifySignature(k.alg, k.publicKey, message, signature)
+}
+
+// verifySignature verifies the signature of the message
+// made by the algorithm with the public key.
+// ECDSA signatures are ASN.1 DER encoded as required by WebAuthn.
+func verifySignature(
+	alg int64, publicKey crypto.PublicKey, message, signature []byte,
+) error {
+	switch alg {
+	case AlgES256:
+		key, ok := publicKey.(*ecdsa.PublicKey)
+		if !ok {
+			return ErrInvalidSignature
+		}
+		digest := sha256.Sum256(message)
+		if !ecdsa.VerifyASN1(key, digest[:], signature) {
+			return ErrInvalidSignature
+		}
+		return nil
+
+	case AlgEdDSA:
+		key, ok := publicKey.(ed25519.PublicKey)
+		if !ok || !ed25519.Verify(key, message, signature) {
+			return ErrInvalidSignature
+		}
+		return nil
+
+	case AlgRS256:
+		key, ok := publicKey.(*rsa.PublicKey)
+		if !ok {
+			return ErrInvalidSignature
+		}
+		digest := sha256.Sum256(message)
+		if rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) != nil {
+			return ErrInvalidSignature
+		}
+		return nil
+
+	default:
+		return ErrUnsupportedKey
+	}
+}
@@ -0,0 +1,240 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+)
+
+// attestationTypeCreate represents the client data type of a registration.
+const attestationTypeCreate = "webauthn.create"
+
+// oidAAGUID represents the id-fido-gen-ce-aaguid certificate extension.
+var oidAAGUID = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 45724, 1, 1, 4}
+
+var (
+	ErrInvalidAttestation     = errors.New("webauthn: attestation is invalid")
+	ErrUnsupportedAttestation = errors.New("webauthn: attestation format is unsupported")
+)
+
+// UserEntity represents the user a passkey is registered for.
+// The ID is an opaque user handle, it must not contain personal information.
+type UserEntity struct {
+	ID          Bytes  `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// CredentialDescriptor represents a credential known to the relying party.
+type CredentialDescriptor struct {
+	Type string `json:"type"`
+	ID   Bytes  `json:"id"`
+}
+
+// CreationOptions represents options of navigator.credentials.create().
+type CreationOptions struct {
+	RP struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"rp"`
+	User             UserEntity `json:"user"`
+	Challenge        string     `json:"challenge"`
+	PubKeyCredParams []struct {
+		Type string `json:"type"`
+		Alg  int64  `json:"alg"`
+	} `json:"pubKeyCredParams"`
+	Timeout                int64                  `json:"timeout"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection struct {
+		ResidentKey      string `json:"residentKey"`
+		UserVerification string `json:"userVerification"`
+	} `json:"authenticatorSelection"`
+	Attestation string `json:"attestation"`
+}
+
+// CreationOptions returns options of a registration ceremony.
+// The excluded credential IDs prevent registering
+// the same authenticator twice.
+func (rp *RelyingParty) CreationOptions(
+	challenge string, user UserEntity, excludeCredentialIDs [][]byte,
+) CreationOptions {
+	var o CreationOptions
+	o.RP.ID = rp.config.RPID
+	o.RP.Name = rp.config.RPName
+	o.User = user
+	o.Challenge = challenge
+	for _, alg := range []int64{AlgES256, AlgEdDSA, AlgRS256} {
+		o.PubKeyCredParams = append(o.PubKeyCredParams, struct {
+			Type string `json:"type"`
+			Alg  int64  `json:"alg"`
+		}{"public-key", alg})
+	}
+	o.Timeout = rp.config.Timeout.Milliseconds()
+	o.ExcludeCredentials = descriptors(excludeCredentialIDs)
+	o.AuthenticatorSelection.ResidentKey = "required"
+	o.AuthenticatorSelection.UserVerification = rp.userVerification()
+	o.Attestation = "none"
+	return o
+}
+
+// AttestationResponse represents a PublicKeyCredential
+// returned by navigator.credentials.create().
+type AttestationResponse struct {
+	ID       string `json:"id"`
+	RawID    Bytes  `json:"rawId"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    Bytes `json:"clientDataJSON"`
+		AttestationObject Bytes `json:"attestationObject"`
+	} `json:"response"`
+}
+
+// Credential represents a verified newly registered credential.
+type Credential struct {
+	ID []byte
+
+	// PublicKey is the CBOR encoded COSE_Key of the credential.
+	PublicKey []byte
+
+	SignCount uint32
+	AAGUID    [16]byte
+}
+
+// VerifyRegistration verifies the response of a registration ceremony
+// started with the challenge, and returns the registered credential.
+// Attestation certificates of the "packed" format are checked to sign
+// the data, but are not validated against trusted roots, since passkeys
+// are accepted from any authenticator.
+func (rp *RelyingParty) VerifyRegistration(
+	challenge string, res *AttestationResponse,
+) (*Credential, error) {
+	if res.Type != "public-key" {
+		return nil, ErrInvalidAttestation
+	}
+
+	err := rp.verifyClientData(
+		res.Response.ClientDataJSON, attestationTypeCreate, challenge,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	v, n, err := decodeCBOR(res.Response.AttestationObject)
+	if err != nil || n != len(res.Response.AttestationObject) {
+		return nil, ErrInvalidAttestation
+	}
+	object, ok := v.(map[any]any)
+	if !ok {
+		return nil, ErrInvalidAttestation
+	}
+	format, _ := object["fmt"].(string)
+	statement, _ := object["attStmt"].(map[any]any)
+	rawAuthData, _ := object["authData"].([]byte)
+	if statement == nil || rawAuthData == nil {
+		return nil, ErrInvalidAttestation
+	}
+
+	ad, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err := rp.verifyAuthenticatorData(ad); err != nil {
+		return nil, err
+	}
+	if ad.flags&flagAttestedData == 0 {
+		return nil, ErrInvalidAuthData
+	}
+	if !bytes.Equal(ad.credentialID, res.RawID) {
+		return nil, ErrInvalidAttestation
+	}
+
+	key, err := parseCOSEKey(ad.publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	clientDataHash := sha256.Sum256(res.Response.ClientDataJSON)
+	signed := append(append([]byte(nil), rawAuthData...), clientDataHash[:]...)
+
+	switch format {
+	case "none":
+		if len(statement) != 0 {
+			return nil, ErrInvalidAttestation
+		}
+	case "packed":
+		if err := verifyPacked(statement, key, ad.aaguid, signed); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrUnsupportedAttestation
+	}
+
+	return &Credential{
+		ID:        ad.credentialID,
+		PublicKey: ad.publicKey,
+		SignCount: ad.signCount,
+		AAGUID:    ad.aaguid,
+	}, nil
+}
+
+// verifyPacked verifies a "packed" attestation statement.
+// A statement without a certificate is a self attestation,
+// signed by the credential key itself.
+func verifyPacked(
+	statement map[any]any, key *coseKey, aaguid [16]byte, signed []byte,
+) error {
+	alg, ok := statement["alg"].(int64)
+	if !ok {
+		return ErrInvalidAttestation
+	}
+	sig, ok := statement["sig"].([]byte)
+	if !ok {
+		return ErrInvalidAttestation
+	}
+
+	x5c, hasCertificates := statement["x5c"].([]any)
+	if !hasCertificates {
+		if alg != key.alg {
+			return ErrInvalidAttestation
+		}
+		return key.verify(signed, sig)
+	}
+
+	if len(x5c) == 0 {
+		return ErrInvalidAttestation
+	}
+	rawCert, ok := x5c[0].([]byte)
+	if !ok {
+		return ErrInvalidAttestation
+	}
+	cert, err := x509.ParseCertificate(rawCert)
+	if err != nil {
+		return ErrInvalidAttestation
+	}
+	if cert.Version != 3 || cert.IsCA {
+		return ErrInvalidAttestation
+	}
+
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(oidAAGUID) {
+			continue
+		}
+		var certAAGUID []byte
+		_, err := asn1.Unmarshal(ext.Value, &certAAGUID)
+		if err != nil || !bytes.Equal(certAAGUID, aaguid[:]) {
+			return ErrInvalidAttestation
+		}
+	}
+
+	return verifySignature(alg, cert.PublicKey, signed, sig)
+}
+
+func descriptors(credentialIDs [][]byte) []CredentialDescriptor {
+	out := make([]CredentialDescriptor, len(credentialIDs))
+	for i, id := range credentialIDs {
+		out[i] = CredentialDescriptor{Type: "public-key", ID: id}
+	}
+	return out
+}
@@ -0,0 +1,198 @@
+// Package webauthn provides a WebAuthn relying party
+// for registering and authenticating with passkeys.
+// It verifies "none" and "packed" attestations,
+// and ES256, EdDSA and RS256 credential keys.
+package webauthn
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultTimeout represents how long a ceremony can take.
+	DefaultTimeout = 5 * time.Minute
+
+	challengeBytes = 32
+
+	// credentialIDMaxLen represents the maximum length
+	// of a credential ID allowed by the specification.
+	credentialIDMaxLen = 1023
+)
+
+var (
+	ErrInvalidConfig     = errors.New("webauthn: config is invalid")
+	ErrInvalidClientData = errors.New("webauthn: client data is invalid")
+	ErrInvalidChallenge  = errors.New("webauthn: challenge is invalid")
+	ErrInvalidOrigin     = errors.New("webauthn: origin is invalid")
+	ErrInvalidRPID       = errors.New("webauthn: relying party ID is invalid")
+	ErrUserNotPresent    = errors.New("webauthn: user presence is missing")
+	ErrUserNotVerified   = errors.New("webauthn: user verification is missing")
+)
+
+// Config represents a configuration of the relying party.
+type Config struct {
+	// RPID is the relying party ID, the domain of the application,
+	// e.g. "example.com". Passkeys are bound to it.
+	RPID string
+
+	// RPName is a human-readable name of the application.
+	RPName string
+
+	// Origins are the allowed origins of the ceremonies,
+	// e.g. "https://example.com".
+	Origins []string
+
+	// RequireUserVerification makes the authenticator
+	// verify the user, e.g. with a PIN or biometrics.
+	RequireUserVerification bool
+
+	// Timeout represents how long a ceremony can take.
+	// [DefaultTimeout] is used if zero.
+	Timeout time.Duration
+}
+
+// RelyingParty represents a configured WebAuthn relying party.
+type RelyingParty struct {
+	config   Config
+	rpIDHash [32]byte
+}
+
+// NewRelyingParty returns a new *RelyingParty.
+// It fails if the RPID, RPName or Origins fields are not set.
+func NewRelyingParty(config Config) (*RelyingParty, error) {
+	if config.RPID == "" || config.RPName == "" || len(config.Origins) == 0 {
+		return nil, ErrInvalidConfig
+	}
+	if config.Timeout == 0 {
+		config.Timeout = DefaultTimeout
+	}
+
+	return &RelyingParty{
+		config:   config,
+		rpIDHash: sha256.Sum256([]byte(config.RPID)),
+	}, nil
+}
+
+// Timeout returns how long a ceremony can take.
+func (rp *RelyingParty) Timeout() time.Duration {
+	return rp.config.Timeout
+}
+
+// Bytes represents binary data encoded as base64 (URL, no padding) in JSON.
+// Padded and standard encodings are accepted when decoding.
+type Bytes []byte
+
+func (b Bytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.RawURLEncoding.EncodeToString(b))
+}
+
+func (b *Bytes) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	s = strings.TrimRight(s, "=")
+	s = strings.NewReplacer("+", "-", "/", "_").Replace(s)
+
+	decoded, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	*b = decoded
+	return nil
+}
+
+// NewChallenge returns a new random challenge.
+// It fails if the system's source of randomness is unavailable.
+func NewChallenge() (string, error) {
+	b := make([]byte, challengeBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("webauthn: failed to generate challenge: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashChallenge returns the challenge hashed using sha256.
+// Challenges should be stored hashed until the ceremony is finished.
+func HashChallenge(challenge string) [32]byte {
+	return sha256.Sum256([]byte(challenge))
+}
+
+// clientData represents the collected client data.
+type clientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+// Challenge returns the challenge from the client data JSON of a response.
+// It's used to find the stored ceremony state before the response is verified,
+// a returned challenge is not trusted until then.
+func Challenge(clientDataJSON []byte) (string, error) {
+	var cd clientData
+	if err := json.Unmarshal(clientDataJSON, &cd); err != nil {
+		return "", ErrInvalidClientData
+	}
+	if cd.Challenge == "" {
+		return "", ErrInvalidChallenge
+	}
+	return cd.Challenge, nil
+}
+
+// verifyClientData verifies the client data JSON against the ceremony type,
+// the challenge issued for it and the allowed origins.
+func (rp *RelyingParty) verifyClientData(
+	clientDataJSON []byte, ceremonyType, challenge string,
+) error {
+	var cd clientData
+	if err := json.Unmarshal(clientDataJSON, &cd); err != nil {
+		return ErrInvalidClientData
+	}
+
+	if cd.Type != ceremonyType {
+		return ErrInvalidClientData
+	}
+
+	if subtle.ConstantTimeCompare([]byte(cd.Challenge), []byte(challenge)) != 1 {
+		return ErrInvalidChallenge
+	}
+
+	if cd.CrossOrigin || !slices.Contains(rp.config.Origins, cd.Origin) {
+		return ErrInvalidOrigin
+	}
+
+	return nil
+}
+
+// verifyAuthenticatorData verifies the relying party ID hash
+// and the user presence and verification flags.
+func (rp *RelyingParty) verifyAuthenticatorData(ad *authenticatorData) error {
+	if subtle.ConstantTimeCompare(ad.rpIDHash[:], rp.rpIDHash[:]) != 1 {
+		return ErrInvalidRPID
+	}
+	if ad.flags&flagUserPresent == 0 {
+		return ErrUserNotPresent
+	}
+	if rp.config.RequireUserVerification && ad.flags&flagUserVerified == 0 {
+		return ErrUserNotVerified
+	}
+	return nil
+}
+
+func (rp *RelyingParty) userVerification() string {
+	if rp.config.RequireUserVerification {
+		return "required"
+	}
+	return "preferred"
+}
@@ -0,0 +1,268 @@
+package webauthn_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/zvxte/kera/webauthn"
+	"github.com/zvxte/kera/webauthn/webauthntest"
+)
+
+const (
+	testRPID   = "example.com"
+	testOrigin = "https://example.com"
+)
+
+var testUser = webauthn.UserEntity{
+	ID:          []byte("0192d4b6-7e0c-7cc4-9b4a-7a3c2a1f0e11"),
+	Name:        "username",
+	DisplayName: "Display Name",
+}
+
+func newTestRelyingParty(t *testing.T, requireUV bool) *webauthn.RelyingParty {
+	t.Helper()
+
+	rp, err := webauthn.NewRelyingParty(webauthn.Config{
+		RPID:                    testRPID,
+		RPName:                  "kera",
+		Origins:                 []string{testOrigin},
+		RequireUserVerification: requireUV,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rp
+}
+
+// register runs a registration ceremony with the authenticator.
+func register(
+	t *testing.T, rp *webauthn.RelyingParty, a *webauthntest.Authenticator,
+) *webauthn.Credential {
+	t.Helper()
+
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := a.Create(testOrigin, rp.CreationOptions(challenge, testUser, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	credential, err := rp.VerifyRegistration(challenge, res)
+	if err != nil {
+		t.Fatalf("VerifyRegistration(), error=%v", err)
+	}
+	return credential
+}
+
+func TestCeremonies(t *testing.T) {
+	tests := []struct {
+		name          string
+		authenticator *webauthntest.Authenticator
+	}{
+		{"Valid: ES256, none", &webauthntest.Authenticator{}},
+		{"Valid: ES256, packed", &webauthntest.Authenticator{Format: "packed"}},
+		{"Valid: EdDSA, packed", &webauthntest.Authenticator{Alg: webauthn.AlgEdDSA, Format: "packed"}},
+		{"Valid: RS256, none", &webauthntest.Authenticator{Alg: webauthn.AlgRS256}},
+	}
+
+	rp := newTestRelyingParty(t, true)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			credential := register(t, rp, test.authenticator)
+
+			signCount := credential.SignCount
+			for range 2 {
+				challenge, _ := webauthn.NewChallenge()
+				res, err := test.authenticator.Get(
+					testOrigin, rp.RequestOptions(challenge, [][]byte{credential.ID}),
+				)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if got, _ := webauthn.Challenge(res.Response.ClientDataJSON); got != challenge {
+					t.Errorf("Challenge(), got=%q, expected=%q", got, challenge)
+				}
+
+				signCount, err = rp.VerifyAuthentication(
+					challenge, res, credential.PublicKey, signCount,
+				)
+				if err != nil {
+					t.Fatalf("VerifyAuthentication(), error=%v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestVerifyRegistration(t *testing.T) {
+	rp := newTestRelyingParty(t, true)
+	otherRP, _ := webauthn.NewRelyingParty(webauthn.Config{
+		RPID: "evil.example", RPName: "evil", Origins: []string{testOrigin},
+	})
+
+	tests := []struct {
+		name      string
+		rp        *webauthn.RelyingParty
+		origin    string
+		challenge string
+		a         *webauthntest.Authenticator
+		expected  error
+	}{
+		{"Valid", rp, testOrigin, "", &webauthntest.Authenticator{}, nil},
+		{"Invalid: origin", rp, "https://evil.example", "", &webauthntest.Authenticator{}, webauthn.ErrInvalidOrigin},
+		{"Invalid: challenge", rp, testOrigin, "other", &webauthntest.Authenticator{}, webauthn.ErrInvalidChallenge},
+		{"Invalid: relying party ID", otherRP, testOrigin, "", &webauthntest.Authenticator{}, webauthn.ErrInvalidRPID},
+		{
+			"Invalid: user not verified",
+			rp, testOrigin, "",
+			&webauthntest.Authenticator{SkipUserVerification: true},
+			webauthn.ErrUserNotVerified,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			challenge, _ := webauthn.NewChallenge()
+			res, err := test.a.Create(
+				test.origin, test.rp.CreationOptions(challenge, testUser, nil),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expectedChallenge := challenge
+			if test.challenge != "" {
+				expectedChallenge = test.challenge
+			}
+
+			_, err = rp.VerifyRegistration(expectedChallenge, res)
+			if !errors.Is(err, test.expected) {
+				t.Errorf(
+					"VerifyRegistration(), error=%v, expected=%v",
+					err, test.expected,
+				)
+			}
+		})
+	}
+}
+
+func TestVerifyRegistrationExcluded(t *testing.T) {
+	rp := newTestRelyingParty(t, false)
+	a := &webauthntest.Authenticator{}
+	credential := register(t, rp, a)
+
+	challenge, _ := webauthn.NewChallenge()
+	_, err := a.Create(
+		testOrigin, rp.CreationOptions(challenge, testUser, [][]byte{credential.ID}),
+	)
+	if !errors.Is(err, webauthntest.ErrExcluded) {
+		t.Errorf("Create(), error=%v, expected=%v", err, webauthntest.ErrExcluded)
+	}
+}
+
+func TestVerifyAuthentication(t *testing.T) {
+	rp := newTestRelyingParty(t, false)
+
+	tests := []struct {
+		name     string
+		tamper   func(res *webauthn.AssertionResponse, a *webauthntest.Authenticator)
+		expected error
+	}{
+		{
+			"Valid",
+			func(*webauthn.AssertionResponse, *webauthntest.Authenticator) {},
+			nil,
+		},
+		{
+			"Invalid: signature",
+			func(res *webauthn.AssertionResponse, _ *webauthntest.Authenticator) {
+				res.Response.Signature[len(res.Response.Signature)-1] ^= 0xff
+			},
+			webauthn.ErrInvalidSignature,
+		},
+		{
+			"Invalid: authenticator data",
+			func(res *webauthn.AssertionResponse, _ *webauthntest.Authenticator) {
+				res.Response.AuthenticatorData = res.Response.AuthenticatorData[:20]
+			},
+			webauthn.ErrInvalidAuthData,
+		},
+		{
+			"Invalid: registration client data",
+			func(res *webauthn.AssertionResponse, _ *webauthntest.Authenticator) {
+				res.Response.ClientDataJSON = []byte(`{"type":"webauthn.create"}`)
+			},
+			webauthn.ErrInvalidClientData,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := &webauthntest.Authenticator{}
+			credential := register(t, rp, a)
+
+			challenge, _ := webauthn.NewChallenge()
+			res, err := a.Get(testOrigin, rp.RequestOptions(challenge, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			test.tamper(res, a)
+
+			_, err = rp.VerifyAuthentication(
+				challenge, res, credential.PublicKey, credential.SignCount,
+			)
+			if !errors.Is(err, test.expected) {
+				t.Errorf(
+					"VerifyAuthentication(), error=%v, expected=%v",
+					err, test.expected,
+				)
+			}
+		})
+	}
+}
+
+func TestVerifyAuthenticationSignCount(t *testing.T) {
+	rp := newTestRelyingParty(t, false)
+
+	tests := []struct {
+		name            string
+		a               *webauthntest.Authenticator
+		signCount       uint32
+		storedSignCount uint32
+		expected        error
+	}{
+		{"Valid: increased", &webauthntest.Authenticator{}, 4, 4, nil},
+		{"Valid: no counter", &webauthntest.Authenticator{NoSignCount: true}, 0, 0, nil},
+		{"Invalid: regressed", &webauthntest.Authenticator{}, 1, 10, webauthn.ErrSignCountRegressed},
+		{"Invalid: repeated", &webauthntest.Authenticator{}, 4, 5, webauthn.ErrSignCountRegressed},
+		{"Invalid: counter dropped", &webauthntest.Authenticator{NoSignCount: true}, 0, 5, webauthn.ErrSignCountRegressed},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			credential := register(t, rp, test.a)
+			test.a.SetSignCount(test.signCount)
+
+			challenge, _ := webauthn.NewChallenge()
+			res, err := test.a.Get(testOrigin, rp.RequestOptions(challenge, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = rp.VerifyAuthentication(
+				challenge, res, credential.PublicKey, test.storedSignCount,
+			)
+			if !errors.Is(err, test.expected) {
+				t.Errorf(
+					"VerifyAuthentication(), stored=%d, error=%v, expected=%v",
+					test.storedSignCount, err, test.expected,
+				)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package webauthntest
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// cborMap represents a CBOR map encoded in the order of its pairs.
+type cborMap []cborPair
+
+type cborPair struct {
+	key   any
+	value any
+}
+
+// encodeCBOR encodes the value as CBOR.
+// Supported types: int, int64, []byte, string, bool, []any and cborMap.
+func encodeCBOR(v any) []byte {
+	switch v := v.(type) {
+	case int:
+		return encodeCBOR(int64(v))
+	case int64:
+		if v < 0 {
+			return cborHead(1, uint64(-1-v))
+		}
+		return cborHead(0, uint64(v))
+	case []byte:
+		return append(cborHead(2, uint64(len(v))), v...)
+	case string:
+		return append(cborHead(3, uint64(len(v))), v...)
+	case bool:
+		if v {
+			return []byte{0xf5}
+		}
+		return []byte{0xf4}
+	case []any:
+		b := cborHead(4, uint64(len(v)))
+		for _, item := range v {
+			b = append(b, encodeCBOR(item)...)
+		}
+		return b
+	case cborMap:
+		b := cborHead(5, uint64(len(v)))
+		for _, pair := range v {
+			b = append(b, encodeCBOR(pair.key)...)
+			b = append(b, encodeCBOR(pair.value)...)
+		}
+		return b
+	default:
+		panic(fmt.Sprintf("webauthntest: unsupported CBOR type %T", v))
+	}
+}
+
+func cborHead(major byte, arg uint64) []byte {
+	major <<= 5
+	switch {
+	case arg < 24:
+		return []byte{major | byte(arg)}
+	case arg <= 0xff:
+		return []byte{major | 24, byte(arg)}
+	case arg <= 0xffff:
+		return binary.BigEndian.AppendUint16([]byte{major | 25}, uint16(arg))
+	case arg <= 0xffffffff:
+		return binary.BigEndian.AppendUint32([]byte{major | 26}, uint32(arg))
+	default:
+		return binary.BigEndian.AppendUint64([]byte{major | 27}, arg)
+	}
+}
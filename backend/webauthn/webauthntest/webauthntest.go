@@ -0,0 +1,271 @@
+// Package webauthntest provides a software authenticator for tests.
+// It creates passkeys and signs assertions the way a platform
+// authenticator does, so no hardware is needed.
+package webauthntest
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math/big"
+
+	"github.com/zvxte/kera/webauthn"
+)
+
+const (
+	flagUserPresent  = 0x01
+	flagUserVerified = 0x04
+	flagAttestedData = 0x40
+)
+
+var (
+	ErrExcluded          = errors.New("webauthntest: credential is excluded")
+	ErrNoCredential      = errors.New("webauthntest: no credential is available")
+	ErrUnsupportedParams = errors.New("webauthntest: no supported algorithm is requested")
+)
+
+// Authenticator represents a software authenticator.
+type Authenticator struct {
+	// AAGUID identifies the authenticator model.
+	AAGUID [16]byte
+
+	// Format is the attestation format, "none" (default) or "packed".
+	// Packed attestations are self attestations.
+	Format string
+
+	// Alg is the preferred credential algorithm, [webauthn.AlgES256] if zero.
+	Alg int64
+
+	// SkipUserVerification clears the user verified flag.
+	SkipUserVerification bool
+
+	// NoSignCount makes the authenticator always report a zero
+	// sign count, like authenticators without a counter do.
+	NoSignCount bool
+
+	credentials []*credential
+}
+
+type credential struct {
+	id         []byte
+	rpID       string
+	userHandle []byte
+	alg        int64
+	key        crypto.Signer
+	signCount  uint32
+}
+
+// Create performs navigator.credentials.create() from the origin.
+func (a *Authenticator) Create(
+	origin string, options webauthn.CreationOptions,
+) (*webauthn.AttestationResponse, error) {
+	for _, excluded := range options.ExcludeCredentials {
+		if a.find(options.RP.ID, excluded.ID) != nil {
+			return nil, ErrExcluded
+		}
+	}
+
+	alg := a.Alg
+	if alg == 0 {
+		alg = webauthn.AlgES256
+	}
+	supported := false
+	for _, param := range options.PubKeyCredParams {
+		if param.Alg == alg {
+			supported = true
+		}
+	}
+	if !supported {
+		return nil, ErrUnsupportedParams
+	}
+
+	key, publicKey, err := generateKey(alg)
+	if err != nil {
+		return nil, err
+	}
+
+	id := make([]byte, 16)
+	rand.Read(id)
+
+	c := &credential{
+		id:         id,
+		rpID:       options.RP.ID,
+		userHandle: options.User.ID,
+		alg:        alg,
+		key:        key,
+	}
+	a.credentials = append(a.credentials, c)
+
+	clientDataJSON := clientData("webauthn.create", options.Challenge, origin)
+
+	authData := a.authData(c, flagAttestedData)
+	authData = append(authData, a.AAGUID[:]...)
+	authData = binary.BigEndian.AppendUint16(authData, uint16(len(id)))
+	authData = append(authData, id...)
+	authData = append(authData, publicKey...)
+
+	statement := cborMap{}
+	format := a.Format
+	if format == "" {
+		format = "none"
+	}
+	if format == "packed" {
+		sig, err := c.sign(authData, clientDataJSON)
+		if err != nil {
+			return nil, err
+		}
+		statement = cborMap{{"alg", alg}, {"sig", sig}}
+	}
+
+	var res webauthn.AttestationResponse
+	res.ID = base64.RawURLEncoding.EncodeToString(id)
+	res.RawID = id
+	res.Type = "public-key"
+	res.Response.ClientDataJSON = clientDataJSON
+	res.Response.AttestationObject = encodeCBOR(cborMap{
+		{"fmt", format},
+		{"attStmt", statement},
+		{"authData", authData},
+	})
+	return &res, nil
+}
+
+// Get performs navigator.credentials.get() from the origin.
+// The first credential of the relying party is used
+// if the options don't list allowed credentials.
+func (a *Authenticator) Get(
+	origin string, options webauthn.RequestOptions,
+) (*webauthn.AssertionResponse, error) {
+	var c *credential
+	if len(options.AllowCredentials) == 0 {
+		c = a.find(options.RPID, nil)
+	}
+	for _, allowed := range options.AllowCredentials {
+		if c = a.find(options.RPID, allowed.ID); c != nil {
+			break
+		}
+	}
+	if c == nil {
+		return nil, ErrNoCredential
+	}
+
+	if !a.NoSignCount {
+		c.signCount++
+	}
+
+	clientDataJSON := clientData("webauthn.get", options.Challenge, origin)
+	authData := a.authData(c, 0)
+	sig, err := c.sign(authData, clientDataJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	var res webauthn.AssertionResponse
+	res.ID = base64.RawURLEncoding.EncodeToString(c.id)
+	res.RawID = c.id
+	res.Type = "public-key"
+	res.Response.ClientDataJSON = clientDataJSON
+	res.Response.AuthenticatorData = authData
+	res.Response.Signature = sig
+	res.Response.UserHandle = c.userHandle
+	return &res, nil
+}
+
+// SetSignCount sets the sign counter of all credentials,
+// e.g. to simulate a cloned authenticator.
+// The next assertion reports the counter incremented by one.
+func (a *Authenticator) SetSignCount(signCount uint32) {
+	for _, c := range a.credentials {
+		c.signCount = signCount
+	}
+}
+
+func (a *Authenticator) find(rpID string, id []byte) *credential {
+	for _, c := range a.credentials {
+		if c.rpID == rpID && (id == nil || bytes.Equal(c.id, id)) {
+			return c
+		}
+	}
+	return nil
+}
+
+func (a *Authenticator) authData(c *credential, flags byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(c.rpID))
+
+	flags |= flagUserPresent
+	if !a.SkipUserVerification {
+		flags |= flagUserVerified
+	}
+
+	data := append(rpIDHash[:], flags)
+	return binary.BigEndian.AppendUint32(data, c.signCount)
+}
+
+func (c *credential) sign(authData, clientDataJSON []byte) ([]byte, error) {
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	message := append(append([]byte(nil), authData...), clientDataHash[:]...)
+
+	if c.alg == webauthn.AlgEdDSA {
+		return c.key.Sign(rand.Reader, message, crypto.Hash(0))
+	}
+	digest := sha256.Sum256(message)
+	return c.key.Sign(rand.Reader, digest[:], crypto.SHA256)
+}
+
+// generateKey returns a new private key and its CBOR encoded COSE_Key.
+func generateKey(alg int64) (crypto.Signer, []byte, error) {
+	switch alg {
+	case webauthn.AlgES256:
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		return key, encodeCBOR(cborMap{
+			{1, 2}, {3, alg}, {-1, 1},
+			{-2, key.X.FillBytes(make([]byte, 32))},
+			{-3, key.Y.FillBytes(make([]byte, 32))},
+		}), nil
+
+	case webauthn.AlgEdDSA:
+		publicKey, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		return key, encodeCBOR(cborMap{
+			{1, 1}, {3, alg}, {-1, 6}, {-2, []byte(publicKey)},
+		}), nil
+
+	case webauthn.AlgRS256:
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, nil, err
+		}
+		return key, encodeCBOR(cborMap{
+			{1, 3}, {3, alg},
+			{-1, key.N.Bytes()},
+			{-2, big.NewInt(int64(key.E)).Bytes()},
+		}), nil
+
+	default:
+		return nil, nil, ErrUnsupportedParams
+	}
+}
+
+func clientData(typ, challenge, origin string) []byte {
+	b, _ := json.Marshal(map[string]any{
+		"type":        typ,
+		"challenge":   challenge,
+		"origin":      origin,
+		"crossOrigin": false,
+	})
+	return b
+}